@@ -0,0 +1,98 @@
+package plog
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// accessLogTimeFormat is the time format of Apache access logs.
+const accessLogTimeFormat = "02/Jan/2006:15:04:05 -0700"
+
+func (l *Logger) accessLogFormatter(b *bytes.Buffer, combined bool, keyvals ...interface{}) {
+	fields := make(map[string]interface{}, len(keyvals)/2)
+	for i := 0; i+1 < len(keyvals); i += 2 {
+		fields[fmt.Sprint(keyvals[i])] = keyvals[i+1]
+	}
+	field := func(key string) string {
+		v, ok := fields[key]
+		if !ok || v == nil {
+			return "-"
+		}
+		if s := fmt.Sprint(v); s != "" {
+			return s
+		}
+		return "-"
+	}
+
+	t, ok := fields[TimestampKey].(time.Time)
+	if !ok {
+		t = time.Now()
+	}
+
+	b.WriteString(field(KeyRemoteAddr))
+	b.WriteString(" - ")
+	b.WriteString(field(KeyUserID))
+	b.WriteString(" [")
+	b.WriteString(t.Format(accessLogTimeFormat))
+	b.WriteString("] ")
+
+	request := "-"
+	if _, ok := fields[KeyMethod]; ok {
+		request = field(KeyMethod) + " " + field(KeyPath)
+		if _, ok := fields[KeyProto]; ok {
+			request += " " + field(KeyProto)
+		}
+	}
+	b.WriteString(accessLogQuote(request))
+	b.WriteByte(' ')
+	b.WriteString(field(KeyStatus))
+	b.WriteByte(' ')
+	if n := field(KeyBytes); n != "0" {
+		b.WriteString(n)
+	} else {
+		b.WriteByte('-')
+	}
+
+	if combined {
+		b.WriteByte(' ')
+		b.WriteString(accessLogQuote(field(KeyReferer)))
+		b.WriteByte(' ')
+		b.WriteString(accessLogQuote(field(KeyUserAgent)))
+	}
+
+	if d, ok := fields[KeyDurationMS]; ok {
+		var ms float64
+		switch v := d.(type) {
+		case float64:
+			ms = v
+		case time.Duration:
+			ms = DurationMS(v)
+		default:
+			ms, _ = strconv.ParseFloat(fmt.Sprint(v), 64)
+		}
+		b.WriteByte(' ')
+		b.WriteString(strconv.FormatInt(int64(ms*1000), 10))
+	}
+	b.WriteByte('\n')
+}
+
+// accessLogQuote quotes s the way Apache does, escaping quotes, backslashes,
+// and control characters.
+func accessLogQuote(s string) string {
+	b := make([]byte, 0, len(s)+2)
+	b = append(b, '"')
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c == '"' || c == '\\':
+			b = append(b, '\\', c)
+		case c < 0x20 || c == 0x7f:
+			b = append(b, fmt.Sprintf("\\x%02x", c)...)
+		default:
+			b = append(b, c)
+		}
+	}
+	return string(append(b, '"'))
+}
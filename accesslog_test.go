@@ -0,0 +1,56 @@
+package plog
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAccessLog(t *testing.T) {
+	ts := time.Date(2000, 10, 10, 13, 55, 36, 0, time.FixedZone("", -7*60*60))
+	cases := []struct {
+		name      string
+		formatter Formatter
+		kvs       []interface{}
+		expected  string
+	}{
+		{
+			name:      "common",
+			formatter: CommonLogFormatter,
+			kvs: []interface{}{
+				KeyRemoteAddr, "127.0.0.1", KeyUserID, "frank", KeyMethod, "GET",
+				KeyPath, "/apache_pb.gif", KeyProto, "HTTP/1.0", KeyStatus, 200, KeyBytes, 2326,
+			},
+			expected: `127.0.0.1 - frank [10/Oct/2000:13:55:36 -0700] "GET /apache_pb.gif HTTP/1.0" 200 2326` + "\n",
+		},
+		{
+			name:      "combined",
+			formatter: CombinedLogFormatter,
+			kvs: []interface{}{
+				KeyRemoteAddr, "127.0.0.1", KeyMethod, "GET", KeyPath, "/",
+				KeyStatus, 304, KeyBytes, 0, KeyUserAgent, `Mozilla "5.0"`,
+				KeyDurationMS, 1.5,
+			},
+			expected: `127.0.0.1 - - [10/Oct/2000:13:55:36 -0700] "GET /" 304 - "-" "Mozilla \"5.0\"" 1500` + "\n",
+		},
+		{
+			name:      "empty",
+			formatter: CommonLogFormatter,
+			expected:  `- - - [10/Oct/2000:13:55:36 -0700] "-" - -` + "\n",
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			l := NewWithOptions(&buf, Options{
+				Formatter:       c.formatter,
+				ReportTimestamp: true,
+				TimeFunction:    func(time.Time) time.Time { return ts },
+			})
+			l.Info("request", c.kvs...)
+			require.Equal(t, c.expected, buf.String())
+		})
+	}
+}
@@ -0,0 +1,73 @@
+package plog
+
+import (
+	"bytes"
+	"sync"
+
+	"github.com/charmbracelet/x/ansi"
+)
+
+// alignWindow is the number of records after which the column widths start
+// being forgotten, so a single wide value doesn't pad the output forever.
+const alignWindow = 100
+
+// alignState holds the column widths of the recent records. It is shared by
+// the sub-loggers so their records line up too.
+type alignState struct {
+	mu        sync.Mutex
+	cur, prev []int
+	n         int
+}
+
+func newAlignState(enabled bool) *alignState {
+	if !enabled {
+		return nil
+	}
+	return &alignState{}
+}
+
+// width records a column of width w and returns the width to pad it to.
+func (a *alignState) width(col, w int) int {
+	for len(a.cur) <= col {
+		a.cur = append(a.cur, 0)
+	}
+	a.cur[col] = max(a.cur[col], w)
+	if col < len(a.prev) {
+		return max(a.cur[col], a.prev[col])
+	}
+	return a.cur[col]
+}
+
+// done ends a record.
+func (a *alignState) done() {
+	a.n++
+	if a.n == alignWindow {
+		a.prev, a.cur, a.n = a.cur, nil, 0
+	}
+}
+
+// SetAlignColumns sets whether the TextFormatter pads the message and the
+// key=value pairs to the widest of their column in the recent records, so
+// values line up vertically in terminals.
+func (l *Logger) SetAlignColumns(enabled bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.align = newAlignState(enabled)
+}
+
+// alignColumn pads the column written to the buffer from start, unless it is
+// the last one of the record or spans multiple lines.
+func (l *Logger) alignColumn(b *bytes.Buffer, col, start int, last bool) {
+	if last {
+		return
+	}
+	p := b.Bytes()[start:]
+	if bytes.IndexByte(p, '\n') != -1 {
+		return
+	}
+	p = bytes.TrimPrefix(p, []byte{' '})
+	w := ansi.StringWidth(string(p))
+	if pad := l.align.width(col, w) - w; pad > 0 {
+		b.Write(bytes.Repeat([]byte{' '}, pad))
+	}
+}
@@ -0,0 +1,244 @@
+package plog
+
+import (
+	"context"
+	"io"
+	"sync"
+)
+
+// DefaultAsyncQueueSize is the default number of records the async queue
+// holds.
+const DefaultAsyncQueueSize = 1024
+
+// DropPolicy is what the async mode does when its queue is full.
+type DropPolicy uint8
+
+const (
+	// DropNewest drops the record being logged. This is the default.
+	DropNewest DropPolicy = iota
+	// DropOldest drops the oldest queued record to make room.
+	DropOldest
+	// Block waits for room in the queue.
+	Block
+)
+
+// AsyncOptions configures the async mode of a logger.
+type AsyncOptions struct {
+	// QueueSize is the number of records the queue holds. The default is
+	// DefaultAsyncQueueSize.
+	QueueSize int
+	// DropPolicy is what to do when the queue is full. The default is
+	// DropNewest.
+	DropPolicy DropPolicy
+}
+
+// asyncRecord is a formatted record waiting to be written.
+type asyncRecord struct {
+	w, machine io.Writer
+	p, mp      []byte
+	// mu serializes the writes with the other records of the logger and
+	// its sub-loggers.
+	mu *sync.Mutex
+	// csv writes the CSV header before the first record, if set.
+	csv *csvState
+}
+
+func (r asyncRecord) write() {
+	if r.mu != nil {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+	}
+	if r.w != nil {
+		p := r.p
+		if r.csv != nil {
+			p = r.csv.withHeader(p)
+		}
+		r.w.Write(p) //nolint: errcheck
+	}
+	if r.machine != nil {
+		r.machine.Write(r.mp) //nolint: errcheck
+	}
+}
+
+// asyncQueue writes records in the background. Error and fatal records go
+// through a priority lane: they are written before any queued record and
+// never dropped.
+type asyncQueue struct {
+	opts AsyncOptions
+
+	mu       sync.Mutex
+	cond     *sync.Cond
+	priority []asyncRecord
+	queue    []asyncRecord
+	busy     bool
+	closed   bool
+	dropped  uint64
+	done     chan struct{}
+}
+
+func newAsyncQueue(o AsyncOptions) *asyncQueue {
+	if o.QueueSize <= 0 {
+		o.QueueSize = DefaultAsyncQueueSize
+	}
+	q := &asyncQueue{opts: o, done: make(chan struct{})}
+	q.cond = sync.NewCond(&q.mu)
+	go q.run()
+	return q
+}
+
+// push queues the record, in the priority lane if priority is set. Records
+// pushed after the queue is closed are written right away, serialized with
+// the other writes.
+func (q *asyncQueue) push(r asyncRecord, priority bool) {
+	q.mu.Lock()
+	if q.closed {
+		q.mu.Unlock()
+		r.write()
+		return
+	}
+	defer q.mu.Unlock()
+
+	if priority {
+		q.priority = append(q.priority, r)
+		q.cond.Broadcast()
+		return
+	}
+	for len(q.queue) >= q.opts.QueueSize {
+		switch q.opts.DropPolicy {
+		case DropOldest:
+			q.queue[0] = asyncRecord{}
+			q.queue = q.queue[1:]
+			q.dropped++
+		case Block:
+			q.cond.Wait()
+			if q.closed {
+				q.mu.Unlock()
+				r.write()
+				q.mu.Lock()
+				return
+			}
+		default:
+			q.dropped++
+			return
+		}
+	}
+	q.queue = append(q.queue, r)
+	q.cond.Broadcast()
+}
+
+func (q *asyncQueue) run() {
+	defer close(q.done)
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for {
+		for len(q.priority) == 0 && len(q.queue) == 0 && !q.closed {
+			q.cond.Wait()
+		}
+
+		var r asyncRecord
+		switch {
+		case len(q.priority) > 0:
+			r = q.priority[0]
+			q.priority[0] = asyncRecord{}
+			q.priority = q.priority[1:]
+		case len(q.queue) > 0:
+			r = q.queue[0]
+			q.queue[0] = asyncRecord{}
+			q.queue = q.queue[1:]
+		default:
+			// Closed and drained.
+			return
+		}
+
+		q.busy = true
+		q.cond.Broadcast()
+		q.mu.Unlock()
+		r.write()
+		q.mu.Lock()
+		q.busy = false
+		q.cond.Broadcast()
+	}
+}
+
+// flush waits for the queued records to be written.
+func (q *asyncQueue) flush() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for (len(q.priority) > 0 || len(q.queue) > 0 || q.busy) && !q.closed {
+		q.cond.Wait()
+	}
+}
+
+// close writes the queued records and stops the queue, or gives up when ctx
+// is done.
+func (q *asyncQueue) close(ctx context.Context) error {
+	q.mu.Lock()
+	q.closed = true
+	q.cond.Broadcast()
+	q.mu.Unlock()
+
+	select {
+	case <-q.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// SetAsync enables the async mode: records are formatted by the logging call
+// and written to the outputs in the background, so slow outputs don't slow
+// down the application. When the queue is full, records are dropped or the
+// call blocks according to the drop policy.
+//
+// Error and fatal records take a priority lane: they skip ahead of queued
+// records and are never dropped, so they survive overload. As a result,
+// records aren't always written in order.
+//
+// A nil o disables the async mode, after the queued records are written. Call
+// Shutdown or Flush before exiting so queued records aren't lost; Fatal does
+// it for you.
+//
+// The async mode is shared with the sub-loggers, including those created
+// before calling SetAsync.
+func (l *Logger) SetAsync(o *AsyncOptions) {
+	var q *asyncQueue
+	if o != nil {
+		q = newAsyncQueue(*o)
+	}
+	if old := l.async.Swap(q); old != nil {
+		old.close(context.Background()) //nolint: errcheck
+	}
+}
+
+// AsyncDropped returns the number of records dropped by the async mode drop
+// policy.
+func (l *Logger) AsyncDropped() uint64 {
+	q := l.async.Load()
+	if q == nil {
+		return 0
+	}
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.dropped
+}
+
+// Shutdown writes the records queued by the async mode, disables it, and
+// flushes the processors and the output. It returns ctx.Err() if ctx is done
+// before the queued records are written. With SetShutdownSummary, the
+// shutdown summary record is logged first.
+func (l *Logger) Shutdown(ctx context.Context) error {
+	l.mu.RLock()
+	summary := l.shutdownSummary
+	l.mu.RUnlock()
+	if summary {
+		l.LogShutdownSummary()
+	}
+
+	if q := l.async.Swap(nil); q != nil {
+		if err := q.close(ctx); err != nil {
+			return err
+		}
+	}
+	l.Flush()
+	return nil
+}
@@ -0,0 +1,161 @@
+package plog
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// gateWriter blocks writes until the gate is opened.
+type gateWriter struct {
+	mu      sync.Mutex
+	lines   []string
+	started chan struct{}
+	gate    chan struct{}
+}
+
+func newGateWriter() *gateWriter {
+	return &gateWriter{started: make(chan struct{}, 1), gate: make(chan struct{})}
+}
+
+func (w *gateWriter) Write(p []byte) (int, error) {
+	select {
+	case w.started <- struct{}{}:
+	default:
+	}
+	<-w.gate
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.lines = append(w.lines, strings.TrimSpace(string(p)))
+	return len(p), nil
+}
+
+func (w *gateWriter) Lines() []string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.lines
+}
+
+func TestAsyncPriorityLane(t *testing.T) {
+	cases := []struct {
+		name     string
+		policy   DropPolicy
+		expected []string
+	}{
+		{
+			name:     "drop newest",
+			policy:   DropNewest,
+			expected: []string{"a", "e", "b", "c"},
+		},
+		{
+			name:     "drop oldest",
+			policy:   DropOldest,
+			expected: []string{"a", "e", "c", "d"},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			w := newGateWriter()
+			l := NewWithOptions(w, Options{
+				Formatter: LogfmtFormatter,
+				Async:     &AsyncOptions{QueueSize: 2, DropPolicy: c.policy},
+			})
+			l.Print("a")
+			<-w.started // a is being written
+			l.Print("b")
+			l.Print("c")
+			l.Print("d")
+			l.Error("e")
+			close(w.gate)
+
+			require.NoError(t, l.Shutdown(context.Background()))
+			lines := w.Lines()
+			for i, line := range lines {
+				lines[i] = strings.TrimPrefix(strings.TrimPrefix(line, "level=error "), "msg=")
+			}
+			require.Equal(t, c.expected, lines)
+			require.Zero(t, l.AsyncDropped())
+		})
+	}
+}
+
+func TestAsyncBlock(t *testing.T) {
+	w := newGateWriter()
+	l := NewWithOptions(w, Options{Formatter: LogfmtFormatter})
+	l.SetAsync(&AsyncOptions{QueueSize: 1, DropPolicy: Block})
+	sub := l.With("sub", true)
+	l.Print("a")
+	<-w.started
+	l.Print("b")
+
+	done := make(chan struct{})
+	go func() {
+		sub.Print("c")
+		close(done)
+	}()
+	select {
+	case <-done:
+		t.Fatal("logging didn't block on a full queue")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	close(w.gate)
+	<-done
+	l.Flush()
+	require.Equal(t, []string{"msg=a", "msg=b", "msg=c sub=true"}, w.Lines())
+	require.Zero(t, l.AsyncDropped())
+
+	// Disabling the async mode drains the queue, then writes synchronously.
+	l.SetAsync(nil)
+	l.Print("d")
+	require.Equal(t, "msg=d", w.Lines()[3])
+}
+
+func TestAsyncDropped(t *testing.T) {
+	w := newGateWriter()
+	l := NewWithOptions(w, Options{Formatter: LogfmtFormatter, Async: &AsyncOptions{QueueSize: 1}})
+	l.Print("a")
+	<-w.started
+	l.Print("b")
+	l.Print("c")
+	require.Equal(t, uint64(1), l.AsyncDropped())
+	close(w.gate)
+	require.NoError(t, l.Shutdown(context.Background()))
+}
+
+func TestAsyncSubLoggers(t *testing.T) {
+	// The buffer isn't safe for concurrent use, so the writes must be
+	// serialized across the queue and the loggers.
+	var buf bytes.Buffer
+	l := NewWithOptions(&buf, Options{Formatter: LogfmtFormatter})
+	sl := l.With("sub", true)
+	l.SetAsync(&AsyncOptions{DropPolicy: Block})
+	require.NotNil(t, sl.async.Load())
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 50; j++ {
+				l.Print("parent")
+				sl.Print("child")
+			}
+		}()
+	}
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		time.Sleep(time.Millisecond)
+		require.NoError(t, l.Shutdown(context.Background()))
+	}()
+	wg.Wait()
+
+	require.Equal(t, 4*50*2, strings.Count(buf.String(), "\n"))
+	require.Equal(t, 4*50, strings.Count(buf.String(), "msg=child sub=true\n"))
+}
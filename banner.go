@@ -0,0 +1,76 @@
+package plog
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Banner writes a startup summary of the application: its name followed by
+// the given keyvals, e.g. the version, a configuration summary, and listen
+// addresses, one per line and aligned:
+//
+//	log.Banner(logger, "bakery", "version", version, "listen", addr)
+//
+// With the TextFormatter, the banner is rendered with the logger styles: the
+// name with the Prefix style, and the keyvals with the Key and Value styles.
+// Other formatters log it as a regular InfoLevel record, with the name as the
+// message. A nil logger uses the default logger.
+func Banner(logger *Logger, appName string, keyvals ...interface{}) {
+	if logger == nil {
+		logger = Default()
+	}
+	if len(keyvals)%2 != 0 {
+		keyvals = append(keyvals, ErrMissingValue)
+	}
+
+	logger.mu.RLock()
+	formatter := logger.formatter
+	logger.mu.RUnlock()
+	if formatter != TextFormatter {
+		logger.Log(InfoLevel, appName, keyvals...)
+		return
+	}
+
+	logger.mu.Lock()
+	defer logger.mu.Unlock()
+	if logger.w == io.Discard {
+		return
+	}
+
+	st := logger.styles
+	width := 0
+	for i := 0; i < len(keyvals); i += 2 {
+		if n := len(fmt.Sprint(keyvals[i])); n > width {
+			width = n
+		}
+	}
+
+	var b strings.Builder
+	b.WriteString(st.Prefix.Renderer(logger.re).Render(appName))
+	b.WriteByte('\n')
+	for i := 0; i < len(keyvals); i += 2 {
+		key := fmt.Sprint(keyvals[i])
+		val := fmt.Sprintf("%+v", keyvals[i+1])
+		valueStyle := st.Value
+		if vs, ok := st.Values[key]; ok {
+			valueStyle = vs
+		}
+		keyStyle := st.Key
+		if ks, ok := st.Keys[key]; ok {
+			keyStyle = ks
+		}
+		b.WriteString("  ")
+		b.WriteString(keyStyle.Renderer(logger.re).Render(key))
+		b.WriteString(strings.Repeat(" ", width-len(key)+1))
+		b.WriteString(valueStyle.Renderer(logger.re).Render(val))
+		b.WriteByte('\n')
+	}
+
+	r := asyncRecord{w: logger.w, p: []byte(b.String()), mu: logger.wmu}
+	if q := logger.async.Load(); q != nil {
+		q.push(r, false)
+		return
+	}
+	r.write()
+}
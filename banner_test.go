@@ -0,0 +1,23 @@
+package plog
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBanner(t *testing.T) {
+	var buf bytes.Buffer
+	l := New(&buf)
+	Banner(l, "bakery", "version", "1.2.3", "listen", ":8080", "oven")
+	require.Equal(t, "bakery\n"+
+		"  version 1.2.3\n"+
+		"  listen  :8080\n"+
+		"  oven    missing value\n", buf.String())
+
+	buf.Reset()
+	l.SetFormatter(JSONFormatter)
+	Banner(l, "bakery", "version", "1.2.3")
+	require.Equal(t, `{"level":"info","msg":"bakery","version":"1.2.3"}`+"\n", buf.String())
+}
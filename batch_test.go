@@ -0,0 +1,86 @@
+package plog
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type countingWriter struct {
+	bytes.Buffer
+	writes int
+}
+
+func (w *countingWriter) Write(p []byte) (int, error) {
+	w.writes++
+	return w.Buffer.Write(p)
+}
+
+func TestLogBatch(t *testing.T) {
+	var w, machine countingWriter
+	l := NewWithOptions(&w, Options{
+		Formatter:       LogfmtFormatter,
+		ReportTimestamp: true,
+		TimeFormat:      time.RFC3339,
+		TimeFunction: func(time.Time) time.Time {
+			return time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+		},
+		Prefix:        "import",
+		Fields:        []interface{}{"job", 7},
+		MachineOutput: &machine,
+	})
+	l.AddProcessor(ProcessorFunc(func(e *Entry) bool { return e.Message != "drop" }))
+
+	l.LogBatch([]Entry{
+		{Level: InfoLevel, Message: "first", Keyvals: []interface{}{"n", 1}},
+		{Level: DebugLevel, Message: "too low"},
+		{Level: WarnLevel, Message: "drop"},
+		{
+			Time:    time.Date(2020, 5, 6, 7, 8, 9, 0, time.UTC),
+			Level:   ErrorLevel,
+			Prefix:  "replay",
+			Message: "second",
+		},
+	})
+
+	require.Equal(t, 1, w.writes)
+	require.Equal(t, "time=2024-01-01T12:00:00Z level=info prefix=import msg=first job=7 n=1\n"+
+		"time=2020-05-06T07:08:09Z level=error prefix=replay msg=second job=7\n", w.String())
+	require.Equal(t, 1, machine.writes)
+	require.Equal(t, 2, bytes.Count(machine.Bytes(), []byte("\n")))
+	require.Equal(t, uint64(1), l.Snapshot().Counts[ErrorLevel])
+}
+
+type messageCountingWriter struct {
+	countingWriter
+}
+
+func (w *messageCountingWriter) messageOriented() bool { return true }
+
+func TestLogBatchMessageWriter(t *testing.T) {
+	var w messageCountingWriter
+	l := NewWithOptions(&w, Options{Formatter: LogfmtFormatter})
+	l.LogBatch([]Entry{
+		{Level: InfoLevel, Message: "first"},
+		{Level: ErrorLevel, Message: "second"},
+	})
+	require.Equal(t, 2, w.writes)
+	require.Equal(t, "level=info msg=first\nlevel=error msg=second\n", w.String())
+}
+
+func TestLogBatchPriority(t *testing.T) {
+	w := newGateWriter()
+	l := NewWithOptions(w, Options{Formatter: LogfmtFormatter, Async: &AsyncOptions{QueueSize: 4}})
+	l.Print("a")
+	<-w.started // a is being written
+	l.Print("b")
+	// Entries without a level don't hide the error level of the batch.
+	l.LogBatch([]Entry{{Level: ErrorLevel, Message: "c"}, {Level: noLevel, Message: "d"}})
+	close(w.gate)
+
+	require.NoError(t, l.Shutdown(context.Background()))
+	require.Equal(t, []string{"msg=a", "level=error msg=c\nmsg=d", "msg=b"}, w.Lines())
+}
@@ -0,0 +1,54 @@
+package plog
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"strconv"
+)
+
+// BinaryFormat is how []byte values are rendered.
+type BinaryFormat uint8
+
+const (
+	// BinaryDefault renders []byte values as the TextFormatter and
+	// LogfmtFormatter render any slice, and the JSONFormatter as base64.
+	// This is the default.
+	BinaryDefault BinaryFormat = iota
+	// BinaryHex renders []byte values as hexadecimal.
+	BinaryHex
+	// BinaryBase64 renders []byte values as standard base64.
+	BinaryBase64
+)
+
+// SetBinaryFormat sets how []byte values are rendered. Values longer than
+// maxLen bytes are cut to maxLen and suffixed with their total length, like
+// "00ff…(4096 bytes)". A maxLen of zero or less disables the cut.
+func (l *Logger) SetBinaryFormat(f BinaryFormat, maxLen int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.binaryFormat = f
+	l.binaryMaxLen = maxLen
+}
+
+// formatBinary returns v formatted with the binary format. It returns false
+// if there is no binary format or v isn't a []byte.
+func (l *Logger) formatBinary(v interface{}) (string, bool) {
+	b, ok := v.([]byte)
+	if !ok || l.binaryFormat == BinaryDefault {
+		return "", false
+	}
+	n := len(b)
+	if l.binaryMaxLen > 0 && n > l.binaryMaxLen {
+		b = b[:l.binaryMaxLen]
+	}
+	var s string
+	if l.binaryFormat == BinaryHex {
+		s = hex.EncodeToString(b)
+	} else {
+		s = base64.StdEncoding.EncodeToString(b)
+	}
+	if len(b) < n {
+		s += "…(" + strconv.Itoa(n) + " bytes)"
+	}
+	return s, true
+}
@@ -0,0 +1,38 @@
+package plog
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBinaryFormat(t *testing.T) {
+	data := []byte{0xde, 0xad, 0xbe, 0xef}
+	var buf bytes.Buffer
+	l := NewWithOptions(&buf, Options{BinaryFormat: BinaryHex})
+	l.Print("", "data", data)
+	require.Equal(t, "data=deadbeef\n", buf.String())
+
+	buf.Reset()
+	l.SetBinaryFormat(BinaryHex, 2)
+	l.Print("", "data", data)
+	require.Equal(t, `data="dead…(4 bytes)"`+"\n", buf.String())
+
+	buf.Reset()
+	l.SetBinaryFormat(BinaryBase64, 0)
+	l.SetFormatter(LogfmtFormatter)
+	l.Print("", "data", data)
+	require.Equal(t, `data="3q2+7w=="`+"\n", buf.String())
+
+	buf.Reset()
+	l.SetBinaryFormat(BinaryHex, 0)
+	l.SetFormatter(JSONFormatter)
+	l.Print("", "data", data)
+	require.Equal(t, `{"data":"deadbeef"}`+"\n", buf.String())
+
+	buf.Reset()
+	l.SetBinaryFormat(BinaryDefault, 0)
+	l.Print("", "data", data)
+	require.Equal(t, `{"data":"3q2+7w=="}`+"\n", buf.String())
+}
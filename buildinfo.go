@@ -0,0 +1,47 @@
+package plog
+
+import (
+	"runtime/debug"
+	"strconv"
+)
+
+// Build info keys, as added by WithBuildInfo.
+const (
+	// KeyVersion is the key for the main module version.
+	KeyVersion = "version"
+	// KeyRevision is the key for the VCS revision of the build.
+	KeyRevision = "vcs_revision"
+	// KeyModified is the key for whether the build had uncommitted changes.
+	KeyModified = "vcs_modified"
+)
+
+// WithBuildInfo returns a new logger adding the main module version, the VCS
+// revision and whether the working tree was modified to every record, so they
+// identify the build that logged them. The values missing from the build info
+// are omitted.
+func (l *Logger) WithBuildInfo() *Logger {
+	bi, ok := debug.ReadBuildInfo()
+	if !ok {
+		return l.With()
+	}
+	return l.With(buildInfoKeyvals(bi)...)
+}
+
+// buildInfoKeyvals returns the build info keyvals of bi.
+func buildInfoKeyvals(bi *debug.BuildInfo) []interface{} {
+	var keyvals []interface{}
+	if v := bi.Main.Version; v != "" {
+		keyvals = append(keyvals, KeyVersion, v)
+	}
+	for _, s := range bi.Settings {
+		switch s.Key {
+		case "vcs.revision":
+			keyvals = append(keyvals, KeyRevision, s.Value)
+		case "vcs.modified":
+			if modified, err := strconv.ParseBool(s.Value); err == nil {
+				keyvals = append(keyvals, KeyModified, modified)
+			}
+		}
+	}
+	return keyvals
+}
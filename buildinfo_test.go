@@ -0,0 +1,27 @@
+package plog
+
+import (
+	"bytes"
+	"runtime/debug"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildInfo(t *testing.T) {
+	bi := &debug.BuildInfo{
+		Main: debug.Module{Version: "v1.2.3"},
+		Settings: []debug.BuildSetting{
+			{Key: "vcs", Value: "git"},
+			{Key: "vcs.revision", Value: "abc123"},
+			{Key: "vcs.modified", Value: "true"},
+		},
+	}
+	require.Equal(t, []interface{}{KeyVersion, "v1.2.3", KeyRevision, "abc123", KeyModified, true}, buildInfoKeyvals(bi))
+	require.Empty(t, buildInfoKeyvals(&debug.BuildInfo{}))
+
+	var buf bytes.Buffer
+	l := NewWithOptions(&buf, Options{Formatter: LogfmtFormatter}).WithBuildInfo()
+	l.Print("hi")
+	require.Contains(t, buf.String(), "msg=hi")
+}
@@ -0,0 +1,183 @@
+package plog
+
+import (
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultBurnRateObjective is the default success ratio objective of a
+	// BurnRateProcessor.
+	DefaultBurnRateObjective = 0.999
+	// DefaultBurnRateMinRecords is the default minimum number of records in
+	// a window before its burn rate is considered.
+	DefaultBurnRateMinRecords = 10
+
+	burnRateBuckets = 60
+)
+
+// Burn rate record keys, added to the record crossing a threshold when the
+// BurnRateProcessor has no callback.
+const (
+	KeyBurnRate       = "burn_rate"
+	KeyBurnRateWindow = "burn_rate_window"
+)
+
+// DefaultBurnRateWindows are the default windows of a BurnRateProcessor: a
+// fast burn over an hour, and a slower burn over six hours.
+var DefaultBurnRateWindows = []BurnRateWindow{
+	{Window: time.Hour, Threshold: 14.4},
+	{Window: 6 * time.Hour, Threshold: 6},
+}
+
+// BurnRateWindow is a rolling window with its alerting threshold.
+type BurnRateWindow struct {
+	// Window is the duration over which the error rate is computed.
+	Window time.Duration
+	// Threshold is the burn rate crossing which alerts. A burn rate of 1
+	// consumes the error budget exactly over the objective period.
+	Threshold float64
+}
+
+// BurnRateAlert describes a burn rate threshold crossing.
+type BurnRateAlert struct {
+	BurnRateWindow
+	// BurnRate is the burn rate over the window.
+	BurnRate float64
+	// Errors is the number of records at ErrorLevel or above in the window.
+	Errors uint64
+	// Total is the number of records in the window.
+	Total uint64
+	// Entry is the record that crossed the threshold.
+	Entry *Entry
+}
+
+// BurnRateOptions are the options for a BurnRateProcessor.
+type BurnRateOptions struct {
+	// Objective is the target ratio of records below ErrorLevel, e.g. 0.999.
+	// The error budget is 1 - Objective. The default is
+	// DefaultBurnRateObjective.
+	Objective float64
+	// Windows are the rolling windows and their thresholds. The default is
+	// DefaultBurnRateWindows.
+	Windows []BurnRateWindow
+	// MinRecords is the minimum number of records in a window before its
+	// burn rate is considered, so a single error at startup doesn't alert.
+	// The default is DefaultBurnRateMinRecords.
+	MinRecords uint64
+	// OnAlert is called when the burn rate of a window crosses its threshold.
+	// It's called again only after the burn rate went back below it. The
+	// default adds the KeyBurnRate and KeyBurnRateWindow fields to the record
+	// crossing the threshold.
+	OnAlert func(BurnRateAlert)
+}
+
+// BurnRateProcessor is a Processor computing the error rate of the records
+// over rolling windows, and alerting when the rate at which the error budget
+// of a service level objective is consumed crosses a threshold. It gives
+// small services basic alerting straight from their logs.
+type BurnRateProcessor struct {
+	opts BurnRateOptions
+
+	mu      sync.Mutex
+	now     func() time.Time
+	windows []*burnRateWindow
+}
+
+// burnRateWindow counts the records and errors of a window in a ring of
+// buckets, with epochs holding the bucket number each count belongs to.
+type burnRateWindow struct {
+	BurnRateWindow
+	bucket time.Duration
+	firing bool
+
+	totals [burnRateBuckets]uint64
+	errors [burnRateBuckets]uint64
+	epochs [burnRateBuckets]int64
+}
+
+// NewBurnRateProcessor returns a new BurnRateProcessor.
+func NewBurnRateProcessor(o BurnRateOptions) *BurnRateProcessor {
+	if o.Objective <= 0 || o.Objective >= 1 {
+		o.Objective = DefaultBurnRateObjective
+	}
+	if len(o.Windows) == 0 {
+		o.Windows = DefaultBurnRateWindows
+	}
+	if o.MinRecords == 0 {
+		o.MinRecords = DefaultBurnRateMinRecords
+	}
+	p := &BurnRateProcessor{opts: o, now: time.Now}
+	for _, w := range o.Windows {
+		bucket := w.Window / burnRateBuckets
+		if bucket <= 0 {
+			bucket = 1
+		}
+		p.windows = append(p.windows, &burnRateWindow{BurnRateWindow: w, bucket: bucket})
+	}
+	return p
+}
+
+// Process counts the record and alerts on threshold crossings.
+func (p *BurnRateProcessor) Process(e *Entry) bool {
+	if e.Level == noLevel {
+		return true
+	}
+
+	var alerts []BurnRateAlert
+	p.mu.Lock()
+	now := p.now()
+	for _, w := range p.windows {
+		errs, total := w.add(now, e.Level >= ErrorLevel)
+		if total < p.opts.MinRecords {
+			continue
+		}
+		rate := float64(errs) / float64(total) / (1 - p.opts.Objective)
+		switch {
+		case rate >= w.Threshold && !w.firing:
+			w.firing = true
+			alerts = append(alerts, BurnRateAlert{
+				BurnRateWindow: w.BurnRateWindow,
+				BurnRate:       rate,
+				Errors:         errs,
+				Total:          total,
+				Entry:          e,
+			})
+		case rate < w.Threshold:
+			w.firing = false
+		}
+	}
+	p.mu.Unlock()
+
+	for _, a := range alerts {
+		if p.opts.OnAlert != nil {
+			p.opts.OnAlert(a)
+			continue
+		}
+		e.Keyvals = append(e.Keyvals, KeyBurnRate, a.BurnRate, KeyBurnRateWindow, a.Window)
+	}
+	return true
+}
+
+// add counts a record and returns the errors and records in the window.
+func (w *burnRateWindow) add(now time.Time, isError bool) (errs, total uint64) {
+	epoch := now.UnixNano() / int64(w.bucket)
+	i := epoch % burnRateBuckets
+	if w.epochs[i] != epoch {
+		w.epochs[i] = epoch
+		w.totals[i] = 0
+		w.errors[i] = 0
+	}
+	w.totals[i]++
+	if isError {
+		w.errors[i]++
+	}
+
+	for j := range w.totals {
+		if epoch-w.epochs[j] < burnRateBuckets {
+			total += w.totals[j]
+			errs += w.errors[j]
+		}
+	}
+	return errs, total
+}
@@ -0,0 +1,62 @@
+package plog
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBurnRateProcessor(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	var alerts []BurnRateAlert
+	p := NewBurnRateProcessor(BurnRateOptions{
+		Objective:  0.9,
+		Windows:    []BurnRateWindow{{Window: time.Minute, Threshold: 2}},
+		MinRecords: 5,
+		OnAlert:    func(a BurnRateAlert) { alerts = append(alerts, a) },
+	})
+	p.now = func() time.Time { return now }
+
+	l := NewWithOptions(&bytes.Buffer{}, Options{Processors: []Processor{p}})
+	for i := 0; i < 8; i++ {
+		l.Info("ok")
+	}
+	// 2 errors out of 10 records is a 0.2 error rate, twice the budget.
+	l.Error("failed")
+	require.Empty(t, alerts)
+	l.Error("failed")
+	require.Len(t, alerts, 1)
+	require.Equal(t, uint64(2), alerts[0].Errors)
+	require.Equal(t, uint64(10), alerts[0].Total)
+	require.InDelta(t, 2, alerts[0].BurnRate, 1e-9)
+	require.Equal(t, "failed", alerts[0].Entry.Message)
+
+	// Still firing, no new alert.
+	l.Error("failed")
+	require.Len(t, alerts, 1)
+
+	// The window rolls over, the rate goes back to zero and alerts re-arm.
+	now = now.Add(2 * time.Minute)
+	for i := 0; i < 5; i++ {
+		l.Info("ok")
+	}
+	for i := 0; i < 2; i++ {
+		l.Error("failed")
+	}
+	require.Len(t, alerts, 2)
+}
+
+func TestBurnRateProcessorFields(t *testing.T) {
+	var buf bytes.Buffer
+	p := NewBurnRateProcessor(BurnRateOptions{
+		Objective:  0.5,
+		Windows:    []BurnRateWindow{{Window: time.Hour, Threshold: 1}},
+		MinRecords: 2,
+	})
+	l := NewWithOptions(&buf, Options{Formatter: JSONFormatter, Processors: []Processor{p}})
+	l.Info("ok")
+	l.Error("failed")
+	require.Contains(t, buf.String(), `"msg":"failed","burn_rate":1,"burn_rate_window":"1h0m0s"`)
+}
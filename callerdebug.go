@@ -0,0 +1,50 @@
+package plog
+
+import (
+	"fmt"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+)
+
+// WithCallerDebug returns a new logger that reports, once, the frames it
+// considered when the caller attribution looks wrong: when it ran out of
+// frames while skipping helpers, when there was no frame to report, or when
+// the reported frame is inside the logger itself. It helps troubleshooting
+// wrong file:line output of wrappers and Helper() functions.
+func (l *Logger) WithCallerDebug() *Logger {
+	sl := l.With()
+	sl.callerDebug = &sync.Once{}
+	return sl
+}
+
+// debugCaller reports the considered frames if the chosen one looks wrong.
+func (l *Logger) debugCaller(frame runtime.Frame, considered []runtime.Frame, exhausted bool) {
+	var reason string
+	switch {
+	case frame.PC == 0:
+		reason = "no frame to report, the caller offset may be too large"
+	case exhausted:
+		reason = "ran out of frames while skipping helpers"
+	case strings.HasPrefix(frame.Function, pkgPath+".(*Logger)."):
+		reason = "caller is inside the logger, the caller offset may be too small"
+	default:
+		return
+	}
+
+	l.callerDebug.Do(func() {
+		var sb strings.Builder
+		for i, f := range considered {
+			if i > 0 {
+				sb.WriteByte('\n')
+			}
+			fmt.Fprintf(&sb, "%s %s:%d", f.Function, f.File, f.Line)
+			if _, helper := l.helpers.Load(f.Function); helper {
+				sb.WriteString(" (helper)")
+			}
+		}
+		l.handle(WarnLevel, l.timeFunc(time.Now()), nil, "caller attribution looks wrong",
+			"reason", reason, "offset", l.callerOffset, "frames", sb.String())
+	})
+}
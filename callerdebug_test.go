@@ -0,0 +1,71 @@
+package plog
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCallerDebug(t *testing.T) {
+	cases := []struct {
+		name   string
+		offset int
+		reason string
+	}{
+		{
+			name:   "offset too large",
+			offset: 100,
+			reason: "no frame to report, the caller offset may be too large",
+		},
+		{
+			name:   "offset too small",
+			offset: -1,
+			reason: "caller is inside the logger, the caller offset may be too small",
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			l := NewWithOptions(&buf, Options{
+				Formatter:    LogfmtFormatter,
+				ReportCaller: true,
+				CallerOffset: c.offset,
+			}).WithCallerDebug()
+			l.Info("one")
+			l.Info("two")
+
+			lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+			require.Len(t, lines, 3)
+			require.Contains(t, lines[0], "level=warn")
+			require.Contains(t, lines[0], `msg="caller attribution looks wrong"`)
+			require.Contains(t, lines[0], `reason="`+c.reason+`"`)
+			require.Contains(t, lines[1], "msg=one")
+			require.Contains(t, lines[2], "msg=two")
+		})
+	}
+
+	t.Run("correct attribution", func(t *testing.T) {
+		var buf bytes.Buffer
+		l := NewWithOptions(&buf, Options{Formatter: LogfmtFormatter, ReportCaller: true}).WithCallerDebug()
+		l.Info("one")
+		require.Equal(t, 1, strings.Count(buf.String(), "\n"))
+	})
+
+	t.Run("exhausted helpers", func(t *testing.T) {
+		var buf bytes.Buffer
+		l := NewWithOptions(&buf, Options{Formatter: LogfmtFormatter, ReportCaller: true}).WithCallerDebug()
+		frames := l.frames(0)
+		for {
+			f, more := frames.Next()
+			l.helpers.Store(f.Function, struct{}{})
+			if !more {
+				break
+			}
+		}
+		l.Info("one")
+		require.Contains(t, buf.String(), `reason="ran out of frames while skipping helpers"`)
+		require.Contains(t, buf.String(), "(helper)")
+	})
+}
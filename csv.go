@@ -0,0 +1,118 @@
+package plog
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// CSVOptions configures the columns of the CSVFormatter.
+type CSVOptions struct {
+	// Columns are the keys written as columns, in order. Built-in keys such as
+	// TimestampKey and LevelKey can be used along with record keys. Columns
+	// missing from a record are left empty. The default is the time, level,
+	// prefix, and message.
+	Columns []string
+	// Comma is the field delimiter. Use '\t' for TSV. The default is ','.
+	Comma rune
+	// Header writes the column names as the first row.
+	Header bool
+}
+
+// csvState is the CSVFormatter configuration shared by a logger and its
+// sub-loggers, so that the header is only written once per output.
+type csvState struct {
+	opts   CSVOptions
+	header sync.Once
+}
+
+var defaultCSVColumns = []string{TimestampKey, LevelKey, PrefixKey, MessageKey}
+
+// SetCSV sets the columns of the CSVFormatter. A nil o restores the default
+// columns.
+func (l *Logger) SetCSV(o *CSVOptions) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.csv = newCSVState(o)
+}
+
+func newCSVState(o *CSVOptions) *csvState {
+	s := &csvState{}
+	if o != nil {
+		s.opts = *o
+	}
+	if len(s.opts.Columns) == 0 {
+		s.opts.Columns = defaultCSVColumns
+	}
+	if s.opts.Comma == 0 {
+		s.opts.Comma = ','
+	}
+	return s
+}
+
+// csvHeader returns the CSV state of the logger if it writes CSV records,
+// whose header goes before the first record written.
+func (l *Logger) csvHeader() *csvState {
+	if l.formatter != CSVFormatter {
+		return nil
+	}
+	return l.csv
+}
+
+// withHeader returns p preceded by the header if it is the first record
+// written. It must be called while holding the write lock, so that no record
+// is written before the header.
+func (s *csvState) withHeader(p []byte) []byte {
+	s.header.Do(func() {
+		if !s.opts.Header {
+			return
+		}
+		var b bytes.Buffer
+		w := csv.NewWriter(&b)
+		w.Comma = s.opts.Comma
+		_ = w.Write(s.opts.Columns)
+		w.Flush()
+		p = append(b.Bytes(), p...)
+	})
+	return p
+}
+
+func (l *Logger) csvFormatter(b *bytes.Buffer, keyvals ...interface{}) {
+	w := csv.NewWriter(b)
+	w.Comma = l.csv.opts.Comma
+
+	record := make([]string, len(l.csv.opts.Columns))
+	for i, col := range l.csv.opts.Columns {
+		// Later keyvals override earlier ones, as with sub-logger fields.
+		for j := len(keyvals) - 2; j >= 0; j -= 2 {
+			if fmt.Sprint(keyvals[j]) == col {
+				record[i] = l.csvValue(col, keyvals[j+1])
+				break
+			}
+		}
+	}
+	_ = w.Write(record)
+	w.Flush()
+}
+
+func (l *Logger) csvValue(key string, value interface{}) string {
+	switch v := value.(type) {
+	case time.Time:
+		if key == TimestampKey {
+			return l.formatTime(CSVFormatter, v)
+		}
+		return v.Format(time.RFC3339Nano)
+	case string:
+		return v
+	case error:
+		return v.Error()
+	case fmt.Stringer:
+		return v.String()
+	case nil:
+		return ""
+	default:
+		return fmt.Sprintf("%+v", v)
+	}
+}
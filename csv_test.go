@@ -0,0 +1,60 @@
+package plog
+
+import (
+	"bytes"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCSV(t *testing.T) {
+	var buf bytes.Buffer
+	l := NewWithOptions(&buf, Options{
+		Formatter:       CSVFormatter,
+		ReportTimestamp: true,
+		TimeFunction: func(time.Time) time.Time {
+			return time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+		},
+		CSV: &CSVOptions{
+			Columns: []string{TimestampKey, LevelKey, MessageKey, "user", "err"},
+			Header:  true,
+		},
+	})
+	l.Info("hello, world", "user", "alice")
+	l.With("user", "bob").Error("failed", "err", errors.New("boom"), "user", "carol")
+
+	require.Equal(t, "time,level,msg,user,err\n"+
+		"2024/01/01 12:00:00,info,\"hello, world\",alice,\n"+
+		"2024/01/01 12:00:00,error,failed,carol,boom\n", buf.String())
+}
+
+func TestTSVDefaultColumns(t *testing.T) {
+	var buf bytes.Buffer
+	l := NewWithOptions(&buf, Options{Formatter: CSVFormatter, Prefix: "oven"})
+	l.SetCSV(&CSVOptions{Comma: '\t'})
+	l.Warn("hot", "temp", 500)
+	require.Equal(t, "\twarn\toven\thot\n", buf.String())
+}
+
+func TestCSVHeaderConcurrent(t *testing.T) {
+	for i := 0; i < 50; i++ {
+		var buf bytes.Buffer
+		l := NewWithOptions(&buf, Options{
+			Formatter: CSVFormatter,
+			CSV:       &CSVOptions{Columns: []string{MessageKey}, Header: true},
+		})
+		var wg sync.WaitGroup
+		for j := 0; j < 4; j++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				l.Print("row")
+			}()
+		}
+		wg.Wait()
+		require.Equal(t, "msg\nrow\nrow\nrow\nrow\n", buf.String())
+	}
+}
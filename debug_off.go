@@ -0,0 +1,7 @@
+//go:build plog_nodebug
+
+package plog
+
+// debugEnabled is false with the plog_nodebug build tag: Debug and Debugf
+// return right away. Their arguments are still evaluated by the callers.
+const debugEnabled = false
@@ -0,0 +1,23 @@
+//go:build plog_nodebug
+
+package plog
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNoDebug(t *testing.T) {
+	var buf bytes.Buffer
+	l := NewWithOptions(&buf, Options{Level: DebugLevel, Formatter: LogfmtFormatter})
+	l.Debug("debug")
+	l.Debugf("debug %d", 1)
+	l.Log(DebugLevel, "debug")
+	l.LogBatch([]Entry{{Level: DebugLevel, Message: "debug"}})
+	require.Empty(t, buf.String())
+
+	l.Info("info")
+	require.Equal(t, "level=info msg=info\n", buf.String())
+}
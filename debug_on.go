@@ -0,0 +1,7 @@
+//go:build !plog_nodebug
+
+package plog
+
+// debugEnabled is whether debug logging is compiled in. Build with the
+// plog_nodebug tag to compile it out.
+const debugEnabled = true
@@ -0,0 +1,67 @@
+package plog
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// DuplicateKeys is how keyvals sharing a key are resolved, e.g. when logger
+// fields and call-site keyvals share a key.
+type DuplicateKeys uint8
+
+const (
+	// DuplicateKeepAll writes every keyval, repeating the key. This is the
+	// default.
+	DuplicateKeepAll DuplicateKeys = iota
+	// DuplicateLastWins writes the key once, at its first position, with its
+	// last value.
+	DuplicateLastWins
+	// DuplicateFirstWins writes the key once, with its first value.
+	DuplicateFirstWins
+	// DuplicateSuffix writes every keyval, suffixing the repeated keys with
+	// their occurrence number, e.g. key, key#2, key#3.
+	DuplicateSuffix
+)
+
+// SetDuplicateKeys sets how keyvals sharing a key are resolved.
+func (l *Logger) SetDuplicateKeys(d DuplicateKeys) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.duplicateKeys = d
+}
+
+// resolveDuplicates returns keyvals with their duplicate keys resolved.
+func (l *Logger) resolveDuplicates(keyvals []interface{}) []interface{} {
+	if l.duplicateKeys == DuplicateKeepAll || len(keyvals) <= 2 {
+		return keyvals
+	}
+
+	// index maps a key to the position of its first value, and count to
+	// its number of occurrences.
+	index := make(map[string]int, len(keyvals)/2)
+	var count map[string]int
+	out := keyvals[:0:0]
+	for i := 0; i+1 < len(keyvals); i += 2 {
+		key := fmt.Sprint(keyvals[i])
+		first, dup := index[key]
+		if !dup {
+			index[key] = len(out) + 1
+			out = append(out, keyvals[i], keyvals[i+1])
+			continue
+		}
+		switch l.duplicateKeys {
+		case DuplicateLastWins:
+			out[first] = keyvals[i+1]
+		case DuplicateSuffix:
+			if count == nil {
+				count = map[string]int{}
+			}
+			if count[key] == 0 {
+				count[key] = 1
+			}
+			count[key]++
+			out = append(out, key+"#"+strconv.Itoa(count[key]), keyvals[i+1])
+		}
+	}
+	return out
+}
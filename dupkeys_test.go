@@ -0,0 +1,32 @@
+package plog
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDuplicateKeys(t *testing.T) {
+	cases := []struct {
+		policy DuplicateKeys
+		want   string
+	}{
+		{DuplicateKeepAll, "msg=hi user=a n=1 user=b user=c\n"},
+		{DuplicateLastWins, "msg=hi user=c n=1\n"},
+		{DuplicateFirstWins, "msg=hi user=a n=1\n"},
+		{DuplicateSuffix, "msg=hi user=a n=1 user#2=b user#3=c\n"},
+	}
+	for _, c := range cases {
+		var buf bytes.Buffer
+		l := NewWithOptions(&buf, Options{Formatter: LogfmtFormatter, DuplicateKeys: c.policy})
+		l.With("user", "a", "n", 1).Print("hi", "user", "b", "user", "c")
+		require.Equal(t, c.want, buf.String())
+	}
+
+	var buf bytes.Buffer
+	l := NewWithOptions(&buf, Options{Formatter: JSONFormatter})
+	l.SetDuplicateKeys(DuplicateLastWins)
+	l.With("user", "a").Print("hi", "user", "b")
+	require.Equal(t, `{"msg":"hi","user":"b"}`+"\n", buf.String())
+}
@@ -0,0 +1,122 @@
+package plog
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DurationFormat is how time.Duration values are rendered.
+type DurationFormat struct {
+	// Unit is the fixed unit of the durations, e.g. time.Millisecond. The
+	// default, zero, picks the unit of each duration: 350ms, 1.2s, 4m12s.
+	Unit time.Duration
+	// Precision is the number of decimals of the durations. The default,
+	// zero, keeps three significant digits.
+	Precision int
+}
+
+// SetDurationFormat sets how the TextFormatter and LogfmtFormatter render
+// time.Duration values, instead of the Go formatting. The JSONFormatter then
+// renders them as numbers of the fixed unit, or milliseconds. A nil format
+// restores the default rendering.
+func (l *Logger) SetDurationFormat(f *DurationFormat) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.durationFormat = f
+}
+
+// duration returns the duration value of v.
+func duration(v interface{}) (time.Duration, bool) {
+	switch v := v.(type) {
+	case time.Duration:
+		return v, true
+	case Field:
+		if v.kind == fieldDuration {
+			return time.Duration(v.num), true
+		}
+	}
+	return 0, false
+}
+
+// formatDuration returns v formatted with the duration format. It returns
+// false if there is no duration format or v isn't a duration.
+func (l *Logger) formatDuration(v interface{}) (string, bool) {
+	f := l.durationFormat
+	d, ok := duration(v)
+	if f == nil || !ok {
+		return "", false
+	}
+	if f.Unit > 0 {
+		return f.decimal(float64(d)/float64(f.Unit)) + unitSuffix(f.Unit), true
+	}
+
+	sign := ""
+	if d < 0 {
+		sign, d = "-", -d
+	}
+	switch {
+	case d >= time.Hour:
+		d = d.Round(time.Minute)
+		return sign + strconv.Itoa(int(d/time.Hour)) + "h" + strconv.Itoa(int(d%time.Hour/time.Minute)) + "m", true
+	case d >= time.Minute:
+		d = d.Round(time.Second)
+		return sign + strconv.Itoa(int(d/time.Minute)) + "m" + strconv.Itoa(int(d%time.Minute/time.Second)) + "s", true
+	}
+	unit := time.Nanosecond
+	for _, u := range []time.Duration{time.Second, time.Millisecond, time.Microsecond} {
+		if d >= u {
+			unit = u
+			break
+		}
+	}
+	return sign + f.decimal(float64(d)/float64(unit)) + unitSuffix(unit), true
+}
+
+// decimal returns n with the precision of the format.
+func (f *DurationFormat) decimal(n float64) string {
+	if f.Precision > 0 {
+		return strconv.FormatFloat(n, 'f', f.Precision, 64)
+	}
+	s := strconv.FormatFloat(n, 'f', -1, 64)
+	// Keep three significant digits.
+	digits := 3
+	if i := strings.IndexByte(s, '.'); i != -1 {
+		digits -= len(strings.TrimLeft(s[:i], "-0"))
+		if digits <= 0 {
+			return s[:i]
+		}
+		s = strconv.FormatFloat(n, 'f', digits, 64)
+		s = strings.TrimRight(strings.TrimRight(s, "0"), ".")
+	}
+	return s
+}
+
+// unitSuffix returns the suffix of the unit u.
+func unitSuffix(u time.Duration) string {
+	switch u {
+	case time.Nanosecond:
+		return "ns"
+	case time.Microsecond:
+		return "µs"
+	case time.Millisecond:
+		return "ms"
+	case time.Second:
+		return "s"
+	case time.Minute:
+		return "m"
+	case time.Hour:
+		return "h"
+	}
+	return "×" + u.String()
+}
+
+// jsonDuration returns the JSON number of the duration d, in the fixed unit
+// or milliseconds.
+func (l *Logger) jsonDuration(d time.Duration) float64 {
+	unit := l.durationFormat.Unit
+	if unit <= 0 {
+		unit = time.Millisecond
+	}
+	return float64(d) / float64(unit)
+}
@@ -0,0 +1,57 @@
+package plog
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDurationFormat(t *testing.T) {
+	auto := &DurationFormat{}
+	cases := []struct {
+		f    *DurationFormat
+		d    time.Duration
+		want string
+	}{
+		{auto, 1234567 * time.Microsecond, "1.23s"},
+		{auto, 350 * time.Millisecond, "350ms"},
+		{auto, 12345 * time.Microsecond, "12.3ms"},
+		{auto, 4*time.Minute + 12400*time.Millisecond, "4m12s"},
+		{auto, 2*time.Hour + 30*time.Minute + 40*time.Second, "2h31m"},
+		{auto, 1500 * time.Nanosecond, "1.5µs"},
+		{auto, 42, "42ns"},
+		{auto, -1500 * time.Millisecond, "-1.5s"},
+		{&DurationFormat{Precision: 2}, 1200 * time.Millisecond, "1.20s"},
+		{&DurationFormat{Unit: time.Millisecond, Precision: 1}, 1234567 * time.Microsecond, "1234.6ms"},
+		{&DurationFormat{Unit: time.Second}, 90 * time.Second, "90s"},
+	}
+	for _, c := range cases {
+		l := New(nil)
+		l.SetDurationFormat(c.f)
+		got, ok := l.formatDuration(c.d)
+		require.True(t, ok)
+		require.Equal(t, c.want, got, c.d.String())
+	}
+
+	var buf bytes.Buffer
+	l := NewWithOptions(&buf, Options{DurationFormat: auto})
+	l.Print("", "took", 1234567*time.Microsecond, Dur("wait", 350*time.Millisecond))
+	require.Equal(t, "took=1.23s wait=350ms\n", buf.String())
+
+	buf.Reset()
+	l.SetFormatter(LogfmtFormatter)
+	l.Print("", "took", 1234567*time.Microsecond, Dur("wait", 350*time.Millisecond))
+	require.Equal(t, "took=1.23s wait=350ms\n", buf.String())
+
+	buf.Reset()
+	l.SetFormatter(JSONFormatter)
+	l.Print("", "took", 1234567*time.Microsecond, Dur("wait", 350*time.Millisecond))
+	require.Equal(t, `{"took":1234.567,"wait":350}`+"\n", buf.String())
+
+	buf.Reset()
+	l.SetDurationFormat(nil)
+	l.Print("", "took", 1500*time.Millisecond)
+	require.Equal(t, `{"took":"1.5s"}`+"\n", buf.String())
+}
@@ -0,0 +1,81 @@
+package plog
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// ecsVersion is the Elastic Common Schema version of the ECSFormatter output.
+const ecsVersion = "8.11.0"
+
+func (l *Logger) ecsFormatter(b *bytes.Buffer, keyvals ...interface{}) {
+	jw := &jsonWriter{w: b}
+	jw.start()
+
+	var (
+		labels  [][2]interface{}
+		errSeen bool
+	)
+	for i := 0; i < len(keyvals); i += 2 {
+		switch keyvals[i] {
+		case TimestampKey:
+			if t, ok := keyvals[i+1].(time.Time); ok {
+				jw.objectItem("@timestamp", t.UTC().Format(time.RFC3339Nano))
+			}
+		case LevelKey:
+			if level, ok := keyvals[i+1].(Level); ok {
+				jw.objectItem("log.level", level.String())
+			}
+		case CallerKey:
+			if caller, ok := keyvals[i+1].(string); ok {
+				file, line := splitCaller(caller)
+				jw.objectItem("log.origin.file.name", file)
+				if line != "" {
+					n, _ := strconv.Atoi(line)
+					jw.objectItem("log.origin.file.line", n)
+				}
+			}
+		case PrefixKey:
+			if prefix, ok := keyvals[i+1].(string); ok {
+				jw.objectItem("log.logger", prefix)
+			}
+		case MessageKey:
+			jw.objectItem("message", fmt.Sprint(keyvals[i+1]))
+		default:
+			if err, ok := keyvals[i+1].(error); ok && !errSeen {
+				errSeen = true
+				jw.objectItem("error.message", err.Error())
+				jw.objectItem("error.type", fmt.Sprintf("%T", err))
+				continue
+			}
+			labels = append(labels, [2]interface{}{keyvals[i], keyvals[i+1]})
+		}
+	}
+	jw.objectItem("ecs.version", ecsVersion)
+
+	if len(labels) > 0 {
+		jw.objectKey("labels")
+		lw := &jsonWriter{w: b}
+		lw.start()
+		for _, kv := range labels {
+			lw.objectKey(fmt.Sprint(kv[0]))
+			switch v := kv[1].(type) {
+			case bool, string, int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64,
+				float32, float64:
+				lw.objectValue(v)
+			case error:
+				lw.objectValue(v.Error())
+			case fmt.Stringer:
+				lw.objectValue(v.String())
+			default:
+				lw.objectValue(fmt.Sprintf("%+v", v))
+			}
+		}
+		lw.end()
+	}
+
+	jw.end()
+	b.WriteRune('\n')
+}
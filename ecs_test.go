@@ -0,0 +1,57 @@
+package plog
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestECS(t *testing.T) {
+	var buf bytes.Buffer
+	l := NewWithOptions(&buf, Options{
+		Formatter:       ECSFormatter,
+		ReportTimestamp: true,
+		TimeFunction: func(time.Time) time.Time {
+			return time.Date(2024, 1, 1, 12, 0, 0, 0, time.FixedZone("CET", 3600))
+		},
+		Prefix: "oven",
+	})
+
+	cases := []struct {
+		name     string
+		expected string
+		kvs      []interface{}
+	}{
+		{
+			name: "simple",
+			expected: `{"@timestamp":"2024-01-01T11:00:00Z","log.level":"error","log.logger":"oven",` +
+				`"message":"burnt","ecs.version":"` + ecsVersion + `"}` + "\n",
+		},
+		{
+			name: "error and labels",
+			expected: `{"@timestamp":"2024-01-01T11:00:00Z","log.level":"error","log.logger":"oven",` +
+				`"message":"burnt","error.message":"too hot","error.type":"*errors.errorString",` +
+				`"ecs.version":"` + ecsVersion + `","labels":{"temp":500,"tray":"[1 2]","ok":false}}` + "\n",
+			kvs: []interface{}{"err", errors.New("too hot"), "temp", 500, "tray", []int{1, 2}, "ok", false},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			buf.Reset()
+			l.Error("burnt", c.kvs...)
+			require.Equal(t, c.expected, buf.String())
+		})
+	}
+}
+
+func TestECSCaller(t *testing.T) {
+	var buf bytes.Buffer
+	l := NewWithOptions(&buf, Options{Formatter: ECSFormatter, ReportCaller: true})
+	l.SetCallerFormatter(func(string, int, string) string { return "pkg/file.go:42" })
+	l.Info("hi")
+	require.Equal(t, `{"log.level":"info","log.origin.file.name":"pkg/file.go","log.origin.file.line":42,`+
+		`"message":"hi","ecs.version":"`+ecsVersion+`"}`+"\n", buf.String())
+}
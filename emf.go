@@ -0,0 +1,119 @@
+package plog
+
+import (
+	"fmt"
+	"time"
+)
+
+// EMFOptions configures the AWS CloudWatch Embedded Metric Format mode of the
+// JSONFormatter.
+type EMFOptions struct {
+	// Namespace is the CloudWatch metrics namespace.
+	Namespace string
+	// Metrics are the keys whose numeric values are emitted as metrics. Their
+	// unit is taken from the key FieldMeta, see WithUnit.
+	Metrics []string
+	// Dimensions are the sets of keys used as metric dimensions. A set is
+	// only used when all its keys are present in the record.
+	Dimensions [][]string
+}
+
+// emfUnits maps common unit abbreviations to CloudWatch units.
+var emfUnits = map[string]string{
+	"us":    "Microseconds",
+	"µs":    "Microseconds",
+	"ms":    "Milliseconds",
+	"s":     "Seconds",
+	"B":     "Bytes",
+	"bytes": "Bytes",
+	"KB":    "Kilobytes",
+	"MB":    "Megabytes",
+	"GB":    "Gigabytes",
+	"%":     "Percent",
+	"count": "Count",
+	"B/s":   "Bytes/Second",
+}
+
+type emfMetric struct {
+	Name string `json:"Name"`
+	Unit string `json:"Unit,omitempty"`
+}
+
+type emfDirective struct {
+	Namespace  string      `json:"Namespace"`
+	Dimensions [][]string  `json:"Dimensions"`
+	Metrics    []emfMetric `json:"Metrics"`
+}
+
+type emfMetadata struct {
+	Timestamp         int64          `json:"Timestamp"`
+	CloudWatchMetrics []emfDirective `json:"CloudWatchMetrics"`
+}
+
+func isNumber(v interface{}) bool {
+	switch v.(type) {
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64:
+		return true
+	default:
+		return false
+	}
+}
+
+// jsonEMF writes the "_aws" metadata object for the configured metrics
+// present in keyvals.
+func (l *Logger) jsonEMF(jw *jsonWriter, keyvals []interface{}) {
+	if l.emf == nil {
+		return
+	}
+
+	ts := time.Now()
+	present := map[string]bool{}
+	for i := 0; i+1 < len(keyvals); i += 2 {
+		if keyvals[i] == TimestampKey {
+			if t, ok := keyvals[i+1].(time.Time); ok {
+				ts = t
+			}
+		}
+		present[fmt.Sprint(keyvals[i])] = isNumber(keyvals[i+1])
+	}
+
+	directive := emfDirective{Namespace: l.emf.Namespace, Dimensions: [][]string{}}
+	for _, key := range l.emf.Metrics {
+		if !present[key] {
+			continue
+		}
+		unit := l.meta[key].Unit
+		if u, ok := emfUnits[unit]; ok {
+			unit = u
+		}
+		directive.Metrics = append(directive.Metrics, emfMetric{Name: key, Unit: unit})
+	}
+	if len(directive.Metrics) == 0 {
+		return
+	}
+
+dimensions:
+	for _, set := range l.emf.Dimensions {
+		for _, key := range set {
+			if _, ok := present[key]; !ok {
+				continue dimensions
+			}
+		}
+		directive.Dimensions = append(directive.Dimensions, set)
+	}
+
+	jw.objectItem("_aws", emfMetadata{
+		Timestamp:         ts.UnixMilli(),
+		CloudWatchMetrics: []emfDirective{directive},
+	})
+}
+
+// SetEMF enables the AWS CloudWatch Embedded Metric Format mode of the
+// JSONFormatter: records with numeric values for the configured metrics carry
+// the metadata CloudWatch needs to extract them as metrics. A nil o disables
+// it.
+func (l *Logger) SetEMF(o *EMFOptions) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.emf = o
+}
@@ -0,0 +1,52 @@
+package plog
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestEMF(t *testing.T) {
+	var buf bytes.Buffer
+	l := NewWithOptions(&buf, Options{
+		Formatter:       JSONFormatter,
+		ReportTimestamp: true,
+		TimeFunction:    func(time.Time) time.Time { return time.UnixMilli(1700000000123) },
+		EMF: &EMFOptions{
+			Namespace:  "bakery",
+			Metrics:    []string{"latency", "cookies"},
+			Dimensions: [][]string{{"oven"}, {"oven", "shift"}},
+		},
+	}).WithUnit("latency", "ms")
+	l.SetTimeFormat(time.RFC3339)
+
+	cases := []struct {
+		name     string
+		expected string
+		kvs      []interface{}
+	}{
+		{
+			name: "metrics",
+			expected: `{"time":"` + time.UnixMilli(1700000000123).Format(time.RFC3339) + `","level":"info","msg":"baked",` +
+				`"oven":"a","latency":12.5,"cookies":3,"_meta":{"latency":{"unit":"ms"}},` +
+				`"_aws":{"Timestamp":1700000000123,"CloudWatchMetrics":[{"Namespace":"bakery",` +
+				`"Dimensions":[["oven"]],"Metrics":[{"Name":"latency","Unit":"Milliseconds"},{"Name":"cookies"}]}]}}` + "\n",
+			kvs: []interface{}{"oven", "a", "latency", 12.5, "cookies", 3},
+		},
+		{
+			name: "non numeric metric",
+			expected: `{"time":"` + time.UnixMilli(1700000000123).Format(time.RFC3339) + `","level":"info","msg":"baked",` +
+				`"cookies":"many"}` + "\n",
+			kvs: []interface{}{"cookies", "many"},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			buf.Reset()
+			l.Info("baked", c.kvs...)
+			require.Equal(t, c.expected, buf.String())
+		})
+	}
+}
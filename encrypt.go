@@ -0,0 +1,155 @@
+package plog
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// EncryptedValuePrefix is the prefix of encrypted values.
+const EncryptedValuePrefix = "enc:v1:"
+
+const dataKeySize = 32
+
+// ErrNotEncrypted is returned when decrypting a value that wasn't encrypted
+// by the logger.
+var ErrNotEncrypted = errors.New("value is not encrypted")
+
+// encryptProcessor encrypts the values of the given keys using envelope
+// encryption: each value is sealed with a random AES-256-GCM data key, which
+// is itself encrypted with the RSA public key using OAEP.
+type encryptProcessor struct {
+	pub  *rsa.PublicKey
+	keys map[string]struct{}
+}
+
+func (p *encryptProcessor) Process(e *Entry) bool {
+	p.encrypt(e.Keyvals)
+	return true
+}
+
+// encrypt encrypts the values of keyvals in place, recursing into groups.
+func (p *encryptProcessor) encrypt(keyvals []interface{}) {
+	for i := 0; i+1 < len(keyvals); i += 2 {
+		if g, ok := keyvals[i+1].(groupValue); ok {
+			// Groups may be shared with the logger fields, so encrypt a copy.
+			g = append(groupValue(nil), g...)
+			p.encrypt(g)
+			keyvals[i+1] = g
+			continue
+		}
+		key, ok := keyvals[i].(string)
+		if !ok {
+			continue
+		}
+		if _, ok := p.keys[key]; !ok {
+			continue
+		}
+		var val string
+		switch v := keyvals[i+1].(type) {
+		case error:
+			val = v.Error()
+		case fmt.Stringer:
+			val = v.String()
+		default:
+			val = fmt.Sprintf("%+v", v)
+		}
+		enc, err := encryptValue(p.pub, []byte(val))
+		if err != nil {
+			// Never leak the plaintext.
+			enc = "enc:error"
+		}
+		keyvals[i+1] = enc
+	}
+}
+
+func encryptValue(pub *rsa.PublicKey, plaintext []byte) (string, error) {
+	dataKey := make([]byte, dataKeySize)
+	if _, err := rand.Read(dataKey); err != nil {
+		return "", err
+	}
+	wrapped, err := rsa.EncryptOAEP(sha256.New(), rand.Reader, pub, dataKey, nil)
+	if err != nil {
+		return "", err
+	}
+	gcm, err := newGCM(dataKey)
+	if err != nil {
+		return "", err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+
+	out := make([]byte, 0, len(wrapped)+len(nonce)+len(plaintext)+gcm.Overhead())
+	out = append(out, wrapped...)
+	out = append(out, nonce...)
+	out = gcm.Seal(out, nonce, plaintext, nil)
+	return EncryptedValuePrefix + base64.StdEncoding.EncodeToString(out), nil
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// DecryptValue decrypts a value encrypted by a logger created with
+// WithEncryptedKeys using the matching private key.
+func DecryptValue(value string, priv *rsa.PrivateKey) (string, error) {
+	if !strings.HasPrefix(value, EncryptedValuePrefix) {
+		return "", ErrNotEncrypted
+	}
+	b, err := base64.StdEncoding.DecodeString(value[len(EncryptedValuePrefix):])
+	if err != nil {
+		return "", err
+	}
+	size := priv.Size()
+	if len(b) < size {
+		return "", ErrNotEncrypted
+	}
+	dataKey, err := rsa.DecryptOAEP(sha256.New(), nil, priv, b[:size], nil)
+	if err != nil {
+		return "", err
+	}
+	gcm, err := newGCM(dataKey)
+	if err != nil {
+		return "", err
+	}
+	b = b[size:]
+	if len(b) < gcm.NonceSize() {
+		return "", ErrNotEncrypted
+	}
+	plaintext, err := gcm.Open(nil, b[:gcm.NonceSize()], b[gcm.NonceSize():], nil)
+	if err != nil {
+		return "", err
+	}
+	return string(plaintext), nil
+}
+
+// WithEncryptedKeys returns a new logger that encrypts the values of the given
+// keys with pub before they are formatted, including keys nested in groups.
+// Values are replaced by a string starting with EncryptedValuePrefix that can
+// be decrypted with DecryptValue and the matching private key.
+//
+// Encryption runs before the processors inherited from l, so they never see
+// the plaintext values.
+func (l *Logger) WithEncryptedKeys(pub *rsa.PublicKey, keys ...string) *Logger {
+	p := &encryptProcessor{pub: pub, keys: make(map[string]struct{}, len(keys))}
+	for _, k := range keys {
+		p.keys[k] = struct{}{}
+	}
+	sl := l.With()
+	sl.mu.Lock()
+	sl.processors = append([]Processor{p}, sl.processors...)
+	sl.mu.Unlock()
+	return sl
+}
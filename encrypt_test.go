@@ -0,0 +1,85 @@
+package plog
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithEncryptedKeys(t *testing.T) {
+	priv, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	l := NewWithOptions(&buf, Options{Formatter: JSONFormatter}).
+		WithEncryptedKeys(&priv.PublicKey, "ssn", "card")
+	l.Info("signup", "user", "frank", "ssn", "078-05-1120", "card", 4111111111111111)
+
+	var m map[string]string
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &m))
+	require.Equal(t, "frank", m["user"])
+	require.NotContains(t, buf.String(), "078-05-1120")
+
+	ssn, err := DecryptValue(m["ssn"], priv)
+	require.NoError(t, err)
+	require.Equal(t, "078-05-1120", ssn)
+	card, err := DecryptValue(m["card"], priv)
+	require.NoError(t, err)
+	require.Equal(t, "4111111111111111", card)
+
+	other, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	_, err = DecryptValue(m["ssn"], other)
+	require.Error(t, err)
+
+	_, err = DecryptValue("frank", priv)
+	require.ErrorIs(t, err, ErrNotEncrypted)
+}
+
+func TestWithEncryptedKeysBeforeProcessors(t *testing.T) {
+	priv, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	var seen []interface{}
+	l := New(discardWriter{})
+	l.AddProcessor(ProcessorFunc(func(e *Entry) bool {
+		seen = append([]interface{}(nil), e.Keyvals...)
+		return true
+	}))
+	l.WithEncryptedKeys(&priv.PublicKey, "ssn").Info("signup", "ssn", "078-05-1120")
+
+	require.Len(t, seen, 2)
+	ssn, err := DecryptValue(seen[1].(string), priv)
+	require.NoError(t, err)
+	require.Equal(t, "078-05-1120", ssn)
+}
+
+func TestWithEncryptedKeysGroup(t *testing.T) {
+	priv, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	l := NewWithOptions(&buf, Options{Formatter: JSONFormatter}).
+		With(Group("user", "name", "frank", "ssn", "078-05-1120")).
+		WithEncryptedKeys(&priv.PublicKey, "ssn")
+	l.Info("signup", Group("payment", Group("card", "ssn", "219-09-9999")))
+	require.NotContains(t, buf.String(), "078-05-1120")
+	require.NotContains(t, buf.String(), "219-09-9999")
+
+	var m struct {
+		User    map[string]string
+		Payment struct{ Card map[string]string }
+	}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &m))
+	require.Equal(t, "frank", m.User["name"])
+	ssn, err := DecryptValue(m.User["ssn"], priv)
+	require.NoError(t, err)
+	require.Equal(t, "078-05-1120", ssn)
+	ssn, err = DecryptValue(m.Payment.Card["ssn"], priv)
+	require.NoError(t, err)
+	require.Equal(t, "219-09-9999", ssn)
+}
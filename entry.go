@@ -0,0 +1,28 @@
+package plog
+
+import "time"
+
+// Entry is a single log record.
+type Entry struct {
+	// Time is the time the record was logged at, as returned by the logger
+	// time function.
+	Time time.Time
+	// Level is the level of the record.
+	Level Level
+	// Caller is the formatted caller location. It's empty unless the logger
+	// reports the caller.
+	Caller string
+	// Prefix is the logger prefix.
+	Prefix string
+	// Message is the record message.
+	Message string
+	// Keyvals are the logger fields followed by the call-site keyvals.
+	Keyvals []interface{}
+
+	// file and line are the caller location.
+	file string
+	line int
+	// msg is the message as passed to the logger, already boxed, so that it
+	// isn't boxed again unless a processor changed it.
+	msg interface{}
+}
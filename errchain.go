@@ -0,0 +1,99 @@
+package plog
+
+import (
+	"errors"
+	"strings"
+)
+
+// causedByPrefix prefixes the causes of an error in the text formatter.
+const causedByPrefix = "caused by: "
+
+// jsonError is the JSON encoding of an error with causes.
+type jsonError struct {
+	Error  string   `json:"error"`
+	Causes []string `json:"causes"`
+}
+
+// SetErrorChains sets whether error values are rendered with their cause
+// chain, as returned by errors.Unwrap: as an indented list in the text
+// formatter, and as an object with the error message and a "causes" array in
+// the JSON formatter. Errors without causes are rendered as usual.
+func (l *Logger) SetErrorChains(enabled bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.errorChains = enabled
+}
+
+// errorCauses returns the messages of the errors wrapped by err, outermost
+// first.
+func errorCauses(err error) []string {
+	var causes []string
+	for cause := errors.Unwrap(err); cause != nil; cause = errors.Unwrap(cause) {
+		causes = append(causes, cause.Error())
+	}
+	return causes
+}
+
+// textError returns the text rendering of err with its causes, one per line.
+func textError(err error) (string, bool) {
+	causes := errorCauses(err)
+	if len(causes) == 0 {
+		return "", false
+	}
+	s := err.Error()
+	for _, c := range causes {
+		s += "\n" + causedByPrefix + c
+	}
+	return s, true
+}
+
+// joinedErrors returns the errors of a joined error, such as returned by
+// errors.Join or a multierror implementing Unwrap() []error. Nested joined
+// errors are flattened. It returns nil if err isn't a joined error.
+func joinedErrors(err error) []error {
+	j, ok := err.(interface{ Unwrap() []error })
+	if !ok {
+		return nil
+	}
+	var errs []error
+	for _, e := range j.Unwrap() {
+		if e == nil {
+			continue
+		}
+		if nested := joinedErrors(e); nested != nil {
+			errs = append(errs, nested...)
+			continue
+		}
+		errs = append(errs, e)
+	}
+	return errs
+}
+
+// textJoinedError returns the text rendering of a joined error, one error per
+// line.
+func (l *Logger) textJoinedError(errs []error) string {
+	lines := make([]string, len(errs))
+	for i, e := range errs {
+		lines[i] = e.Error()
+		if l.errorChains {
+			if s, ok := textError(e); ok {
+				lines[i] = s
+			}
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// jsonJoinedError returns the JSON encoding of a joined error, an array with
+// an element per error.
+func (l *Logger) jsonJoinedError(errs []error) []interface{} {
+	arr := make([]interface{}, len(errs))
+	for i, e := range errs {
+		if causes := errorCauses(e); l.errorChains && len(causes) > 0 {
+			arr[i] = jsonError{Error: e.Error(), Causes: causes}
+			continue
+		}
+		arr[i] = e.Error()
+	}
+	return arr
+}
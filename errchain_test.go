@@ -0,0 +1,83 @@
+package plog
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestErrorChains(t *testing.T) {
+	err := fmt.Errorf("open config: %w", fmt.Errorf("read: %w", io.EOF))
+
+	t.Run("text", func(t *testing.T) {
+		var buf bytes.Buffer
+		l := NewWithOptions(&buf, Options{ErrorChains: true})
+		l.Print("failed", "err", err, "plain", io.EOF)
+		require.Equal(t, "failed\n  err=\n"+
+			"  │ open config: read: EOF\n"+
+			"  │ caused by: read: EOF\n"+
+			"  │ caused by: EOF\n"+
+			" plain=EOF\n", buf.String())
+	})
+
+	t.Run("json", func(t *testing.T) {
+		var buf bytes.Buffer
+		l := NewWithOptions(&buf, Options{Formatter: JSONFormatter, ErrorChains: true})
+		l.Print("failed", "err", err, "plain", io.EOF)
+		require.Equal(t, `{"msg":"failed","err":{"error":"open config: read: EOF",`+
+			`"causes":["read: EOF","EOF"]},"plain":"EOF"}`+"\n", buf.String())
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		var buf bytes.Buffer
+		l := NewWithOptions(&buf, Options{Formatter: JSONFormatter})
+		l.Print("failed", "err", err)
+		require.Equal(t, `{"msg":"failed","err":"open config: read: EOF"}`+"\n", buf.String())
+	})
+
+	require.Empty(t, errorCauses(errors.New("root")))
+}
+
+type multiError []error
+
+func (m multiError) Error() string   { return fmt.Sprintf("%d errors", len(m)) }
+func (m multiError) Unwrap() []error { return m }
+
+func TestJoinedErrors(t *testing.T) {
+	err := errors.Join(
+		errors.New("name is required"),
+		multiError{errors.New("age is negative"), fmt.Errorf("email: %w", io.EOF)},
+	)
+
+	t.Run("text", func(t *testing.T) {
+		var buf bytes.Buffer
+		l := New(&buf)
+		l.Print("invalid", "err", err)
+		require.Equal(t, "invalid\n  err=\n"+
+			"  │ name is required\n"+
+			"  │ age is negative\n"+
+			"  │ email: EOF\n", buf.String())
+	})
+
+	t.Run("json", func(t *testing.T) {
+		var buf bytes.Buffer
+		l := NewWithOptions(&buf, Options{Formatter: JSONFormatter})
+		l.Print("invalid", "err", err)
+		require.Equal(t, `{"msg":"invalid","err":["name is required",`+
+			`"age is negative","email: EOF"]}`+"\n", buf.String())
+	})
+
+	t.Run("chains", func(t *testing.T) {
+		var buf bytes.Buffer
+		l := NewWithOptions(&buf, Options{Formatter: JSONFormatter, ErrorChains: true})
+		l.Print("invalid", "err", err)
+		require.Equal(t, `{"msg":"invalid","err":["name is required","age is negative",`+
+			`{"error":"email: EOF","causes":["EOF"]}]}`+"\n", buf.String())
+	})
+
+	require.Nil(t, joinedErrors(io.EOF))
+}
@@ -0,0 +1,76 @@
+package plog
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"runtime"
+	"strings"
+)
+
+// errorStack returns the formatted stack trace carried by err, as recorded
+// by github.com/pkg/errors and compatible packages, which implement
+// StackTrace() returning a slice of program counters. The innermost stack of
+// the chain is the one where the error originated.
+func errorStack(err error) (string, bool) {
+	var pcs []uintptr
+	for ; err != nil; err = errors.Unwrap(err) {
+		if p := stackTracePCs(err); len(p) > 0 {
+			pcs = p
+		}
+	}
+	if len(pcs) == 0 {
+		return "", false
+	}
+
+	var b strings.Builder
+	frames := runtime.CallersFrames(pcs)
+	for {
+		f, more := frames.Next()
+		fmt.Fprintf(&b, "%s\n\t%s:%d\n", f.Function, f.File, f.Line)
+		if !more {
+			break
+		}
+	}
+	return strings.TrimSuffix(b.String(), "\n"), true
+}
+
+// stackTracePCs returns the program counters of err StackTrace method. The
+// method is looked up with reflection to not depend on the package defining
+// the frame type, e.g. errors.StackTrace of github.com/pkg/errors, which is a
+// slice of uintptr-based frames.
+func stackTracePCs(err error) []uintptr {
+	m := reflect.ValueOf(err).MethodByName("StackTrace")
+	if !m.IsValid() {
+		return nil
+	}
+	t := m.Type()
+	if t.NumIn() != 0 || t.NumOut() != 1 || t.Out(0).Kind() != reflect.Slice ||
+		t.Out(0).Elem().Kind() != reflect.Uintptr {
+		return nil
+	}
+	st := m.Call(nil)[0]
+	pcs := make([]uintptr, st.Len())
+	for i := range pcs {
+		pcs[i] = uintptr(st.Index(i).Uint())
+	}
+	return pcs
+}
+
+// appendErrorStack adds the stack of the first error value of keyvals
+// carrying one under KeyStack, unless keyvals already has a KeyStack field.
+func appendErrorStack(keyvals []interface{}) []interface{} {
+	var stack string
+	for i := 0; i+1 < len(keyvals); i += 2 {
+		if keyvals[i] == KeyStack {
+			return keyvals
+		}
+		if err, ok := keyvals[i+1].(error); ok && stack == "" {
+			stack, _ = errorStack(err)
+		}
+	}
+	if stack == "" {
+		return keyvals
+	}
+	return append(keyvals, KeyStack, stack)
+}
@@ -0,0 +1,77 @@
+package plog
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// stackFrame and stackTrace mirror the github.com/pkg/errors types.
+type stackFrame uintptr
+
+type stackTrace []stackFrame
+
+type stackError struct {
+	msg   string
+	stack []uintptr
+}
+
+func newStackError(msg string) error {
+	pcs := make([]uintptr, 32)
+	n := runtime.Callers(2, pcs)
+	return &stackError{msg: msg, stack: pcs[:n]}
+}
+
+func (e *stackError) Error() string { return e.msg }
+
+func (e *stackError) StackTrace() stackTrace {
+	st := make(stackTrace, len(e.stack))
+	for i, pc := range e.stack {
+		st[i] = stackFrame(pc)
+	}
+	return st
+}
+
+func (e *stackError) Format(s fmt.State, verb rune) {
+	io.WriteString(s, e.msg) //nolint: errcheck
+	if s.Flag('+') {
+		io.WriteString(s, "\nstack goes here") //nolint: errcheck
+	}
+}
+
+func TestErrorStack(t *testing.T) {
+	err := fmt.Errorf("load: %w", newStackError("not found"))
+
+	t.Run("json", func(t *testing.T) {
+		var buf bytes.Buffer
+		l := NewWithOptions(&buf, Options{Formatter: JSONFormatter})
+		l.Print("failed", "err", err)
+		var m map[string]interface{}
+		require.NoError(t, json.Unmarshal(buf.Bytes(), &m))
+		require.Equal(t, "load: not found", m["err"])
+		stack, _ := m[KeyStack].(string)
+		require.True(t, strings.HasPrefix(stack, pkgPath+".TestErrorStack"), stack)
+	})
+
+	t.Run("text", func(t *testing.T) {
+		var buf bytes.Buffer
+		l := New(&buf)
+		l.Print("failed", "err", newStackError("not found"))
+		require.True(t, strings.HasPrefix(buf.String(), "failed err=\"not found\"\n  stack=\n"), buf.String())
+		require.NotContains(t, buf.String(), "stack goes here")
+	})
+
+	t.Run("existing stack", func(t *testing.T) {
+		kvs := appendErrorStack([]interface{}{KeyStack, "mine", "err", err})
+		require.Len(t, kvs, 4)
+	})
+
+	_, ok := errorStack(io.EOF)
+	require.False(t, ok)
+}
@@ -0,0 +1,293 @@
+package plog
+
+import (
+	"math"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// fieldKind is the type of a Field value.
+type fieldKind uint8
+
+const (
+	fieldString fieldKind = iota
+	fieldInt
+	fieldUint
+	fieldFloat
+	fieldBool
+	fieldDuration
+	fieldError
+	fieldBytes
+)
+
+// Field is a strongly typed keyval, as returned by Int, Str, Dur, and the
+// other constructors. It takes a single keyvals slot, in place of a key and a
+// value:
+//
+//	logger.Info("done", log.Int("n", 3), log.Dur("took", d), log.Err(err))
+//
+// The TextFormatter and JSONFormatter write fields without boxing their value
+// in an interface or going through fmt reflection.
+type Field struct {
+	Key  string
+	kind fieldKind
+	num  uint64
+	str  string
+	err  error
+
+	// key is Key boxed by the constructor, where it's usually a constant
+	// and boxing it is free, so that records don't box it again.
+	key interface{}
+}
+
+// Str returns a string Field.
+func Str(key, value string) Field {
+	return Field{Key: key, key: key, kind: fieldString, str: value}
+}
+
+// Int returns an int Field.
+func Int(key string, value int) Field {
+	return Int64(key, int64(value))
+}
+
+// Int64 returns an int64 Field.
+func Int64(key string, value int64) Field {
+	return Field{Key: key, key: key, kind: fieldInt, num: uint64(value)}
+}
+
+// Uint64 returns a uint64 Field.
+func Uint64(key string, value uint64) Field {
+	return Field{Key: key, key: key, kind: fieldUint, num: value}
+}
+
+// Float64 returns a float64 Field.
+func Float64(key string, value float64) Field {
+	return Field{Key: key, key: key, kind: fieldFloat, num: math.Float64bits(value)}
+}
+
+// Bool returns a bool Field.
+func Bool(key string, value bool) Field {
+	f := Field{Key: key, key: key, kind: fieldBool}
+	if value {
+		f.num = 1
+	}
+	return f
+}
+
+// Dur returns a time.Duration Field.
+func Dur(key string, value time.Duration) Field {
+	return Field{Key: key, key: key, kind: fieldDuration, num: uint64(value)}
+}
+
+// Bytes returns a byte size Field. The TextFormatter renders it with binary
+// units, like 1.5KiB or 20MiB, while the other formatters keep the number.
+func Bytes(key string, n int64) Field {
+	return Field{Key: key, key: key, kind: fieldBytes, num: uint64(n)}
+}
+
+// Err returns an error Field under ErrorKey. The error is logged as a
+// regular error value, so error chains, joined errors, and stack traces are
+// rendered as usual.
+func Err(err error) Field {
+	return Field{Key: ErrorKey, key: ErrorKey, kind: fieldError, err: err}
+}
+
+// Value returns the value of f, boxed in an interface.
+func (f Field) Value() interface{} {
+	switch f.kind {
+	case fieldInt, fieldBytes:
+		return int64(f.num)
+	case fieldUint:
+		return f.num
+	case fieldFloat:
+		return math.Float64frombits(f.num)
+	case fieldBool:
+		return f.num != 0
+	case fieldDuration:
+		return time.Duration(f.num)
+	case fieldError:
+		return f.err
+	default:
+		return f.str
+	}
+}
+
+// String returns the text representation of the value of f.
+func (f Field) String() string {
+	switch f.kind {
+	case fieldString:
+		return f.str
+	case fieldInt:
+		return strconv.FormatInt(int64(f.num), 10)
+	case fieldUint:
+		return strconv.FormatUint(f.num, 10)
+	case fieldBool:
+		return strconv.FormatBool(f.num != 0)
+	}
+	return string(f.appendText(nil))
+}
+
+// boxedKey returns the key of f, boxed in an interface.
+func (f Field) boxedKey() interface{} {
+	if k, ok := f.key.(string); ok && k == f.Key {
+		return f.key
+	}
+	return f.Key
+}
+
+// appendText appends the text representation of the value of f to b.
+func (f Field) appendText(b []byte) []byte {
+	switch f.kind {
+	case fieldInt:
+		return strconv.AppendInt(b, int64(f.num), 10)
+	case fieldUint:
+		return strconv.AppendUint(b, f.num, 10)
+	case fieldFloat:
+		return strconv.AppendFloat(b, math.Float64frombits(f.num), 'g', -1, 64)
+	case fieldBool:
+		return strconv.AppendBool(b, f.num != 0)
+	case fieldDuration:
+		return append(b, time.Duration(f.num).String()...)
+	case fieldBytes:
+		return appendByteSize(b, int64(f.num))
+	case fieldError:
+		if f.err == nil {
+			return append(b, "<nil>"...)
+		}
+		return append(b, f.err.Error()...)
+	default:
+		return append(b, f.str...)
+	}
+}
+
+// appendJSON appends the JSON encoding of the value of f to b, the same as
+// encoding/json would.
+func (f Field) appendJSON(b []byte) []byte {
+	switch f.kind {
+	case fieldString:
+		return appendJSONString(b, f.str)
+	case fieldFloat:
+		v := math.Float64frombits(f.num)
+		if math.IsNaN(v) || math.IsInf(v, 0) {
+			// Not representable in JSON.
+			return appendJSONString(b, strconv.FormatFloat(v, 'g', -1, 64))
+		}
+		return appendJSONFloat(b, v, 64)
+	case fieldDuration, fieldBytes:
+		// encoding/json encodes a time.Duration as its nanoseconds.
+		return strconv.AppendInt(b, int64(f.num), 10)
+	default:
+		return f.appendText(b)
+	}
+}
+
+// appendByteSize appends the byte size n to b with binary units.
+func appendByteSize(b []byte, n int64) []byte {
+	const units = "KMGTPE"
+	u := uint64(n)
+	if n < 0 {
+		b = append(b, '-')
+		u = -u
+	}
+	if u < 1024 {
+		b = strconv.AppendUint(b, u, 10)
+		return append(b, 'B')
+	}
+	v, i := float64(u)/1024, 0
+	for v >= 1024 && i < len(units)-1 {
+		v /= 1024
+		i++
+	}
+	s := strconv.FormatFloat(v, 'f', 1, 64)
+	if strings.HasSuffix(s, ".0") {
+		s = s[:len(s)-2]
+	}
+	return append(append(b, s...), units[i], 'i', 'B')
+}
+
+// appendJSONFloat appends v to b the way encoding/json formats floats of the
+// given bit size: without an exponent between 1e-6 and 1e21.
+func appendJSONFloat(b []byte, v float64, bits int) []byte {
+	format := byte('f')
+	if abs := math.Abs(v); abs != 0 {
+		if bits == 64 && (abs < 1e-6 || abs >= 1e21) ||
+			bits == 32 && (float32(abs) < 1e-6 || float32(abs) >= 1e21) {
+			format = 'e'
+		}
+	}
+	b = strconv.AppendFloat(b, v, format, -1, bits)
+	if format == 'e' {
+		// Clean up e-09 to e-9.
+		if n := len(b); n >= 4 && b[n-4] == 'e' && b[n-3] == '-' && b[n-2] == '0' {
+			b[n-2] = b[n-1]
+			b = b[:n-1]
+		}
+	}
+	return b
+}
+
+// appendJSONString appends s as a JSON string to b, without escaping HTML
+// characters, like the jsonWriter does.
+func appendJSONString(b []byte, s string) []byte {
+	const hex = "0123456789abcdef"
+	b = append(b, '"')
+	for i := 0; i < len(s); {
+		c := s[i]
+		if c < utf8.RuneSelf {
+			switch {
+			case c == '"' || c == '\\':
+				b = append(b, '\\', c)
+			case c == '\n':
+				b = append(b, '\\', 'n')
+			case c == '\r':
+				b = append(b, '\\', 'r')
+			case c == '\t':
+				b = append(b, '\\', 't')
+			case c == '\b':
+				b = append(b, '\\', 'b')
+			case c == '\f':
+				b = append(b, '\\', 'f')
+			case c < 0x20:
+				b = append(b, '\\', 'u', '0', '0', hex[c>>4], hex[c&0xf])
+			default:
+				b = append(b, c)
+			}
+			i++
+			continue
+		}
+		r, size := utf8.DecodeRuneInString(s[i:])
+		switch {
+		case r == utf8.RuneError && size == 1:
+			b = append(b, "\ufffd"...)
+		case r == '\u2028' || r == '\u2029':
+			b = append(b, '\\', 'u', '2', '0', '2', hex[r&0xf])
+		default:
+			b = append(b, s[i:i+size]...)
+		}
+		i += size
+	}
+	return append(b, '"')
+}
+
+// boxFields returns keyvals with their Field values replaced by their boxed
+// value, for the formatters without a fast path. It returns keyvals as is if
+// it has no fields.
+func boxFields(keyvals []interface{}) []interface{} {
+	var out []interface{}
+	for i := 1; i < len(keyvals); i += 2 {
+		f, ok := keyvals[i].(Field)
+		if !ok {
+			continue
+		}
+		if out == nil {
+			out = append(make([]interface{}, 0, len(keyvals)), keyvals...)
+		}
+		out[i] = f.Value()
+	}
+	if out == nil {
+		return keyvals
+	}
+	return out
+}
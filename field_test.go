@@ -0,0 +1,96 @@
+package plog
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFields(t *testing.T) {
+	fields := []interface{}{
+		Str("s", "a b"), Int("i", -3), Int64("i64", 4), Uint64("u", 5),
+		Float64("f", 1.5), Bool("b", true), Dur("d", 1500*time.Millisecond),
+		Err(errors.New("boom")),
+	}
+
+	t.Run("text", func(t *testing.T) {
+		var buf bytes.Buffer
+		New(&buf).Print("typed", fields...)
+		require.Equal(t, `typed s="a b" i=-3 i64=4 u=5 f=1.5 b=true d=1.5s error=boom`+"\n", buf.String())
+	})
+
+	t.Run("json", func(t *testing.T) {
+		var buf bytes.Buffer
+		NewWithOptions(&buf, Options{Formatter: JSONFormatter}).Print("typed", fields...)
+		require.Equal(t, `{"msg":"typed","s":"a b","i":-3,"i64":4,"u":5,"f":1.5,"b":true,`+
+			`"d":1500000000,"error":"boom"}`+"\n", buf.String())
+	})
+
+	t.Run("logfmt", func(t *testing.T) {
+		var buf bytes.Buffer
+		l := NewWithOptions(&buf, Options{Formatter: LogfmtFormatter})
+		l.With(Int("n", 1)).Print("typed", Str("s", "x"), "k", "v")
+		require.Equal(t, "msg=typed n=1 s=x k=v\n", buf.String())
+	})
+
+	t.Run("number format", func(t *testing.T) {
+		var buf bytes.Buffer
+		l := New(&buf).WithNumberFormat("n", NumberFormat{Thousands: true})
+		l.Print("typed", Int("n", 1234))
+		require.Equal(t, "typed n=1,234\n", buf.String())
+	})
+}
+
+func TestFieldJSON(t *testing.T) {
+	strs := []string{"plain", "quote\" back\\slash", "ctrl\x00\x1f\b\f\n\r\t", "<html>&",
+		"\u2028\u2029", "bad\xffutf8", "日本"}
+	for _, s := range strs {
+		var want bytes.Buffer
+		e := json.NewEncoder(&want)
+		e.SetEscapeHTML(false)
+		require.NoError(t, e.Encode(s))
+		require.Equal(t, want.String(), string(Str("k", s).appendJSON(nil))+"\n", "%q", s)
+	}
+	require.Equal(t, `"NaN"`, string(Float64("k", math.NaN()).appendJSON(nil)))
+	for _, f := range []float64{0, 1e6, 1e21, 1e-7, -2.5, 123456789.125} {
+		want, err := json.Marshal(f)
+		require.NoError(t, err)
+		require.Equal(t, string(want), string(Float64("k", f).appendJSON(nil)))
+	}
+}
+
+func TestBytesField(t *testing.T) {
+	cases := map[int64]string{
+		0:             "0B",
+		1023:          "1023B",
+		1536:          "1.5KiB",
+		20 << 20:      "20MiB",
+		3 << 30:       "3GiB",
+		-2048:         "-2KiB",
+		math.MaxInt64: "8EiB",
+		math.MinInt64: "-8EiB",
+	}
+	for n, want := range cases {
+		require.Equal(t, want, Bytes("size", n).String())
+	}
+
+	var buf bytes.Buffer
+	l := New(&buf)
+	l.Print("", Bytes("size", 1536))
+	require.Equal(t, "size=1.5KiB\n", buf.String())
+
+	buf.Reset()
+	l.SetFormatter(JSONFormatter)
+	l.Print("", Bytes("size", 1536))
+	require.Equal(t, `{"size":1536}`+"\n", buf.String())
+
+	buf.Reset()
+	l.SetFormatter(LogfmtFormatter)
+	l.Print("", Bytes("size", 1536))
+	require.Equal(t, "size=1536\n", buf.String())
+}
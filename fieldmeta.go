@@ -0,0 +1,33 @@
+package plog
+
+// FieldMeta describes a field. It's surfaced in the JSON output under MetaKey
+// and the unit is appended to values by the TextFormatter, after a space and
+// outside of any quotes, e.g. 12 MB.
+type FieldMeta struct {
+	// Unit is the unit of the field value, e.g. "ms" or "bytes".
+	Unit string `json:"unit,omitempty"`
+	// Description is a human readable description of the field.
+	Description string `json:"description,omitempty"`
+}
+
+// MetaKey is the key for field metadata in the JSON output.
+const MetaKey = "_meta"
+
+// WithFieldMeta returns a new logger that annotates the given key with meta.
+func (l *Logger) WithFieldMeta(key string, meta FieldMeta) *Logger {
+	sl := l.With()
+	m := make(map[string]FieldMeta, len(sl.meta)+1)
+	for k, v := range sl.meta {
+		m[k] = v
+	}
+	m[key] = meta
+	sl.meta = m
+	return sl
+}
+
+// WithUnit returns a new logger that annotates the given key with a unit.
+func (l *Logger) WithUnit(key, unit string) *Logger {
+	meta := l.meta[key]
+	meta.Unit = unit
+	return l.WithFieldMeta(key, meta)
+}
@@ -0,0 +1,51 @@
+package plog
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFieldMeta(t *testing.T) {
+	var buf bytes.Buffer
+	l := New(&buf).
+		WithUnit("latency", "ms").
+		WithFieldMeta("size", FieldMeta{Unit: "B", Description: "response size"})
+
+	t.Run("text", func(t *testing.T) {
+		buf.Reset()
+		l.SetFormatter(TextFormatter)
+		l.Print("done", "latency", 12, "size", 512, "path", "/")
+		require.Equal(t, "done latency=12 ms size=512 B path=/\n", buf.String())
+	})
+
+	t.Run("text with quoted value", func(t *testing.T) {
+		buf.Reset()
+		l.SetFormatter(TextFormatter)
+		l.Print("done", "latency", "about 12")
+		require.Equal(t, "done latency=\"about 12\" ms\n", buf.String())
+	})
+
+	t.Run("json", func(t *testing.T) {
+		buf.Reset()
+		l.SetFormatter(JSONFormatter)
+		l.Print("done", "latency", 12, "path", "/")
+		require.Equal(t, `{"msg":"done","latency":12,"path":"/","_meta":{"latency":{"unit":"ms"}}}`+"\n", buf.String())
+	})
+
+	t.Run("json without annotated keys", func(t *testing.T) {
+		buf.Reset()
+		l.SetFormatter(JSONFormatter)
+		l.Print("done", "path", "/")
+		require.Equal(t, `{"msg":"done","path":"/"}`+"\n", buf.String())
+	})
+
+	t.Run("parent is untouched", func(t *testing.T) {
+		var buf bytes.Buffer
+		parent := New(&buf)
+		_ = parent.WithUnit("latency", "ms")
+		parent.Print("done", "latency", 12)
+		require.Equal(t, "done latency=12\n", buf.String())
+	})
+}
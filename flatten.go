@@ -0,0 +1,123 @@
+package plog
+
+import (
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// SetFlattenStructs sets whether struct values are expanded into keyvals
+// prefixed with their key by the TextFormatter and LogfmtFormatter, e.g. a
+// user struct under "user" renders as user.id=1 user.name=bob instead of
+// {ID:1 Name:bob}.
+//
+// Fields are named after their `log` struct tag, then their `json` tag, then
+// their name. A "-" name skips the field, and the omitempty option skips it
+// when it's empty. Nested structs are expanded too. Structs implementing
+// error, fmt.Stringer, encoding.TextMarshaler, or json.Marshaler, like
+// time.Time, keep their own representation.
+func (l *Logger) SetFlattenStructs(flatten bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.flattenStructs = flatten
+}
+
+// flattenStructs returns keyvals with their struct values expanded.
+func flattenStructs(keyvals []interface{}) []interface{} {
+	var out []interface{}
+	for i := 0; i < len(keyvals); i += 2 {
+		rv, ok := flattenable(reflect.ValueOf(keyvals[i+1]))
+		if !ok {
+			if out != nil {
+				out = append(out, keyvals[i], keyvals[i+1])
+			}
+			continue
+		}
+		if out == nil {
+			out = append(make([]interface{}, 0, len(keyvals)+8), keyvals[:i]...)
+		}
+		out = appendFlattened(out, fmt.Sprint(keyvals[i]), rv)
+	}
+	if out == nil {
+		return keyvals
+	}
+	return out
+}
+
+// appendFlattened appends the fields of the struct rv to keyvals, prefixed
+// with prefix.
+func appendFlattened(keyvals []interface{}, prefix string, rv reflect.Value) []interface{} {
+	t := rv.Type()
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		name, omitEmpty := structFieldName(f)
+		if name == "-" {
+			continue
+		}
+		fv := rv.Field(i)
+		if omitEmpty && fv.IsZero() {
+			continue
+		}
+		key := prefix + "." + name
+		if nested, ok := flattenable(fv); ok {
+			keyvals = appendFlattened(keyvals, key, nested)
+			continue
+		}
+		keyvals = append(keyvals, key, fv.Interface())
+	}
+	return keyvals
+}
+
+// structFieldName returns the name of f from its log or json tag, and
+// whether it has the omitempty option.
+func structFieldName(f reflect.StructField) (string, bool) {
+	for _, tagKey := range []string{"log", "json"} {
+		tag, ok := f.Tag.Lookup(tagKey)
+		if !ok {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+		if name == "" {
+			name = f.Name
+		}
+		return name, strings.Contains(","+opts+",", ",omitempty,")
+	}
+	return f.Name, false
+}
+
+// flattenable returns the struct behind rv, dereferencing pointers, if it
+// doesn't have a representation of its own.
+func flattenable(rv reflect.Value) (reflect.Value, bool) {
+	for rv.Kind() == reflect.Pointer || rv.Kind() == reflect.Interface {
+		if rv.IsNil() {
+			return rv, false
+		}
+		if rv.Kind() == reflect.Pointer && hasRepresentation(rv) {
+			return rv, false
+		}
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct || hasRepresentation(rv) {
+		return rv, false
+	}
+	if rv.CanAddr() && hasRepresentation(rv.Addr()) {
+		return rv, false
+	}
+	return rv, true
+}
+
+func hasRepresentation(rv reflect.Value) bool {
+	if !rv.CanInterface() {
+		return true
+	}
+	switch rv.Interface().(type) {
+	case error, fmt.Stringer, encoding.TextMarshaler, json.Marshaler:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,51 @@
+package plog
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type flatAddress struct {
+	City string `json:"city"`
+	Zip  string `json:"zip,omitempty"`
+}
+
+type flatUser struct {
+	ID       int    `log:"id"`
+	Name     string `log:"name" json:"full_name"`
+	Password string `log:"-"`
+	Email    string `json:",omitempty"`
+	Address  *flatAddress
+	Since    time.Time `log:"since"`
+	secret   string
+}
+
+func TestFlattenStructs(t *testing.T) {
+	u := flatUser{
+		ID:       1,
+		Name:     "bob",
+		Password: "hunter2",
+		Address:  &flatAddress{City: "Paris"},
+		Since:    time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		secret:   "x",
+	}
+
+	var buf bytes.Buffer
+	l := NewWithOptions(&buf, Options{Formatter: LogfmtFormatter, FlattenStructs: true})
+	l.Print("login", "user", &u, "n", 1)
+	require.Equal(t, "msg=login user.id=1 user.name=bob user.Address.city=Paris "+
+		"user.since=2024-01-01T00:00:00Z n=1\n", buf.String())
+
+	buf.Reset()
+	l.SetFormatter(TextFormatter)
+	l.Print("login", "addr", flatAddress{City: "Paris", Zip: "75001"})
+	require.Equal(t, "login addr.city=Paris addr.zip=75001\n", buf.String())
+
+	buf.Reset()
+	l.SetFlattenStructs(false)
+	l.Print("login", "addr", flatAddress{City: "Paris"})
+	require.Equal(t, "login addr=\"{City:Paris Zip:}\"\n", buf.String())
+}
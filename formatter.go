@@ -1,5 +1,10 @@
 package plog
 
+import (
+	"strconv"
+	"strings"
+)
+
 // Formatter is a formatter for log messages.
 type Formatter uint8
 
@@ -11,6 +16,37 @@ const (
 	JSONFormatter
 	// LogfmtFormatter is a formatter that formats log messages as logfmt.
 	LogfmtFormatter
+	// GELFFormatter is a formatter that formats log messages as Graylog
+	// Extended Log Format (GELF) 1.1 messages.
+	GELFFormatter
+	// ECSFormatter is a formatter that formats log messages as Elastic Common
+	// Schema (ECS) JSON documents. Timestamps are always formatted as RFC 3339
+	// in UTC and fields other than errors are nested under "labels".
+	ECSFormatter
+	// GCPFormatter is a formatter that formats log messages as Google Cloud
+	// Logging structured JSON, as parsed from stdout on GKE and Cloud Run.
+	GCPFormatter
+	// LogstashFormatter is a formatter that formats log messages as Logstash
+	// JSON events. Fields are flattened at the top level, with maps expanded
+	// into dotted keys.
+	LogstashFormatter
+	// MsgPackFormatter is a formatter that formats log messages as MessagePack
+	// maps, a compact binary encoding for high volume pipelines. Records are
+	// self-delimiting and aren't followed by a newline. Timestamps use the
+	// MessagePack timestamp extension. Use the parse package to decode them.
+	MsgPackFormatter
+	// CSVFormatter is a formatter that formats log messages as CSV or TSV rows
+	// with fixed columns, see CSVOptions.
+	CSVFormatter
+	// CommonLogFormatter is a formatter that formats log messages as Apache
+	// Common Log Format access log lines, built from the well-known request
+	// keys: KeyRemoteAddr, KeyUserID, KeyMethod, KeyPath, KeyProto, KeyStatus,
+	// and KeyBytes. The request duration in microseconds is appended when
+	// KeyDurationMS is set. Other keys are ignored.
+	CommonLogFormatter
+	// CombinedLogFormatter is like CommonLogFormatter, with the KeyReferer and
+	// KeyUserAgent values added as in the Apache Combined Log Format.
+	CombinedLogFormatter
 )
 
 var (
@@ -24,4 +60,27 @@ var (
 	CallerKey = "caller"
 	// PrefixKey is the key for the prefix.
 	PrefixKey = "prefix"
+	// SchemaVersionKey is the key for the output schema version.
+	SchemaVersionKey = "schema_version"
+	// ErrorKey is the key for errors, see WithError.
+	ErrorKey = KeyError
 )
+
+// SchemaVersion is the version of the output schema: the built-in keys, their
+// meaning, and how values are encoded. It's bumped whenever the output of an
+// existing formatter changes in a way that may break parsers. See the "Schema
+// versions" section of the README for the changes between versions.
+const SchemaVersion = 3
+
+// splitCaller splits a formatted caller into its file and line parts. The line
+// is empty if the caller doesn't end with a line number.
+func splitCaller(caller string) (file string, line string) {
+	idx := strings.LastIndexByte(caller, ':')
+	if idx == -1 {
+		return caller, ""
+	}
+	if _, err := strconv.Atoi(caller[idx+1:]); err != nil {
+		return caller, ""
+	}
+	return caller[:idx], caller[idx+1:]
+}
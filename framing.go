@@ -0,0 +1,66 @@
+package plog
+
+import (
+	"bytes"
+	"encoding/binary"
+	"strconv"
+)
+
+// Framing is how records are delimited on the output, independently of the
+// formatter.
+type Framing uint8
+
+const (
+	// FramingNewline terminates records with a newline, as formatted. This is
+	// the default. MsgPackFormatter records are self-delimiting and written as
+	// is.
+	FramingNewline Framing = iota
+	// FramingNone writes records without any delimiter, e.g. for datagram
+	// outputs where each write is a record.
+	FramingNone
+	// FramingCRLF terminates records with a CRLF.
+	FramingCRLF
+	// FramingLengthPrefix prefixes records with their length as a 4 byte big
+	// endian integer.
+	FramingLengthPrefix
+	// FramingOctetCounting prefixes records with their length in decimal and a
+	// space, as in RFC 6587 syslog over TCP.
+	FramingOctetCounting
+)
+
+// SetFraming sets how records are delimited on the output. It doesn't apply
+// to the machine output, which is always newline delimited JSON.
+func (l *Logger) SetFraming(f Framing) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.framing = f
+}
+
+// frame applies the framing to the record formatted in the buffer from start.
+func (l *Logger) frame(b *bytes.Buffer, start int) {
+	if l.framing == FramingNewline {
+		return
+	}
+
+	record := b.Bytes()[start:]
+	if l.formatter != MsgPackFormatter && len(record) > 0 && record[len(record)-1] == '\n' {
+		record = record[:len(record)-1]
+	}
+	record = append([]byte(nil), record...)
+	b.Truncate(start)
+
+	switch l.framing {
+	case FramingCRLF:
+		b.Write(record)
+		b.WriteString("\r\n")
+	case FramingLengthPrefix:
+		b.Write(binary.BigEndian.AppendUint32(nil, uint32(len(record))))
+		b.Write(record)
+	case FramingOctetCounting:
+		b.WriteString(strconv.Itoa(len(record)))
+		b.WriteByte(' ')
+		b.Write(record)
+	default:
+		b.Write(record)
+	}
+}
@@ -0,0 +1,65 @@
+package plog
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/Malanris/plog/parse"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFraming(t *testing.T) {
+	cases := []struct {
+		name     string
+		framing  Framing
+		expected string
+	}{
+		{
+			name:     "newline",
+			framing:  FramingNewline,
+			expected: "level=info msg=one\nlevel=info msg=two\n",
+		},
+		{
+			name:     "none",
+			framing:  FramingNone,
+			expected: "level=info msg=onelevel=info msg=two",
+		},
+		{
+			name:     "crlf",
+			framing:  FramingCRLF,
+			expected: "level=info msg=one\r\nlevel=info msg=two\r\n",
+		},
+		{
+			name:     "length prefix",
+			framing:  FramingLengthPrefix,
+			expected: "\x00\x00\x00\x12level=info msg=one\x00\x00\x00\x12level=info msg=two",
+		},
+		{
+			name:     "octet counting",
+			framing:  FramingOctetCounting,
+			expected: "18 level=info msg=one18 level=info msg=two",
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			l := NewWithOptions(&buf, Options{Formatter: LogfmtFormatter, Framing: c.framing})
+			l.LogBatch([]Entry{{Level: InfoLevel, Message: "one"}, {Level: InfoLevel, Message: "two"}})
+			require.Equal(t, c.expected, buf.String())
+		})
+	}
+}
+
+func TestFramingMsgPack(t *testing.T) {
+	var buf bytes.Buffer
+	l := NewWithOptions(&buf, Options{Formatter: MsgPackFormatter, Framing: FramingLengthPrefix})
+	// The record ends with 0x0a, which must not be mistaken for a newline.
+	l.Print("ten", "n", 10)
+
+	b := buf.Bytes()
+	require.Equal(t, []byte{0, 0, 0, byte(len(b) - 4)}, b[:4])
+	require.Equal(t, byte(10), b[len(b)-1])
+	rec, err := parse.NewMsgPackDecoder(bytes.NewReader(b[4:])).Decode()
+	require.NoError(t, err)
+	require.Equal(t, int64(10), rec["n"])
+}
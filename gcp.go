@@ -0,0 +1,95 @@
+package plog
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+var (
+	// GCPTraceKey is the key whose value is reported as the Cloud Trace trace
+	// of a record by the GCPFormatter.
+	GCPTraceKey = "trace"
+	// GCPSpanKey is the key whose value is reported as the Cloud Trace span
+	// of a record by the GCPFormatter.
+	GCPSpanKey = "span_id"
+	// GCPProjectID is the Google Cloud project used to qualify trace IDs that
+	// aren't already in the "projects/PROJECT_ID/traces/TRACE_ID" form. The
+	// default is the GOOGLE_CLOUD_PROJECT environment variable.
+	GCPProjectID = os.Getenv("GOOGLE_CLOUD_PROJECT")
+)
+
+// gcpSeverity maps a Level to a Cloud Logging LogSeverity.
+func gcpSeverity(level Level) string {
+	switch {
+	case level == noLevel:
+		return "DEFAULT"
+	case level >= FatalLevel:
+		return "CRITICAL"
+	case level >= ErrorLevel:
+		return "ERROR"
+	case level >= WarnLevel:
+		return "WARNING"
+	case level >= InfoLevel:
+		return "INFO"
+	default:
+		return "DEBUG"
+	}
+}
+
+type gcpSourceLocation struct {
+	File string `json:"file"`
+	Line string `json:"line,omitempty"`
+}
+
+func (l *Logger) gcpFormatter(b *bytes.Buffer, keyvals ...interface{}) {
+	jw := &jsonWriter{w: b}
+	jw.start()
+
+	severity := gcpSeverity(noLevel)
+	for i := 0; i < len(keyvals); i += 2 {
+		if keyvals[i] == LevelKey {
+			if level, ok := keyvals[i+1].(Level); ok {
+				severity = gcpSeverity(level)
+			}
+		}
+	}
+	jw.objectItem("severity", severity)
+
+	for i := 0; i < len(keyvals); i += 2 {
+		switch keyvals[i] {
+		case LevelKey:
+		case TimestampKey:
+			if t, ok := keyvals[i+1].(time.Time); ok {
+				jw.objectItem("time", t.UTC().Format(time.RFC3339Nano))
+			}
+		case CallerKey:
+			if caller, ok := keyvals[i+1].(string); ok {
+				var loc gcpSourceLocation
+				loc.File, loc.Line = splitCaller(caller)
+				jw.objectItem("logging.googleapis.com/sourceLocation", loc)
+			}
+		case PrefixKey:
+			if prefix, ok := keyvals[i+1].(string); ok {
+				jw.objectItem("logging.googleapis.com/labels", map[string]string{"logger": prefix})
+			}
+		case MessageKey:
+			jw.objectItem("message", fmt.Sprint(keyvals[i+1]))
+		case GCPTraceKey:
+			trace := fmt.Sprint(keyvals[i+1])
+			if GCPProjectID != "" && !strings.HasPrefix(trace, "projects/") {
+				trace = "projects/" + GCPProjectID + "/traces/" + trace
+			}
+			jw.objectItem("logging.googleapis.com/trace", trace)
+		case GCPSpanKey:
+			jw.objectItem("logging.googleapis.com/spanId", fmt.Sprint(keyvals[i+1]))
+		default:
+			l.jsonFormatterItem(jw, keyvals[i], keyvals[i+1])
+		}
+	}
+
+	jw.end()
+	b.WriteRune('\n')
+}
@@ -0,0 +1,67 @@
+package plog
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGCP(t *testing.T) {
+	oldProject := GCPProjectID
+	defer func() { GCPProjectID = oldProject }()
+	GCPProjectID = "my-project"
+
+	var buf bytes.Buffer
+	l := NewWithOptions(&buf, Options{
+		Formatter:       GCPFormatter,
+		ReportTimestamp: true,
+		ReportCaller:    true,
+		CallerFormatter: func(string, int, string) string { return "pkg/file.go:42" },
+		TimeFunction: func(time.Time) time.Time {
+			return time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+		},
+		Prefix: "oven",
+	})
+
+	cases := []struct {
+		name     string
+		expected string
+		kvs      []interface{}
+		f        func(interface{}, ...interface{})
+	}{
+		{
+			name: "warn",
+			expected: `{"severity":"WARNING","time":"2024-01-01T12:00:00Z",` +
+				`"logging.googleapis.com/sourceLocation":{"file":"pkg/file.go","line":"42"},` +
+				`"logging.googleapis.com/labels":{"logger":"oven"},"message":"hot","temp":500}` + "\n",
+			kvs: []interface{}{"temp", 500},
+			f:   l.Warn,
+		},
+		{
+			name: "trace",
+			expected: `{"severity":"CRITICAL","time":"2024-01-01T12:00:00Z",` +
+				`"logging.googleapis.com/sourceLocation":{"file":"pkg/file.go","line":"42"},` +
+				`"logging.googleapis.com/labels":{"logger":"oven"},"message":"hot",` +
+				`"logging.googleapis.com/trace":"projects/my-project/traces/abc",` +
+				`"logging.googleapis.com/spanId":"def"}` + "\n",
+			kvs: []interface{}{"trace", "abc", "span_id", "def"},
+			f:   func(msg interface{}, kvs ...interface{}) { l.Log(FatalLevel, msg, kvs...) },
+		},
+		{
+			name: "no level",
+			expected: `{"severity":"DEFAULT","time":"2024-01-01T12:00:00Z",` +
+				`"logging.googleapis.com/sourceLocation":{"file":"pkg/file.go","line":"42"},` +
+				`"logging.googleapis.com/labels":{"logger":"oven"},"message":"hot"}` + "\n",
+			f: l.Print,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			buf.Reset()
+			c.f("hot", c.kvs...)
+			require.Equal(t, c.expected, buf.String())
+		})
+	}
+}
@@ -0,0 +1,253 @@
+package plog
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"sync"
+	"time"
+)
+
+const gelfVersion = "1.1"
+
+var (
+	gelfHost     string
+	gelfHostOnce sync.Once
+)
+
+// gelfLevel maps a Level to its syslog severity as used by GELF.
+func gelfLevel(level Level) int {
+	switch {
+	case level >= FatalLevel:
+		return 2 // critical
+	case level >= ErrorLevel:
+		return 3 // error
+	case level >= WarnLevel:
+		return 4 // warning
+	case level >= InfoLevel:
+		return 6 // informational
+	default:
+		return 7 // debug
+	}
+}
+
+// gelfKey returns the additional field name for the given key. GELF requires
+// additional field names to be prefixed with an underscore and only contain
+// letters, numbers, underscores, dashes and dots. The "_id" field is reserved.
+func gelfKey(key string) string {
+	b := make([]byte, 0, len(key)+1)
+	b = append(b, '_')
+	for i := 0; i < len(key); i++ {
+		c := key[i]
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9',
+			c == '_', c == '-', c == '.':
+			b = append(b, c)
+		default:
+			b = append(b, '_')
+		}
+	}
+	if string(b) == "_id" {
+		return "__id"
+	}
+	return string(b)
+}
+
+func (l *Logger) gelfFormatter(b *bytes.Buffer, keyvals ...interface{}) {
+	gelfHostOnce.Do(func() {
+		gelfHost, _ = os.Hostname()
+		if gelfHost == "" {
+			gelfHost = "localhost"
+		}
+	})
+
+	jw := &jsonWriter{w: b}
+	jw.start()
+	jw.objectItem("version", gelfVersion)
+	jw.objectItem("host", gelfHost)
+
+	var msg string
+	level := noLevel
+	for i := 0; i < len(keyvals); i += 2 {
+		switch keyvals[i] {
+		case MessageKey:
+			msg = fmt.Sprint(keyvals[i+1])
+		case LevelKey:
+			if lvl, ok := keyvals[i+1].(Level); ok {
+				level = lvl
+			}
+		}
+	}
+	jw.objectItem("short_message", msg)
+	if level != noLevel {
+		jw.objectItem("level", gelfLevel(level))
+	}
+
+	for i := 0; i < len(keyvals); i += 2 {
+		switch keyvals[i] {
+		case MessageKey, LevelKey:
+		case TimestampKey:
+			if t, ok := keyvals[i+1].(time.Time); ok {
+				jw.objectItem("timestamp", float64(t.UnixNano())/float64(time.Second))
+			}
+		default:
+			key := gelfKey(fmt.Sprint(keyvals[i]))
+			switch v := keyvals[i+1].(type) {
+			case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64,
+				float32, float64, string:
+				jw.objectItem(key, v)
+			case error:
+				jw.objectItem(key, v.Error())
+			default:
+				jw.objectItem(key, fmt.Sprintf("%+v", v))
+			}
+		}
+	}
+
+	jw.end()
+	b.WriteRune('\n')
+}
+
+// GELFCompression is the compression applied to GELF UDP payloads.
+type GELFCompression uint8
+
+const (
+	// GELFCompressNone sends payloads uncompressed.
+	GELFCompressNone GELFCompression = iota
+	// GELFCompressGzip compresses payloads with gzip.
+	GELFCompressGzip
+	// GELFCompressZlib compresses payloads with zlib.
+	GELFCompressZlib
+)
+
+const (
+	// DefaultGELFChunkSize is the default maximum size of a GELF UDP datagram.
+	// It is suitable for most WAN links.
+	DefaultGELFChunkSize = 1420
+
+	gelfChunkHeaderLen = 12
+	gelfMaxChunks      = 128
+)
+
+// ErrGELFMessageTooLarge is returned when a message doesn't fit in the maximum
+// number of GELF chunks.
+var ErrGELFMessageTooLarge = errors.New("gelf: message too large")
+
+// GELFOptions are the options for a GELFWriter.
+type GELFOptions struct {
+	// ChunkSize is the maximum size of a single datagram, including the chunk
+	// header. The default is DefaultGELFChunkSize.
+	ChunkSize int
+	// Compression is the compression applied to each message. The default is
+	// GELFCompressNone.
+	Compression GELFCompression
+}
+
+// GELFWriter is an io.Writer that sends each write as a GELF message over UDP.
+// Messages larger than the chunk size are split into GELF chunks.
+//
+// Use it as the output of a logger using the GELFFormatter:
+//
+//	w, _ := log.NewGELFWriter("graylog:12201", log.GELFOptions{})
+//	logger := log.NewWithOptions(w, log.Options{Formatter: log.GELFFormatter})
+type GELFWriter struct {
+	mu   sync.Mutex
+	conn net.Conn
+	opts GELFOptions
+	buf  bytes.Buffer
+}
+
+// NewGELFWriter returns a new GELFWriter sending to the given UDP address.
+func NewGELFWriter(addr string, o GELFOptions) (*GELFWriter, error) {
+	conn, err := net.Dial("udp", addr)
+	if err != nil {
+		return nil, err
+	}
+	if o.ChunkSize <= gelfChunkHeaderLen {
+		o.ChunkSize = DefaultGELFChunkSize
+	}
+	return &GELFWriter{conn: conn, opts: o}, nil
+}
+
+// Write sends p as a single GELF message.
+func (w *GELFWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	msg := bytes.TrimRight(p, "\n")
+	w.buf.Reset()
+	if err := w.compress(msg); err != nil {
+		return 0, err
+	}
+
+	if w.buf.Len() <= w.opts.ChunkSize {
+		if _, err := w.conn.Write(w.buf.Bytes()); err != nil {
+			return 0, err
+		}
+		return len(p), nil
+	}
+
+	if err := w.writeChunks(w.buf.Bytes()); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+func (w *GELFWriter) messageOriented() bool { return true }
+
+func (w *GELFWriter) compress(p []byte) error {
+	var zw io.WriteCloser
+	switch w.opts.Compression {
+	case GELFCompressGzip:
+		zw = gzip.NewWriter(&w.buf)
+	case GELFCompressZlib:
+		zw = zlib.NewWriter(&w.buf)
+	default:
+		w.buf.Write(p)
+		return nil
+	}
+	if _, err := zw.Write(p); err != nil {
+		return err
+	}
+	return zw.Close()
+}
+
+func (w *GELFWriter) writeChunks(p []byte) error {
+	size := w.opts.ChunkSize - gelfChunkHeaderLen
+	count := (len(p) + size - 1) / size
+	if count > gelfMaxChunks {
+		return ErrGELFMessageTooLarge
+	}
+
+	var id [8]byte
+	if _, err := rand.Read(id[:]); err != nil {
+		return err
+	}
+
+	chunk := make([]byte, 0, w.opts.ChunkSize)
+	for i := 0; i < count; i++ {
+		end := (i + 1) * size
+		if end > len(p) {
+			end = len(p)
+		}
+		chunk = append(chunk[:0], 0x1e, 0x0f)
+		chunk = append(chunk, id[:]...)
+		chunk = append(chunk, byte(i), byte(count))
+		chunk = append(chunk, p[i*size:end]...)
+		if _, err := w.conn.Write(chunk); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Close closes the underlying connection.
+func (w *GELFWriter) Close() error {
+	return w.conn.Close()
+}
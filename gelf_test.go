@@ -0,0 +1,117 @@
+package plog
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"io"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGELF(t *testing.T) {
+	var buf bytes.Buffer
+	l := New(&buf)
+	l.SetFormatter(GELFFormatter)
+	l.SetReportTimestamp(true)
+	l.SetTimeFunction(func(time.Time) time.Time { return time.Unix(1700000000, 500000000) })
+	l.SetPrefix("oven")
+	l.Error("burnt", "temp", 500, "id", "abc", "bad key", errors.New("too hot"))
+
+	var m map[string]interface{}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &m))
+	require.Equal(t, "1.1", m["version"])
+	require.NotEmpty(t, m["host"])
+	require.Equal(t, "burnt", m["short_message"])
+	require.Equal(t, float64(3), m["level"])
+	require.Equal(t, 1700000000.5, m["timestamp"])
+	require.Equal(t, "oven", m["_prefix"])
+	require.Equal(t, float64(500), m["_temp"])
+	require.Equal(t, "abc", m["__id"])
+	require.Equal(t, "too hot", m["_bad_key"])
+}
+
+func TestGELFLevel(t *testing.T) {
+	cases := map[Level]int{
+		DebugLevel: 7,
+		InfoLevel:  6,
+		WarnLevel:  4,
+		ErrorLevel: 3,
+		FatalLevel: 2,
+	}
+	for level, expected := range cases {
+		require.Equal(t, expected, gelfLevel(level), level.String())
+	}
+}
+
+func TestGELFWriter(t *testing.T) {
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer pc.Close() //nolint: errcheck
+
+	read := func() []byte {
+		b := make([]byte, 65536)
+		require.NoError(t, pc.SetReadDeadline(time.Now().Add(time.Second)))
+		n, _, err := pc.ReadFrom(b)
+		require.NoError(t, err)
+		return b[:n]
+	}
+
+	t.Run("single datagram", func(t *testing.T) {
+		w, err := NewGELFWriter(pc.LocalAddr().String(), GELFOptions{})
+		require.NoError(t, err)
+		defer w.Close() //nolint: errcheck
+
+		_, err = w.Write([]byte("{\"short_message\":\"hi\"}\n"))
+		require.NoError(t, err)
+		require.Equal(t, "{\"short_message\":\"hi\"}", string(read()))
+	})
+
+	t.Run("chunked", func(t *testing.T) {
+		w, err := NewGELFWriter(pc.LocalAddr().String(), GELFOptions{ChunkSize: 32})
+		require.NoError(t, err)
+		defer w.Close() //nolint: errcheck
+
+		msg := strings.Repeat("x", 50)
+		_, err = w.Write([]byte(msg))
+		require.NoError(t, err)
+
+		var got []byte
+		for i := 0; i < 3; i++ {
+			chunk := read()
+			require.Equal(t, []byte{0x1e, 0x0f}, chunk[:2])
+			require.Equal(t, byte(i), chunk[10])
+			require.Equal(t, byte(3), chunk[11])
+			got = append(got, chunk[12:]...)
+		}
+		require.Equal(t, msg, string(got))
+	})
+
+	t.Run("too many chunks", func(t *testing.T) {
+		w, err := NewGELFWriter(pc.LocalAddr().String(), GELFOptions{ChunkSize: 13})
+		require.NoError(t, err)
+		defer w.Close() //nolint: errcheck
+
+		_, err = w.Write(bytes.Repeat([]byte("x"), gelfMaxChunks+1))
+		require.ErrorIs(t, err, ErrGELFMessageTooLarge)
+	})
+
+	t.Run("gzip", func(t *testing.T) {
+		w, err := NewGELFWriter(pc.LocalAddr().String(), GELFOptions{Compression: GELFCompressGzip})
+		require.NoError(t, err)
+		defer w.Close() //nolint: errcheck
+
+		_, err = w.Write([]byte("{\"short_message\":\"hi\"}\n"))
+		require.NoError(t, err)
+		zr, err := gzip.NewReader(bytes.NewReader(read()))
+		require.NoError(t, err)
+		b, err := io.ReadAll(zr)
+		require.NoError(t, err)
+		require.Equal(t, "{\"short_message\":\"hi\"}", string(b))
+	})
+}
@@ -0,0 +1,33 @@
+package plog
+
+import (
+	"bytes"
+	"runtime"
+	"strconv"
+)
+
+// KeyGoroutineID is the key of the ID of the goroutine logging a record, as
+// added by WithGoroutineID.
+const KeyGoroutineID = "goroutine_id"
+
+// WithGoroutineID returns a new logger adding the ID of the calling goroutine
+// to every record, after the logger fields, to tell apart the records of
+// concurrent goroutines.
+func (l *Logger) WithGoroutineID() *Logger {
+	sl := l.With()
+	sl.goroutineID = true
+	return sl
+}
+
+// goroutineID returns the ID of the calling goroutine, parsed from the header
+// of its stack trace, "goroutine 42 [running]:".
+func goroutineID() uint64 {
+	var buf [64]byte
+	b := buf[:runtime.Stack(buf[:], false)]
+	b = bytes.TrimPrefix(b, []byte("goroutine "))
+	if i := bytes.IndexByte(b, ' '); i != -1 {
+		b = b[:i]
+	}
+	id, _ := strconv.ParseUint(string(b), 10, 64)
+	return id
+}
@@ -0,0 +1,31 @@
+package plog
+
+import (
+	"bytes"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithGoroutineID(t *testing.T) {
+	var buf bytes.Buffer
+	l := NewWithOptions(&buf, Options{Formatter: LogfmtFormatter}).With("a", 1).WithGoroutineID()
+	l.Print("hi", "b", 2)
+	require.Equal(t, fmt.Sprintf("msg=hi a=1 goroutine_id=%d b=2\n", goroutineID()), buf.String())
+
+	var wg sync.WaitGroup
+	ids := make(chan uint64, 2)
+	for i := 0; i < 2; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ids <- goroutineID()
+		}()
+	}
+	wg.Wait()
+	a, b := <-ids, <-ids
+	require.NotZero(t, a)
+	require.NotEqual(t, a, b)
+}
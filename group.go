@@ -0,0 +1,102 @@
+package plog
+
+import "fmt"
+
+// Grouped is a group of keyvals under a key, as returned by Group.
+type Grouped struct {
+	Key     string
+	Keyvals []interface{}
+}
+
+// groupValue is the value of a group, once normalized into a key and value
+// pair by normalizeKeyvals.
+type groupValue []interface{}
+
+// Group returns a group of keyvals namespaced under key. It takes a single
+// keyvals slot, in place of a key and a value:
+//
+//	logger.Info("request", log.Group("req", "method", m, "path", p), "status", 200)
+//
+// The JSONFormatter renders it as a nested object, and the other formatters
+// as dotted keys, e.g. req.method=GET req.path=/. Groups can be nested.
+func Group(key string, keyvals ...interface{}) Grouped {
+	return Grouped{Key: key, Keyvals: keyvals}
+}
+
+// normalizeKeyvals returns keyvals with their single slot Grouped and Field
+// keys turned into key and value pairs. It returns keyvals as is if it has
+// none.
+func normalizeKeyvals(keyvals []interface{}) []interface{} {
+	for i := 0; i < len(keyvals); i += 2 {
+		switch keyvals[i].(type) {
+		case Field, Grouped:
+			return appendNormalized(make([]interface{}, 0, len(keyvals)+1), keyvals)
+		}
+	}
+	return keyvals
+}
+
+// appendNormalized appends keyvals to dst, with their single slot Grouped and
+// Field keys turned into key and value pairs.
+func appendNormalized(dst, keyvals []interface{}) []interface{} {
+	for i := 0; i < len(keyvals); i++ {
+		switch kv := keyvals[i].(type) {
+		case Field:
+			if kv.kind == fieldError {
+				dst = append(dst, kv.boxedKey(), kv.err)
+			} else {
+				// Reuse the boxed field rather than boxing it again.
+				dst = append(dst, kv.boxedKey(), keyvals[i])
+			}
+		case Grouped:
+			kvs := appendNormalized(make([]interface{}, 0, len(kv.Keyvals)+1), kv.Keyvals)
+			if len(kvs)%2 != 0 {
+				kvs = append(kvs, ErrMissingValue)
+			}
+			dst = append(dst, kv.Key, groupValue(kvs))
+		default:
+			dst = append(dst, kv)
+			// Skip the value, so a Grouped value isn't taken for a key.
+			if i+1 < len(keyvals) {
+				i++
+				dst = append(dst, keyvals[i])
+			}
+		}
+	}
+	return dst
+}
+
+// expandGroups returns keyvals with their groups expanded into dotted keys.
+// It returns keyvals as is if it has no groups.
+func expandGroups(keyvals []interface{}) []interface{} {
+	var out []interface{}
+	for i := 0; i+1 < len(keyvals); i += 2 {
+		g, ok := keyvals[i+1].(groupValue)
+		if !ok {
+			if out != nil {
+				out = append(out, keyvals[i], keyvals[i+1])
+			}
+			continue
+		}
+		if out == nil {
+			out = append(make([]interface{}, 0, len(keyvals)+len(g)), keyvals[:i]...)
+		}
+		out = appendGroup(out, fmt.Sprint(keyvals[i]), g)
+	}
+	if out == nil {
+		return keyvals
+	}
+	return out
+}
+
+func appendGroup(keyvals []interface{}, prefix string, g groupValue) []interface{} {
+	for i := 0; i+1 < len(g); i += 2 {
+		key := prefix + "." + fmt.Sprint(g[i])
+		if nested, ok := g[i+1].(groupValue); ok {
+			keyvals = appendGroup(keyvals, key, nested)
+			continue
+		}
+		keyvals = append(keyvals, key, g[i+1])
+	}
+	return keyvals
+}
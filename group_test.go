@@ -0,0 +1,35 @@
+package plog
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGroup(t *testing.T) {
+	req := Group("req", "method", "GET", "path", "/", Group("client", "ip", "::1"))
+
+	t.Run("json", func(t *testing.T) {
+		var buf bytes.Buffer
+		l := NewWithOptions(&buf, Options{Formatter: JSONFormatter})
+		l.With(Group("app", "name", "bakery")).Print("request", req, "status", 200, Group("empty"), "odd")
+		require.Equal(t, `{"msg":"request","app":{"name":"bakery"},`+
+			`"req":{"method":"GET","path":"/","client":{"ip":"::1"}},`+
+			`"status":200,"empty":{},"odd":"missing value"}`+"\n", buf.String())
+	})
+
+	t.Run("text", func(t *testing.T) {
+		var buf bytes.Buffer
+		l := New(&buf)
+		l.Print("request", req, "status", 200)
+		require.Equal(t, "request req.method=GET req.path=/ req.client.ip=::1 status=200\n", buf.String())
+	})
+
+	t.Run("logfmt", func(t *testing.T) {
+		var buf bytes.Buffer
+		l := NewWithOptions(&buf, Options{Formatter: LogfmtFormatter})
+		l.Print("request", req)
+		require.Equal(t, "msg=request req.method=GET req.path=/ req.client.ip=::1\n", buf.String())
+	})
+}
@@ -0,0 +1,42 @@
+package plog
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"hash/fnv"
+	"time"
+)
+
+// HashKey is the key for the record content hash.
+var HashKey = "hash"
+
+// recordHash returns a stable hash of the entry content, with its time
+// truncated to the given bucket. Two identical records logged within the same
+// bucket get the same hash, which lets at-least-once pipelines deduplicate
+// them on ingestion.
+func recordHash(e *Entry, bucket time.Duration) string {
+	h := fnv.New64a()
+	var b [8]byte
+	binary.BigEndian.PutUint64(b[:], uint64(e.Time.Truncate(bucket).UnixNano()))
+	h.Write(b[:]) //nolint: errcheck
+	binary.BigEndian.PutUint32(b[:4], uint32(e.Level))
+	h.Write(b[:4]) //nolint: errcheck
+	for _, s := range []string{e.Caller, e.Prefix, e.Message} {
+		fmt.Fprintf(h, "%d:%s", len(s), s)
+	}
+	for _, kv := range e.Keyvals {
+		s := fmt.Sprintf("%+v", kv)
+		fmt.Fprintf(h, "%d:%s", len(s), s)
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// SetRecordHash enables stamping every record with a content hash under
+// HashKey. The record time is truncated to bucket before hashing. A zero
+// bucket disables hashing.
+func (l *Logger) SetRecordHash(bucket time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.hashBucket = bucket
+}
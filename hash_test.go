@@ -0,0 +1,46 @@
+package plog
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecordHash(t *testing.T) {
+	var buf bytes.Buffer
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	l := NewWithOptions(&buf, Options{
+		Formatter:        JSONFormatter,
+		RecordHashBucket: time.Minute,
+		TimeFunction:     func(time.Time) time.Time { return now },
+	})
+
+	hash := func(msg string, kvs ...interface{}) string {
+		buf.Reset()
+		l.Info(msg, kvs...)
+		var m map[string]interface{}
+		require.NoError(t, json.Unmarshal(buf.Bytes(), &m))
+		require.Len(t, m[HashKey], 16)
+		return m[HashKey].(string)
+	}
+
+	h1 := hash("baking", "temp", 180)
+	require.Equal(t, h1, hash("baking", "temp", 180), "same record, same hash")
+
+	now = now.Add(30 * time.Second)
+	require.Equal(t, h1, hash("baking", "temp", 180), "same bucket, same hash")
+	require.NotEqual(t, h1, hash("baking", "temp", 200), "different value")
+	require.NotEqual(t, h1, hash("baking!", "temp", 180), "different message")
+	require.NotEqual(t, h1, hash("baking", "temp1", 80), "keys and values are delimited")
+
+	now = now.Add(time.Minute)
+	require.NotEqual(t, h1, hash("baking", "temp", 180), "different bucket")
+
+	l.SetRecordHash(0)
+	buf.Reset()
+	l.Info("baking")
+	require.NotContains(t, buf.String(), HashKey)
+}
@@ -0,0 +1,188 @@
+// Package httplog provides a net/http middleware logging every request.
+package httplog
+
+import (
+	"bufio"
+	"errors"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/Malanris/plog"
+)
+
+// DefaultRequestIDHeader is the default header carrying the request ID.
+const DefaultRequestIDHeader = "X-Request-ID"
+
+// maxRequestIDLen is the maximum length of a request ID taken from a request.
+const maxRequestIDLen = 128
+
+// Options are the options of the middleware.
+type Options struct {
+	// Logger is the logger requests are logged to. The default is
+	// plog.Default().
+	Logger *plog.Logger
+	// Message is the message of request records. The default is "request".
+	Message string
+	// Level returns the level of a request record from the response status.
+	// The default is LevelByStatus.
+	Level func(status int) plog.Level
+	// RequestIDHeader is the header carrying the request ID. An ID found in
+	// the request is reused if it's at most 128 letters, digits, and "-_.:"
+	// characters, otherwise a new one is generated. The ID is also set on
+	// the response. The default is DefaultRequestIDHeader.
+	RequestIDHeader string
+	// IDGenerator generates request IDs. The default is
+	// plog.DefaultIDGenerator.
+	IDGenerator plog.IDGenerator
+}
+
+// LevelByStatus returns plog.ErrorLevel for 5xx statuses, plog.WarnLevel for
+// 4xx statuses, and plog.InfoLevel otherwise.
+func LevelByStatus(status int) plog.Level {
+	switch {
+	case status >= 500:
+		return plog.ErrorLevel
+	case status >= 400:
+		return plog.WarnLevel
+	default:
+		return plog.InfoLevel
+	}
+}
+
+// Middleware returns a middleware logging every request handled by the next
+// handler, with its method, path, status, response size, duration, remote
+// address, and request ID:
+//
+//	http.ListenAndServe(":8080", httplog.Middleware(httplog.Options{})(mux))
+//
+// Handlers can log with the request logger, which carries the request ID,
+// using plog.FromContext(r.Context()). Requests whose handler panics are
+// logged at the error level, with the panic value, before the panic goes on.
+// http.ErrAbortHandler panics, used to abort responses, aren't logged.
+func Middleware(o Options) func(http.Handler) http.Handler {
+	if o.Message == "" {
+		o.Message = "request"
+	}
+	if o.Level == nil {
+		o.Level = LevelByStatus
+	}
+	if o.RequestIDHeader == "" {
+		o.RequestIDHeader = DefaultRequestIDHeader
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+
+			id := r.Header.Get(o.RequestIDHeader)
+			if !validRequestID(id) {
+				gen := o.IDGenerator
+				if gen == nil {
+					gen = plog.DefaultIDGenerator
+				}
+				id = gen.NewID()
+			}
+			w.Header().Set(o.RequestIDHeader, id)
+
+			logger := o.Logger
+			if logger == nil {
+				logger = plog.Default()
+			}
+			logger = logger.WithRequestID(id)
+
+			rw := &responseWriter{ResponseWriter: w, status: http.StatusOK}
+			logRequest := func(level plog.Level, status int, keyvals ...interface{}) {
+				logger.Log(level, o.Message, append([]interface{}{
+					plog.KeyMethod, r.Method,
+					plog.KeyPath, r.URL.Path,
+					plog.KeyProto, r.Proto,
+					plog.KeyStatus, status,
+					plog.KeyBytes, rw.bytes,
+					plog.KeyDurationMS, plog.DurationMS(time.Since(start)),
+					plog.KeyRemoteAddr, r.RemoteAddr,
+				}, keyvals...)...)
+			}
+			defer func() {
+				if v := recover(); v != nil {
+					if v == http.ErrAbortHandler { //nolint: errorlint
+						panic(v)
+					}
+					// Log the request before the panic goes on, e.g. to
+					// Recoverer or net/http.
+					status := rw.status
+					if !rw.wroteHeader {
+						status = http.StatusInternalServerError
+					}
+					logRequest(plog.ErrorLevel, status, plog.KeyPanic, v)
+					panic(v)
+				}
+			}()
+			next.ServeHTTP(rw, r.WithContext(plog.WithContext(r.Context(), logger)))
+
+			logRequest(o.Level(rw.status), rw.status)
+		})
+	}
+}
+
+// validRequestID reports whether id, taken from a request, is safe to log and
+// echo back.
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLen {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		c := id[i]
+		switch {
+		case 'a' <= c && c <= 'z', 'A' <= c && c <= 'Z', '0' <= c && c <= '9':
+		case c == '-', c == '_', c == '.', c == ':':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// responseWriter records the status and size of a response.
+type responseWriter struct {
+	http.ResponseWriter
+	status      int
+	bytes       int64
+	wroteHeader bool
+}
+
+func (w *responseWriter) WriteHeader(status int) {
+	if !w.wroteHeader {
+		w.status = status
+		w.wroteHeader = true
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *responseWriter) Write(p []byte) (int, error) {
+	w.wroteHeader = true
+	n, err := w.ResponseWriter.Write(p)
+	w.bytes += int64(n)
+	return n, err
+}
+
+// Flush implements http.Flusher.
+func (w *responseWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		w.wroteHeader = true
+		f.Flush()
+	}
+}
+
+// Hijack implements http.Hijacker.
+func (w *responseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	if h, ok := w.ResponseWriter.(http.Hijacker); ok {
+		return h.Hijack()
+	}
+	return nil, nil, errors.New("httplog: response writer doesn't support hijacking")
+}
+
+// Unwrap returns the wrapped http.ResponseWriter, for http.ResponseController.
+func (w *responseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
@@ -0,0 +1,111 @@
+package httplog
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Malanris/plog"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMiddleware(t *testing.T) {
+	var buf bytes.Buffer
+	logger := plog.NewWithOptions(&buf, plog.Options{Formatter: plog.JSONFormatter})
+	h := Middleware(Options{
+		Logger:      logger,
+		IDGenerator: plog.IDGeneratorFunc(func() string { return "gen-id" }),
+	})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		plog.FromContext(r.Context()).Info("handling")
+		if r.URL.Path == "/missing" {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = w.Write([]byte("hello"))
+	}))
+
+	cases := []struct {
+		name   string
+		path   string
+		header string
+		id     string
+		level  string
+		status float64
+		bytes  float64
+	}{
+		{name: "ok", path: "/ok", id: "gen-id", level: "info", status: 200, bytes: 5},
+		{name: "not found", path: "/missing", header: "given-id", id: "given-id", level: "warn", status: 404, bytes: 19},
+		{name: "invalid id", path: "/ok", header: `"forged":1`, id: "gen-id", level: "info", status: 200, bytes: 5},
+		{name: "long id", path: "/ok", header: strings.Repeat("a", 129), id: "gen-id", level: "info", status: 200, bytes: 5},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			buf.Reset()
+			req := httptest.NewRequest(http.MethodGet, c.path, nil)
+			if c.header != "" {
+				req.Header.Set(DefaultRequestIDHeader, c.header)
+			}
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, req)
+			require.Equal(t, c.id, rec.Header().Get(DefaultRequestIDHeader))
+
+			lines := bytes.Split(bytes.TrimSpace(buf.Bytes()), []byte("\n"))
+			require.Len(t, lines, 2)
+			require.Contains(t, string(lines[0]), `"msg":"handling","request_id":"`+c.id+`"`)
+
+			var m map[string]interface{}
+			require.NoError(t, json.Unmarshal(lines[1], &m))
+			require.Equal(t, c.level, m["level"])
+			require.Equal(t, "request", m["msg"])
+			require.Equal(t, c.id, m[plog.KeyRequestID])
+			require.Equal(t, "GET", m[plog.KeyMethod])
+			require.Equal(t, c.path, m[plog.KeyPath])
+			require.Equal(t, c.status, m[plog.KeyStatus])
+			require.Equal(t, c.bytes, m[plog.KeyBytes])
+			require.Equal(t, "192.0.2.1:1234", m[plog.KeyRemoteAddr])
+			require.Contains(t, m, plog.KeyDurationMS)
+		})
+	}
+}
+
+func TestMiddlewarePanic(t *testing.T) {
+	var buf bytes.Buffer
+	logger := plog.NewWithOptions(&buf, plog.Options{Formatter: plog.JSONFormatter})
+	h := Middleware(Options{Logger: logger})(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {
+		panic("boom")
+	}))
+
+	require.PanicsWithValue(t, "boom", func() {
+		h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	})
+
+	var m map[string]interface{}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &m))
+	require.Equal(t, "error", m["level"])
+	require.Equal(t, "request", m["msg"])
+	require.Equal(t, float64(500), m[plog.KeyStatus])
+	require.Equal(t, "boom", m[plog.KeyPanic])
+}
+
+func TestMiddlewareAbortHandler(t *testing.T) {
+	var buf bytes.Buffer
+	logger := plog.NewWithOptions(&buf, plog.Options{Formatter: plog.JSONFormatter})
+	h := Middleware(Options{Logger: logger})(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {
+		panic(http.ErrAbortHandler)
+	}))
+
+	require.PanicsWithValue(t, http.ErrAbortHandler, func() {
+		h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	})
+	require.Empty(t, buf.String())
+}
+
+func TestLevelByStatus(t *testing.T) {
+	require.Equal(t, plog.InfoLevel, LevelByStatus(204))
+	require.Equal(t, plog.InfoLevel, LevelByStatus(302))
+	require.Equal(t, plog.WarnLevel, LevelByStatus(429))
+	require.Equal(t, plog.ErrorLevel, LevelByStatus(503))
+}
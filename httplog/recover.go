@@ -0,0 +1,65 @@
+package httplog
+
+import (
+	"net/http"
+
+	"github.com/Malanris/plog"
+)
+
+// RecoverOptions are the options of the Recoverer middleware.
+type RecoverOptions struct {
+	// Logger is the logger panics are logged to. The default is the request
+	// logger, see plog.FromContext.
+	Logger *plog.Logger
+	// Repanic panics again once the panic is logged, e.g. to let an outer
+	// middleware or the server handle it. By default the panic is recovered
+	// and a 500 response is sent if nothing was written yet.
+	Repanic bool
+}
+
+// Recoverer returns a middleware recovering panics of the next handler and
+// logging them at plog.ErrorLevel with their stack trace. Put it inside
+// Middleware so panics are logged with the request ID:
+//
+//	h := httplog.Middleware(httplog.Options{})(httplog.Recoverer(httplog.RecoverOptions{})(mux))
+//
+// http.ErrAbortHandler panics, used to abort responses, aren't logged.
+func Recoverer(o RecoverOptions) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			rw, ok := w.(*responseWriter)
+			if !ok {
+				rw = &responseWriter{ResponseWriter: w, status: http.StatusOK}
+			}
+			defer func() {
+				v := recover()
+				if v == nil {
+					return
+				}
+				if v == http.ErrAbortHandler { //nolint: errorlint
+					panic(v)
+				}
+
+				logger := o.Logger
+				if logger == nil {
+					logger = plog.FromContext(r.Context())
+				}
+				_, stack := plog.PanicStack()
+				logger.Error("panic",
+					plog.KeyPanic, v,
+					plog.KeyStack, stack,
+					plog.KeyMethod, r.Method,
+					plog.KeyPath, r.URL.Path,
+				)
+
+				if o.Repanic {
+					panic(v)
+				}
+				if !rw.wroteHeader {
+					http.Error(rw, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+				}
+			}()
+			next.ServeHTTP(rw, r)
+		})
+	}
+}
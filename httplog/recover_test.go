@@ -0,0 +1,43 @@
+package httplog
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Malanris/plog"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecoverer(t *testing.T) {
+	var buf bytes.Buffer
+	logger := plog.NewWithOptions(&buf, plog.Options{Formatter: plog.JSONFormatter})
+	panicky := http.HandlerFunc(func(http.ResponseWriter, *http.Request) {
+		panic("oops")
+	})
+
+	h := Middleware(Options{Logger: logger})(Recoverer(RecoverOptions{})(panicky))
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/boom", nil))
+	require.Equal(t, http.StatusInternalServerError, rec.Code)
+
+	lines := bytes.Split(bytes.TrimSpace(buf.Bytes()), []byte("\n"))
+	require.Len(t, lines, 2)
+	var m map[string]interface{}
+	require.NoError(t, json.Unmarshal(lines[0], &m))
+	require.Equal(t, "error", m["level"])
+	require.Equal(t, "oops", m[plog.KeyPanic])
+	require.NotEmpty(t, m[plog.KeyRequestID])
+	require.True(t, strings.HasPrefix(m[plog.KeyStack].(string), "github.com/Malanris/plog/httplog.TestRecoverer.func1\n"))
+	require.Contains(t, string(lines[1]), `"status":500`)
+
+	buf.Reset()
+	h = Recoverer(RecoverOptions{Logger: logger, Repanic: true})(panicky)
+	require.PanicsWithValue(t, "oops", func() {
+		h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/boom", nil))
+	})
+	require.Contains(t, buf.String(), `"panic":"oops"`)
+}
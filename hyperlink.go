@@ -0,0 +1,66 @@
+package plog
+
+import (
+	"net/url"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// CallerLink returns the URL of a caller location, rendered as an OSC 8
+// hyperlink by the TextFormatter so terminals can open the source line. See
+// SetHyperlinks.
+type CallerLink func(file string, line int) string
+
+// FileCallerLink is a caller link returning the file:// URL of the caller
+// file.
+func FileCallerLink(file string, _ int) string {
+	u := url.URL{Scheme: "file", Path: filepath.ToSlash(file)}
+	if !strings.HasPrefix(u.Path, "/") {
+		u.Path = "/" + u.Path
+	}
+	return u.String()
+}
+
+// VSCodeCallerLink is a caller link returning the vscode:// URL of the
+// caller line, opening it in Visual Studio Code.
+func VSCodeCallerLink(file string, line int) string {
+	return "vscode://file/" + strings.TrimPrefix(filepath.ToSlash(file), "/") + ":" + strconv.Itoa(line)
+}
+
+// SetCallerLink sets the function returning the URL of the callers, which the
+// TextFormatter renders as OSC 8 hyperlinks when enabled with SetHyperlinks.
+// A nil link disables the hyperlinks.
+func (l *Logger) SetCallerLink(link CallerLink) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.callerLink = link
+}
+
+// SetHyperlinks sets whether the TextFormatter renders the caller links as
+// OSC 8 hyperlinks. Not all terminals supporting colors support hyperlinks,
+// so they're only rendered when enabled, and never on outputs without colors,
+// such as files and pipes.
+func (l *Logger) SetHyperlinks(enabled bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.hyperlinks = enabled
+}
+
+// callerLink is a formatted caller along with its URL.
+type callerLink struct {
+	caller, url string
+}
+
+// unlinkCaller returns keyvals with the caller link replaced by the formatted
+// caller, for the formatters not rendering links.
+func unlinkCaller(keyvals []interface{}) []interface{} {
+	for i := 0; i+1 < len(keyvals); i += 2 {
+		if c, ok := keyvals[i+1].(callerLink); ok && keyvals[i] == CallerKey {
+			kvs := append([]interface{}(nil), keyvals...)
+			kvs[i+1] = c.caller
+			return kvs
+		}
+	}
+	return keyvals
+}
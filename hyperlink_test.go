@@ -0,0 +1,50 @@
+package plog
+
+import (
+	"bytes"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/x/ansi"
+	"github.com/muesli/termenv"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCallerLink(t *testing.T) {
+	require.Equal(t, "file:///src/main.go", FileCallerLink("/src/main.go", 3))
+	require.Equal(t, "file:///C:/src/main.go", FileCallerLink("C:/src/main.go", 3))
+	require.Equal(t, "vscode://file/src/main.go:3", VSCodeCallerLink("/src/main.go", 3))
+
+	var buf, machine bytes.Buffer
+	l := NewWithOptions(&buf, Options{
+		ReportCaller:  true,
+		CallerLink:    VSCodeCallerLink,
+		MachineOutput: &machine,
+	})
+	l.Print("plain")
+	require.NotContains(t, buf.String(), "\x1b]8;")
+	_, file, _, _ := runtime.Caller(0)
+	require.Contains(t, machine.String(), `"caller":"`+trimCallerPath(file, 2)+":")
+
+	// Color support doesn't imply hyperlink support.
+	buf.Reset()
+	l.SetColorProfile(termenv.ANSI)
+	l.Print("colored")
+	require.NotContains(t, buf.String(), "\x1b]8;")
+
+	// Plain outputs never get escape sequences.
+	buf.Reset()
+	l.SetColorProfile(termenv.Ascii)
+	l.SetHyperlinks(true)
+	l.Print("plain")
+	require.NotContains(t, buf.String(), "\x1b]8;")
+
+	buf.Reset()
+	l.SetColorProfile(termenv.ANSI)
+	l.Print("linked")
+	out := buf.String()
+	require.True(t, strings.HasPrefix(out, "\x1b]8;;vscode://file/"), out)
+	require.Contains(t, out, "hyperlink_test.go:")
+	require.Contains(t, out, ansi.ResetHyperlink())
+}
@@ -0,0 +1,164 @@
+package plog
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"math/big"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// IDGenerator generates unique identifiers, e.g. request or trace IDs.
+type IDGenerator interface {
+	NewID() string
+}
+
+// IDGeneratorFunc is a function that implements IDGenerator.
+type IDGeneratorFunc func() string
+
+// NewID calls f().
+func (f IDGeneratorFunc) NewID() string {
+	return f()
+}
+
+// DefaultIDGenerator is the IDGenerator used when none is configured. It
+// generates UUIDv7s.
+var DefaultIDGenerator IDGenerator = IDGeneratorFunc(NewUUIDv7)
+
+// idNow is the clock used by the ID generators.
+var idNow = time.Now
+
+// NewUUIDv7 returns a new time-ordered RFC 9562 version 7 UUID.
+func NewUUIDv7() string {
+	var b [16]byte
+	_, _ = rand.Read(b[6:])
+	ms := uint64(idNow().UnixMilli())
+	for i := 5; i >= 0; i-- {
+		b[i] = byte(ms)
+		ms >>= 8
+	}
+	b[6] = b[6]&0x0f | 0x70 // version 7
+	b[8] = b[8]&0x3f | 0x80 // variant 10
+
+	var s [36]byte
+	hex.Encode(s[0:8], b[0:4])
+	s[8] = '-'
+	hex.Encode(s[9:13], b[4:6])
+	s[13] = '-'
+	hex.Encode(s[14:18], b[6:8])
+	s[18] = '-'
+	hex.Encode(s[19:23], b[8:10])
+	s[23] = '-'
+	hex.Encode(s[24:], b[10:])
+	return string(s[:])
+}
+
+const crockford = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+// NewULID returns a new lexicographically sortable ULID.
+func NewULID() string {
+	var b [16]byte
+	_, _ = rand.Read(b[6:])
+	ms := uint64(idNow().UnixMilli())
+	for i := 5; i >= 0; i-- {
+		b[i] = byte(ms)
+		ms >>= 8
+	}
+
+	// 128 bits encoded as 26 base32 characters, i.e. 130 bits: the 48-bit
+	// timestamp takes the first 10 characters, 50 bits, whose top 2 bits
+	// are always zero.
+	hi := uint64(b[0])<<56 | uint64(b[1])<<48 | uint64(b[2])<<40 | uint64(b[3])<<32 |
+		uint64(b[4])<<24 | uint64(b[5])<<16 | uint64(b[6])<<8 | uint64(b[7])
+	lo := uint64(b[8])<<56 | uint64(b[9])<<48 | uint64(b[10])<<40 | uint64(b[11])<<32 |
+		uint64(b[12])<<24 | uint64(b[13])<<16 | uint64(b[14])<<8 | uint64(b[15])
+	var s [26]byte
+	for i := 25; i >= 0; i-- {
+		s[i] = crockford[lo&0x1f]
+		lo = lo>>5 | hi<<59
+		hi >>= 5
+	}
+	return string(s[:])
+}
+
+const (
+	ksuidEpoch = 1400000000
+	ksuidLen   = 27
+	base62     = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"
+)
+
+// NewKSUID returns a new K-Sortable Unique IDentifier.
+func NewKSUID() string {
+	var b [20]byte
+	ts := uint32(idNow().Unix() - ksuidEpoch)
+	b[0], b[1], b[2], b[3] = byte(ts>>24), byte(ts>>16), byte(ts>>8), byte(ts)
+	_, _ = rand.Read(b[4:])
+
+	n := new(big.Int).SetBytes(b[:])
+	s := []byte(n.Text(62))
+	for i, c := range s {
+		// big.Int uses 0-9a-zA-Z, KSUIDs use 0-9A-Za-z.
+		switch {
+		case c >= 'a' && c <= 'z':
+			s[i] = base62[c-'a'+10]
+		case c >= 'A' && c <= 'Z':
+			s[i] = base62[c-'A'+36]
+		}
+	}
+	for len(s) < ksuidLen {
+		s = append([]byte{'0'}, s...)
+	}
+	return string(s)
+}
+
+const (
+	// SnowflakeEpoch is the epoch of Snowflake IDs in Unix milliseconds.
+	SnowflakeEpoch = 1288834974657
+
+	snowflakeNodeBits = 10
+	snowflakeSeqBits  = 12
+	snowflakeMaxNode  = 1<<snowflakeNodeBits - 1
+	snowflakeMaxSeq   = 1<<snowflakeSeqBits - 1
+)
+
+// Snowflake is an IDGenerator of Twitter Snowflake IDs: 41 bits of
+// milliseconds since SnowflakeEpoch, 10 bits of node ID, and a 12 bits
+// sequence number.
+type Snowflake struct {
+	mu   sync.Mutex
+	node int64
+	last int64
+	seq  int64
+}
+
+// NewSnowflake returns a new Snowflake generator for the given node ID. Only
+// the 10 least significant bits of node are used.
+func NewSnowflake(node int64) *Snowflake {
+	return &Snowflake{node: node & snowflakeMaxNode}
+}
+
+// NewID returns a new Snowflake ID.
+func (s *Snowflake) NewID() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := idNow().UnixMilli() - SnowflakeEpoch
+	if now < s.last {
+		// The clock went backwards, stick to the last timestamp.
+		now = s.last
+	}
+	if now == s.last {
+		s.seq = (s.seq + 1) & snowflakeMaxSeq
+		if s.seq == 0 {
+			// Sequence exhausted, borrow the next millisecond.
+			now++
+		}
+	} else {
+		s.seq = 0
+	}
+	s.last = now
+
+	id := now<<(snowflakeNodeBits+snowflakeSeqBits) | s.node<<snowflakeSeqBits | s.seq
+	return strconv.FormatInt(id, 10)
+}
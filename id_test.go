@@ -0,0 +1,91 @@
+package plog
+
+import (
+	"regexp"
+	"sort"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func withIDClock(t *testing.T, now *time.Time) {
+	t.Helper()
+	old := idNow
+	idNow = func() time.Time { return *now }
+	t.Cleanup(func() { idNow = old })
+}
+
+func TestIDGenerators(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	withIDClock(t, &now)
+
+	cases := []struct {
+		name    string
+		gen     IDGenerator
+		pattern string
+	}{
+		{
+			name:    "uuidv7",
+			gen:     IDGeneratorFunc(NewUUIDv7),
+			pattern: `^[0-9a-f]{8}-[0-9a-f]{4}-7[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`,
+		},
+		{
+			name:    "ulid",
+			gen:     IDGeneratorFunc(NewULID),
+			pattern: `^[0-7][0-9A-HJKMNP-TV-Z]{25}$`,
+		},
+		{
+			name:    "ksuid",
+			gen:     IDGeneratorFunc(NewKSUID),
+			pattern: `^[0-9A-Za-z]{27}$`,
+		},
+		{
+			name:    "snowflake",
+			gen:     NewSnowflake(1),
+			pattern: `^[0-9]+$`,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			re := regexp.MustCompile(c.pattern)
+			seen := map[string]struct{}{}
+			var ids []string
+			for i := 0; i < 100; i++ {
+				now = now.Add(time.Second)
+				id := c.gen.NewID()
+				require.Regexp(t, re, id)
+				require.NotContains(t, seen, id)
+				seen[id] = struct{}{}
+				ids = append(ids, id)
+			}
+			if c.name != "snowflake" {
+				require.True(t, sort.StringsAreSorted(ids), "ids are time-ordered")
+			}
+		})
+	}
+}
+
+func TestULIDTimestamp(t *testing.T) {
+	now := time.UnixMilli(1469918176385)
+	withIDClock(t, &now)
+	// Timestamp from the ULID spec.
+	require.Equal(t, "01ARYZ6S41", NewULID()[:10])
+}
+
+func TestSnowflake(t *testing.T) {
+	now := time.UnixMilli(SnowflakeEpoch + 1)
+	withIDClock(t, &now)
+
+	s := NewSnowflake(5)
+	id1, _ := strconv.ParseInt(s.NewID(), 10, 64)
+	id2, _ := strconv.ParseInt(s.NewID(), 10, 64)
+	require.Equal(t, int64(1<<22|5<<12), id1)
+	require.Equal(t, id1+1, id2)
+
+	// Clock going backwards keeps IDs increasing.
+	now = now.Add(-time.Second)
+	id3, _ := strconv.ParseInt(s.NewID(), 10, 64)
+	require.Greater(t, id3, id2)
+}
@@ -2,76 +2,335 @@ package plog
 
 import (
 	"bytes"
+	"encoding"
 	"encoding/json"
 	"fmt"
+	"math"
+	"reflect"
+	"strconv"
 	"time"
 )
 
-func (l *Logger) jsonFormatter(keyvals ...interface{}) {
-	jw := &jsonWriter{w: &l.b}
+// JSONPrefixMode is how the JSONFormatter renders the logger prefix.
+type JSONPrefixMode uint8
+
+const (
+	// JSONPrefixField renders the prefix as the PrefixKey field. This is the
+	// default.
+	JSONPrefixField JSONPrefixMode = iota
+	// JSONPrefixLogger renders the prefix as a "logger" field.
+	JSONPrefixLogger
+	// JSONPrefixMessage merges the prefix into the message, as in
+	// "prefix: message", like the TextFormatter does.
+	JSONPrefixMessage
+	// JSONPrefixDrop omits the prefix.
+	JSONPrefixDrop
+)
+
+// SetJSONPrefixMode sets how the JSONFormatter renders the logger prefix.
+func (l *Logger) SetJSONPrefixMode(mode JSONPrefixMode) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.jsonPrefix = mode
+}
+
+// SetJSONIndent sets the indentation of the records of the JSONFormatter,
+// e.g. "  ", writing each record as an indented block for local development.
+// The machine output stays on a single line. An empty indent disables the
+// indentation.
+func (l *Logger) SetJSONIndent(indent string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.jsonIndent = indent
+}
+
+// indentJSON indents the JSON record written to the buffer from start.
+func (l *Logger) indentJSON(b *bytes.Buffer, start int) {
+	var out bytes.Buffer
+	record := bytes.TrimSuffix(b.Bytes()[start:], []byte{'\n'})
+	if err := json.Indent(&out, record, "", l.jsonIndent); err != nil {
+		return
+	}
+	b.Truncate(start)
+	out.WriteTo(b) //nolint: errcheck
+	b.WriteByte('\n')
+}
+
+// JSONFieldNames are the names of the built-in fields of the JSONFormatter,
+// e.g. "@timestamp", "severity" or "message" as mandated by some downstream
+// systems. Empty names keep the default keys.
+type JSONFieldNames struct {
+	// Time is the name of the TimestampKey field.
+	Time string
+	// Level is the name of the LevelKey field.
+	Level string
+	// Message is the name of the MessageKey field.
+	Message string
+	// Caller is the name of the CallerKey field.
+	Caller string
+	// Prefix is the name of the PrefixKey field.
+	Prefix string
+}
+
+// name returns the name of the built-in key.
+func (n JSONFieldNames) name(key string) string {
+	var name string
+	switch key {
+	case TimestampKey:
+		name = n.Time
+	case LevelKey:
+		name = n.Level
+	case MessageKey:
+		name = n.Message
+	case CallerKey:
+		name = n.Caller
+	case PrefixKey:
+		name = n.Prefix
+	}
+	if name == "" {
+		return key
+	}
+	return name
+}
+
+// SetJSONFieldNames sets the names of the built-in fields of the
+// JSONFormatter.
+func (l *Logger) SetJSONFieldNames(names JSONFieldNames) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.jsonNames = names
+}
+
+// JSONTimeMode is how the JSONFormatter renders the timestamps.
+type JSONTimeMode uint8
+
+const (
+	// JSONTimeFormatted renders the timestamps as strings in the logger time
+	// format. This is the default.
+	JSONTimeFormatted JSONTimeMode = iota
+	// JSONTimeUnix renders the timestamps as Unix seconds.
+	JSONTimeUnix
+	// JSONTimeUnixMilli renders the timestamps as Unix milliseconds.
+	JSONTimeUnixMilli
+	// JSONTimeUnixNano renders the timestamps as Unix nanoseconds.
+	JSONTimeUnixNano
+)
+
+// SetJSONTimeMode sets how the JSONFormatter renders the timestamps.
+func (l *Logger) SetJSONTimeMode(mode JSONTimeMode) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.jsonTime = mode
+}
+
+// jsonTimestamp returns the JSON value of the timestamp t.
+func (l *Logger) jsonTimestamp(t time.Time) any {
+	switch l.jsonTime {
+	case JSONTimeUnix:
+		return t.Unix()
+	case JSONTimeUnixMilli:
+		return t.UnixMilli()
+	case JSONTimeUnixNano:
+		return t.UnixNano()
+	default:
+		return l.formatTime(JSONFormatter, t)
+	}
+}
+
+// jsonMergePrefix returns keyvals with the prefix merged into the message.
+func jsonMergePrefix(keyvals []interface{}) []interface{} {
+	prefix, msg := -1, -1
+	for i := 0; i+1 < len(keyvals); i += 2 {
+		switch keyvals[i] {
+		case PrefixKey:
+			prefix = i
+		case MessageKey:
+			msg = i
+		}
+	}
+	if prefix == -1 {
+		return keyvals
+	}
+
+	p := fmt.Sprint(keyvals[prefix+1])
+	kvs := make([]interface{}, 0, len(keyvals))
+	kvs = append(kvs, keyvals[:prefix]...)
+	if msg == -1 {
+		kvs = append(kvs, MessageKey, p+":")
+	}
+	for i := prefix + 2; i < len(keyvals); i++ {
+		if i == msg+1 {
+			kvs = append(kvs, p+": "+fmt.Sprint(keyvals[i]))
+			continue
+		}
+		kvs = append(kvs, keyvals[i])
+	}
+	return kvs
+}
+
+func (l *Logger) jsonFormatter(b *bytes.Buffer, keyvals ...interface{}) {
+	if l.jsonPrefix == JSONPrefixMessage {
+		keyvals = jsonMergePrefix(keyvals)
+	}
+	// The field meta and EMF refer to the keys as logged.
+	items := keyvals
+	if l.jsonNested {
+		items = nestKeys(keyvals)
+	}
+
+	jw := &jsonWriter{w: b}
 	jw.start()
 
 	i := 0
-	for i < len(keyvals) {
-		switch kv := keyvals[i].(type) {
+	for i < len(items) {
+		switch kv := items[i].(type) {
 		case slogAttr:
 			l.jsonFormatterRoot(jw, kv.Key, kv.Value)
 			i++
 		default:
-			if i+1 < len(keyvals) {
-				l.jsonFormatterRoot(jw, keyvals[i], keyvals[i+1])
+			if i+1 < len(items) {
+				l.jsonFormatterRoot(jw, items[i], items[i+1])
 			}
 			i += 2
 		}
 	}
 
+	l.jsonFieldMeta(jw, keyvals)
+	l.jsonEMF(jw, keyvals)
 	jw.end()
-	l.b.WriteRune('\n')
+	b.WriteRune('\n')
+}
+
+// jsonFieldMeta writes the metadata of the fields present in keyvals.
+func (l *Logger) jsonFieldMeta(jw *jsonWriter, keyvals []interface{}) {
+	if len(l.meta) == 0 {
+		return
+	}
+	var meta map[string]FieldMeta
+	for i := 0; i < len(keyvals); i += 2 {
+		key, ok := keyvals[i].(string)
+		if !ok {
+			continue
+		}
+		if m, ok := l.meta[key]; ok {
+			if meta == nil {
+				meta = map[string]FieldMeta{}
+			}
+			meta[key] = m
+		}
+	}
+	if meta != nil {
+		jw.objectItem(MetaKey, meta)
+	}
 }
 
 func (l *Logger) jsonFormatterRoot(jw *jsonWriter, key, value any) {
 	switch key {
 	case TimestampKey:
 		if t, ok := value.(time.Time); ok {
-			jw.objectItem(TimestampKey, t.Format(l.timeFormat))
+			jw.objectItem(l.jsonNames.name(TimestampKey), l.jsonTimestamp(t))
 		}
 	case LevelKey:
 		if level, ok := value.(Level); ok {
-			jw.objectItem(LevelKey, level.String())
+			jw.objectString(l.jsonNames.name(LevelKey), level.String())
 		}
 	case CallerKey:
 		if caller, ok := value.(string); ok {
-			jw.objectItem(CallerKey, caller)
+			jw.objectString(l.jsonNames.name(CallerKey), caller)
 		}
 	case PrefixKey:
-		if prefix, ok := value.(string); ok {
-			jw.objectItem(PrefixKey, prefix)
+		prefix, ok := value.(string)
+		if !ok {
+			return
+		}
+		switch l.jsonPrefix {
+		case JSONPrefixField:
+			jw.objectString(l.jsonNames.name(PrefixKey), prefix)
+		case JSONPrefixLogger:
+			jw.objectString("logger", prefix)
 		}
 	case MessageKey:
-		if msg := value; msg != nil {
-			jw.objectItem(MessageKey, fmt.Sprint(msg))
+		switch msg := value.(type) {
+		case nil:
+		case string:
+			jw.objectString(l.jsonNames.name(MessageKey), msg)
+		default:
+			jw.objectString(l.jsonNames.name(MessageKey), fmt.Sprint(msg))
 		}
 	default:
 		l.jsonFormatterItem(jw, key, value)
 	}
 }
 
-func (l *Logger) jsonFormatterItem(jw *jsonWriter, key, value any) {
+// jsonKey returns the JSON object key of key. Keys whose String or Error
+// method panics are rendered as "invalid key".
+func jsonKey(key any) (s string) {
+	defer func() {
+		if r := recover(); r != nil {
+			s = "invalid key"
+		}
+	}()
 	switch k := key.(type) {
+	case string:
+		return k
 	case fmt.Stringer:
-		jw.objectKey(k.String())
+		return k.String()
 	case error:
-		jw.objectKey(k.Error())
+		return k.Error()
 	default:
-		jw.objectKey(fmt.Sprint(k))
+		return fmt.Sprint(k)
+	}
+}
+
+func (l *Logger) jsonFormatterItem(jw *jsonWriter, key, value any) {
+	jw.objectKey(jsonKey(key))
+	// A panicking String, Error, or MarshalJSON method must not leave a
+	// partial value behind, so that each record stays valid JSON.
+	pos, d := jw.w.Len(), jw.d
+	defer func() {
+		if r := recover(); r != nil {
+			jw.w.Truncate(pos)
+			jw.w.WriteString(`"invalid value"`)
+			jw.d = d
+		}
+	}()
+	if d, ok := duration(value); ok && l.durationFormat != nil {
+		jw.objectValue(l.jsonDuration(d))
+		return
+	}
+	if s, ok := l.formatBinary(value); ok {
+		jw.objectValue(s)
+		return
 	}
 	switch v := value.(type) {
 	case error:
+		if errs := joinedErrors(v); len(errs) > 0 {
+			jw.objectValue(l.jsonJoinedError(errs))
+			return
+		}
+		if causes := errorCauses(v); l.errorChains && len(causes) > 0 {
+			jw.objectValue(jsonError{Error: v.Error(), Causes: causes})
+			return
+		}
 		jw.objectValue(v.Error())
+	case float32, float64:
+		jw.objectValue(jsonFloat(v))
+	case Field:
+		jw.w.Write(v.appendJSON(jw.w.AvailableBuffer())) //nolint: errcheck
+	case groupValue:
+		d := jw.d
+		jw.start()
+		for i := 0; i+1 < len(v); i += 2 {
+			l.jsonFormatterItem(jw, v[i], v[i+1])
+		}
+		jw.end()
+		jw.d = d
 	case slogLogValuer:
 		l.writeSlogValue(jw, v.LogValue())
 	case slogValue:
 		l.writeSlogValue(jw, v.Resolve())
+	case json.Marshaler, encoding.TextMarshaler:
+		jw.objectValue(v)
 	case fmt.Stringer:
 		jw.objectValue(v.String())
 	default:
@@ -79,14 +338,25 @@ func (l *Logger) jsonFormatterItem(jw *jsonWriter, key, value any) {
 	}
 }
 
+// jsonFloat returns v, or its string form if it is NaN or infinite, which are
+// not representable in JSON.
+func jsonFloat(v any) any {
+	if f := reflect.ValueOf(v).Float(); math.IsNaN(f) || math.IsInf(f, 0) {
+		return strconv.FormatFloat(f, 'g', -1, 64)
+	}
+	return v
+}
+
 func (l *Logger) writeSlogValue(jw *jsonWriter, v slogValue) {
 	switch v.Kind() {
 	case slogKindGroup:
+		d := jw.d
 		jw.start()
 		for _, attr := range v.Group() {
 			l.jsonFormatterItem(jw, attr.Key, attr.Value)
 		}
 		jw.end()
+		jw.d = d
 	default:
 		jw.objectValue(v.Any())
 	}
@@ -111,22 +381,28 @@ func (w *jsonWriter) objectItem(key string, value any) {
 	w.objectValue(value)
 }
 
+// objectString writes the string value without boxing it, unlike
+// objectItem.
+func (w *jsonWriter) objectString(key, value string) {
+	w.objectKey(key)
+	w.w.Write(appendJSONString(w.w.AvailableBuffer(), value)) //nolint: errcheck
+}
+
 func (w *jsonWriter) objectKey(key string) {
 	if w.d > 0 {
 		w.w.WriteRune(',')
 	}
 	w.d++
 
-	pos := w.w.Len()
-	err := w.writeEncoded(key)
-	if err != nil {
-		w.w.Truncate(pos)
-		w.w.WriteString(`"invalid key"`)
-	}
-	w.w.WriteRune(':')
+	b := appendJSONString(w.w.AvailableBuffer(), key)
+	w.w.Write(append(b, ':')) //nolint: errcheck
 }
 
 func (w *jsonWriter) objectValue(value any) {
+	if b, ok := appendJSONValue(w.w.AvailableBuffer(), value); ok {
+		w.w.Write(b) //nolint: errcheck
+		return
+	}
 	pos := w.w.Len()
 	err := w.writeEncoded(value)
 	if err != nil {
@@ -135,6 +411,63 @@ func (w *jsonWriter) objectValue(value any) {
 	}
 }
 
+// appendJSONValue appends the JSON encoding of the common value v to b, the
+// same as encoding/json would but without reflection. It returns false for
+// the other values, which are left to encoding/json.
+func appendJSONValue(b []byte, v any) ([]byte, bool) {
+	switch v := v.(type) {
+	case nil:
+		return append(b, "null"...), true
+	case string:
+		return appendJSONString(b, v), true
+	case bool:
+		return strconv.AppendBool(b, v), true
+	case int:
+		return strconv.AppendInt(b, int64(v), 10), true
+	case int8:
+		return strconv.AppendInt(b, int64(v), 10), true
+	case int16:
+		return strconv.AppendInt(b, int64(v), 10), true
+	case int32:
+		return strconv.AppendInt(b, int64(v), 10), true
+	case int64:
+		return strconv.AppendInt(b, v, 10), true
+	case uint:
+		return strconv.AppendUint(b, uint64(v), 10), true
+	case uint8:
+		return strconv.AppendUint(b, uint64(v), 10), true
+	case uint16:
+		return strconv.AppendUint(b, uint64(v), 10), true
+	case uint32:
+		return strconv.AppendUint(b, uint64(v), 10), true
+	case uint64:
+		return strconv.AppendUint(b, v, 10), true
+	case float32:
+		if math.IsNaN(float64(v)) || math.IsInf(float64(v), 0) {
+			return b, false
+		}
+		return appendJSONFloat(b, float64(v), 32), true
+	case float64:
+		if math.IsNaN(v) || math.IsInf(v, 0) {
+			return b, false
+		}
+		return appendJSONFloat(b, v, 64), true
+	case time.Duration:
+		return strconv.AppendInt(b, int64(v), 10), true
+	case time.Time:
+		// time.Time.MarshalJSON rejects the years and zone offsets that
+		// RFC 3339 cannot represent.
+		_, offset := v.Zone()
+		if y := v.Year(); y < 0 || y > 9999 || offset%60 != 0 || offset <= -24*60*60 || offset >= 24*60*60 {
+			return b, false
+		}
+		b = append(b, '"')
+		b = v.AppendFormat(b, time.RFC3339Nano)
+		return append(b, '"'), true
+	}
+	return b, false
+}
+
 func (w *jsonWriter) writeEncoded(v any) error {
 	e := json.NewEncoder(w.w)
 	e.SetEscapeHTML(false)
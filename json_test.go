@@ -2,12 +2,19 @@ package plog
 
 import (
 	"bytes"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
+	"math"
+	"net/url"
 	"path/filepath"
 	"runtime"
 	"testing"
+	"time"
 
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
 	"github.com/stretchr/testify/require"
 )
 
@@ -325,3 +332,284 @@ type invalidJSON struct{}
 func (invalidJSON) MarshalJSON() ([]byte, error) {
 	return nil, errors.New("invalid json error")
 }
+
+func TestJsonPrefixMode(t *testing.T) {
+	cases := []struct {
+		name     string
+		mode     JSONPrefixMode
+		msg      string
+		expected string
+	}{
+		{
+			name:     "field",
+			mode:     JSONPrefixField,
+			msg:      "hot",
+			expected: `{"level":"info","prefix":"oven","msg":"hot","temp":500}` + "\n",
+		},
+		{
+			name:     "logger",
+			mode:     JSONPrefixLogger,
+			msg:      "hot",
+			expected: `{"level":"info","logger":"oven","msg":"hot","temp":500}` + "\n",
+		},
+		{
+			name:     "message",
+			mode:     JSONPrefixMessage,
+			msg:      "hot",
+			expected: `{"level":"info","msg":"oven: hot","temp":500}` + "\n",
+		},
+		{
+			name:     "message without message",
+			mode:     JSONPrefixMessage,
+			expected: `{"level":"info","msg":"oven:","temp":500}` + "\n",
+		},
+		{
+			name:     "drop",
+			mode:     JSONPrefixDrop,
+			msg:      "hot",
+			expected: `{"level":"info","msg":"hot","temp":500}` + "\n",
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			l := NewWithOptions(&buf, Options{
+				Formatter:      JSONFormatter,
+				Prefix:         "oven",
+				JSONPrefixMode: c.mode,
+			})
+			l.Info(c.msg, "temp", 500)
+			require.Equal(t, c.expected, buf.String())
+		})
+	}
+}
+
+type jsonPoint struct {
+	X     int `json:"x"`
+	Y     int `json:"y,omitempty"`
+	Label string
+}
+
+func (p jsonPoint) String() string { return "point" }
+
+type jsonRaw struct{}
+
+func (jsonRaw) MarshalJSON() ([]byte, error) { return []byte(`{"raw":true}`), nil }
+
+func (jsonRaw) String() string { return "raw" }
+
+type jsonOpaque struct{ v int }
+
+func (o jsonOpaque) String() string { return "opaque" }
+
+func TestJsonMarshaler(t *testing.T) {
+	var buf bytes.Buffer
+	l := NewWithOptions(&buf, Options{Formatter: JSONFormatter})
+	l.Print("values",
+		"point", jsonPoint{X: 1, Label: "a"},
+		"raw", jsonRaw{},
+		"opaque", jsonOpaque{1},
+		"loc", time.UTC,
+		"url", &url.URL{Scheme: "https", Host: "example.com", Path: "/a"},
+		"tagged", struct {
+			X int `json:"x"`
+		}{1},
+		"nested", map[string]interface{}{"p": &jsonPoint{Y: 2}},
+	)
+	require.Equal(t, `{"msg":"values","point":"point","raw":{"raw":true},`+
+		`"opaque":"opaque","loc":"UTC","url":"https://example.com/a","tagged":{"x":1},`+
+		`"nested":{"p":{"x":0,"y":2,"Label":""}}}`+"\n", buf.String())
+}
+
+func TestJsonTimeMode(t *testing.T) {
+	var buf bytes.Buffer
+	now := time.Unix(1700000000, 123456789)
+	l := NewWithOptions(&buf, Options{
+		Formatter:       JSONFormatter,
+		ReportTimestamp: true,
+		TimeFunction:    func(time.Time) time.Time { return now },
+	})
+	cases := []struct {
+		mode JSONTimeMode
+		want string
+	}{
+		{JSONTimeUnix, `{"time":1700000000,"msg":"hi"}`},
+		{JSONTimeUnixMilli, `{"time":1700000000123,"msg":"hi"}`},
+		{JSONTimeUnixNano, `{"time":1700000000123456789,"msg":"hi"}`},
+	}
+	for _, c := range cases {
+		buf.Reset()
+		l.SetJSONTimeMode(c.mode)
+		l.Print("hi")
+		require.Equal(t, c.want+"\n", buf.String())
+	}
+}
+
+func TestJsonIndent(t *testing.T) {
+	var buf, machine bytes.Buffer
+	l := NewWithOptions(&buf, Options{
+		Formatter:     JSONFormatter,
+		JSONIndent:    "  ",
+		MachineOutput: &machine,
+	})
+	l.Print("hi", "a", 1)
+	l.Print("bye")
+	require.Equal(t, "{\n  \"msg\": \"hi\",\n  \"a\": 1\n}\n{\n  \"msg\": \"bye\"\n}\n", buf.String())
+	require.Equal(t, "{\"msg\":\"hi\",\"a\":1}\n{\"msg\":\"bye\"}\n", machine.String())
+}
+
+func TestJsonColors(t *testing.T) {
+	var buf, machine bytes.Buffer
+	l := NewWithOptions(&buf, Options{
+		Formatter:     JSONFormatter,
+		JSONColors:    true,
+		MachineOutput: &machine,
+	})
+	l.Print("hi", "n", -1.5, "ok", true, "z", nil, "q", `a"b`)
+	require.Equal(t, `{"msg":"hi","n":-1.5,"ok":true,"z":null,"q":"a\"b"}`+"\n", buf.String())
+
+	buf.Reset()
+	machine.Reset()
+	l.SetColorProfile(termenv.ANSI256)
+	l.SetHasDarkBackground(true)
+	l.Print("hi", "n", -1.5, "ok", true, "z", nil, "q", `a"b`)
+	st := DefaultStyles()
+	re := lipgloss.NewRenderer(io.Discard)
+	re.SetColorProfile(termenv.ANSI256)
+	re.SetHasDarkBackground(true)
+	r := func(s lipgloss.Style, v string) string { return s.Renderer(re).Render(v) }
+	require.Equal(t, "{"+
+		r(st.Key, `"msg"`)+":"+r(st.Types.String, `"hi"`)+","+
+		r(st.Key, `"n"`)+":"+r(st.Types.Number, `-1.5`)+","+
+		r(st.Key, `"ok"`)+":"+r(st.Types.Bool, `true`)+","+
+		r(st.Key, `"z"`)+":"+r(st.Types.Nil, `null`)+","+
+		r(st.Key, `"q"`)+":"+r(st.Types.String, `"a\"b"`)+
+		"}\n", buf.String())
+	require.Equal(t, `{"msg":"hi","n":-1.5,"ok":true,"z":null,"q":"a\"b"}`+"\n", machine.String())
+}
+
+func TestJsonFieldNames(t *testing.T) {
+	var buf bytes.Buffer
+	l := NewWithOptions(&buf, Options{
+		Formatter:       JSONFormatter,
+		ReportTimestamp: true,
+		TimeFunction:    _zeroTime,
+		Prefix:          "app",
+		JSONFieldNames:  JSONFieldNames{Time: "@timestamp", Level: "severity", Message: "message"},
+	})
+	l.Info("hi", "a", 1)
+	require.Equal(t, `{"@timestamp":"0002/01/01 00:00:00","severity":"info","prefix":"app","message":"hi","a":1}`+"\n", buf.String())
+}
+
+type panicStringer struct{}
+
+func (panicStringer) String() string { panic("boom") }
+
+type panicMarshaler struct{}
+
+func (panicMarshaler) MarshalJSON() ([]byte, error) { panic("boom") }
+
+type badMarshaler struct{}
+
+func (badMarshaler) MarshalJSON() ([]byte, error) { return []byte("{not json"), nil }
+
+type cyclic struct{ Next *cyclic }
+
+// requireValidJSON requires each line of out to be a valid JSON object.
+func requireValidJSON(t testing.TB, out []byte) {
+	t.Helper()
+	for _, line := range bytes.Split(bytes.TrimSuffix(out, []byte{'\n'}), []byte{'\n'}) {
+		var m map[string]interface{}
+		require.NoError(t, json.Unmarshal(line, &m), "%q", line)
+	}
+}
+
+func TestJsonValid(t *testing.T) {
+	c := &cyclic{}
+	c.Next = c
+	values := []interface{}{
+		math.NaN(), math.Inf(1), float32(math.Inf(-1)), Float64("f", math.NaN()),
+		complex(1, 2), make(chan int), func() {}, map[float64]int{math.NaN(): 1},
+		"\x00\x1f\"\\ \xff", []byte{0, 1}, time.Duration(-1), errors.New("e\n\"x"),
+		panicStringer{}, panicMarshaler{}, badMarshaler{}, c, nil,
+		map[interface{}]int{struct{}{}: 1}, Group("g", "k", math.Inf(1)), emptyGroupValuer{},
+	}
+	keys := []interface{}{"k", 1, nil, panicStringer{}, errors.New("err key"), "\x00\"", 1.5}
+
+	var buf bytes.Buffer
+	l := NewWithOptions(&buf, Options{
+		Formatter:       JSONFormatter,
+		ReportCaller:    true,
+		ReportTimestamp: true,
+		Prefix:          "p\"\x01",
+	})
+	for _, k := range keys {
+		for _, v := range values {
+			buf.Reset()
+			l.Info("m\"\x02", k, v, "after", 1)
+			requireValidJSON(t, buf.Bytes())
+		}
+	}
+	buf.Reset()
+	l.Info(panicStringer{}, "odd")
+	requireValidJSON(t, buf.Bytes())
+}
+
+func FuzzJsonFormatter(f *testing.F) {
+	f.Add("msg", "key", "value", 1.5, int64(3))
+	f.Add("\x00", "\"", "\xff\xfe", math.NaN(), int64(-1))
+	f.Add("a\nb", "", " ", math.Inf(-1), int64(math.MinInt64))
+	f.Fuzz(func(t *testing.T, msg, key, value string, fv float64, iv int64) {
+		var buf bytes.Buffer
+		l := NewWithOptions(&buf, Options{Formatter: JSONFormatter, Prefix: value})
+		l.Print(msg, key, value, "group", emptyGroupValuer{}, value, key, key, fv, "i", iv, Float64(key, fv), Str(value, msg))
+		requireValidJSON(t, buf.Bytes())
+	})
+}
+
+func TestJsonNonFinite(t *testing.T) {
+	var buf bytes.Buffer
+	l := NewWithOptions(&buf, Options{Formatter: JSONFormatter})
+	l.Print("", "nan", math.NaN(), "inf", math.Inf(1), "ninf", float32(math.Inf(-1)), "f", float32(1.1))
+	require.Equal(t, `{"nan":"NaN","inf":"+Inf","ninf":"-Inf","f":1.1}`+"\n", buf.String())
+
+	buf.Reset()
+	l.Print("", panicStringer{}, 1, "v", panicStringer{}, Group("g", "k", panicMarshaler{}), "after", true)
+	require.Equal(t, `{"invalid key":1,"v":"invalid value","g":{"k":"invalid value"},"after":true}`+"\n", buf.String())
+}
+
+func TestJsonAppendValue(t *testing.T) {
+	values := []interface{}{
+		nil, "s\"\x00 \xff<>", true, false, 0, -1, int8(-8), int16(16), int32(-32), int64(math.MinInt64),
+		uint(1), uint8(8), uint16(16), uint32(32), uint64(math.MaxUint64),
+		float32(1.1), float32(1e-7), float32(3e21), 0.0, 1e6, 1e21, 1e-7, -2.5, 123456789.125,
+		time.Duration(1500), time.Date(2024, 1, 2, 3, 4, 5, 6, time.FixedZone("", -5*60*60)),
+		time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+	}
+	for _, v := range values {
+		var want bytes.Buffer
+		e := json.NewEncoder(&want)
+		e.SetEscapeHTML(false)
+		require.NoError(t, e.Encode(v))
+		got, ok := appendJSONValue(nil, v)
+		require.True(t, ok, "%#v", v)
+		require.Equal(t, want.String(), string(got)+"\n", "%#v", v)
+	}
+	for _, v := range []interface{}{math.NaN(), time.Date(10000, 1, 1, 0, 0, 0, 0, time.UTC), []int{1}, struct{}{}} {
+		_, ok := appendJSONValue(nil, v)
+		require.False(t, ok, "%#v", v)
+	}
+}
+
+func BenchmarkJsonFormatter(b *testing.B) {
+	var buf bytes.Buffer
+	l := NewWithOptions(&buf, Options{Formatter: JSONFormatter, ReportTimestamp: true})
+	err := errors.New("oops")
+	now := time.Now()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		buf.Reset()
+		l.Info("request", "method", "GET", "status", 200, "latency", 1.5, "ok", true, "at", now, "err", err)
+	}
+}
@@ -0,0 +1,71 @@
+package plog
+
+import (
+	"bytes"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
+)
+
+// SetJSONColors sets whether the JSONFormatter highlights the keys, strings,
+// numbers, booleans and nulls of the records with the Key and Types styles,
+// when the output is styled. The machine output is never highlighted.
+func (l *Logger) SetJSONColors(enabled bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.jsonColors = enabled
+}
+
+// colorJSON highlights the JSON record written to the buffer from start.
+func (l *Logger) colorJSON(b *bytes.Buffer, start int) {
+	if l.re.ColorProfile() == termenv.Ascii {
+		return
+	}
+	record := append([]byte(nil), b.Bytes()[start:]...)
+	b.Truncate(start)
+
+	st := l.styles
+	render := func(s lipgloss.Style, token []byte) {
+		b.WriteString(s.Renderer(l.re).Render(string(token)))
+	}
+	for i := 0; i < len(record); {
+		c := record[i]
+		switch {
+		case c == '"':
+			end := i + 1
+			for end < len(record) && record[end] != '"' {
+				if record[end] == '\\' {
+					end++
+				}
+				end++
+			}
+			end = min(end+1, len(record))
+			next := bytes.TrimLeft(record[end:], " \n\t")
+			if len(next) > 0 && next[0] == ':' {
+				render(st.Key, record[i:end])
+			} else {
+				render(st.Types.String, record[i:end])
+			}
+			i = end
+		case c == '-' || (c >= '0' && c <= '9'):
+			end := i + 1
+			for end < len(record) && bytes.IndexByte([]byte("0123456789.eE+-"), record[end]) != -1 {
+				end++
+			}
+			render(st.Types.Number, record[i:end])
+			i = end
+		case bytes.HasPrefix(record[i:], []byte("true")):
+			render(st.Types.Bool, record[i:i+4])
+			i += 4
+		case bytes.HasPrefix(record[i:], []byte("false")):
+			render(st.Types.Bool, record[i:i+5])
+			i += 5
+		case bytes.HasPrefix(record[i:], []byte("null")):
+			render(st.Types.Nil, record[i:i+4])
+			i += 4
+		default:
+			b.WriteByte(c)
+			i++
+		}
+	}
+}
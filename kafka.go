@@ -0,0 +1,173 @@
+package plog
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultKafkaBatchSize is the default number of records per batch.
+	DefaultKafkaBatchSize = 100
+	// DefaultKafkaLinger is the default time records wait for a batch to fill
+	// up.
+	DefaultKafkaLinger = time.Second
+	// DefaultKafkaTimeout is the default timeout for producing a batch.
+	DefaultKafkaTimeout = 10 * time.Second
+)
+
+// KafkaMessage is a message produced to Kafka.
+type KafkaMessage struct {
+	Topic string
+	Key   []byte
+	Value []byte
+}
+
+// KafkaProducer produces batches of messages to Kafka. Implement it on top of
+// the Kafka client of your choice.
+type KafkaProducer interface {
+	Produce(ctx context.Context, msgs []KafkaMessage) error
+}
+
+// KafkaKeyFunc returns the message key of a record. A nil key lets the
+// producer pick the partition.
+type KafkaKeyFunc func(record []byte) []byte
+
+// KafkaKeyField returns a KafkaKeyFunc keying JSON records by the value of
+// the given top level string field, e.g. PrefixKey.
+func KafkaKeyField(field string) KafkaKeyFunc {
+	return func(record []byte) []byte {
+		var m map[string]json.RawMessage
+		if err := json.Unmarshal(record, &m); err != nil {
+			return nil
+		}
+		var s string
+		if err := json.Unmarshal(m[field], &s); err != nil || s == "" {
+			return nil
+		}
+		return []byte(s)
+	}
+}
+
+// KafkaOptions are the options for a KafkaWriter.
+type KafkaOptions struct {
+	// Producer produces the batches.
+	Producer KafkaProducer
+	// Topic is the topic records are published to.
+	Topic string
+	// Key returns the message key of a record. The default is no key.
+	Key KafkaKeyFunc
+	// BatchSize is the number of records that triggers a batch. The default
+	// is DefaultKafkaBatchSize.
+	BatchSize int
+	// Linger is the maximum time a record waits for its batch to fill up.
+	// The default is DefaultKafkaLinger.
+	Linger time.Duration
+	// Timeout is the timeout for producing a batch. The default is
+	// DefaultKafkaTimeout.
+	Timeout time.Duration
+	// OnError is called with the messages of a batch that couldn't be
+	// produced. The default is to drop them.
+	OnError func(err error, msgs []KafkaMessage)
+}
+
+// KafkaWriter is an io.Writer that publishes each write as a Kafka message,
+// in batches. Use it with the JSONFormatter:
+//
+//	w := log.NewKafkaWriter(log.KafkaOptions{
+//		Producer: producer,
+//		Topic:    "logs",
+//		Key:      log.KafkaKeyField(log.PrefixKey),
+//	})
+//	defer w.Close()
+//	logger := log.NewWithOptions(w, log.Options{Formatter: log.JSONFormatter})
+type KafkaWriter struct {
+	opts KafkaOptions
+
+	mu    sync.Mutex
+	batch []KafkaMessage
+	timer *time.Timer
+
+	// produceMu serializes batches so records are produced in order.
+	produceMu sync.Mutex
+}
+
+// NewKafkaWriter returns a new KafkaWriter.
+func NewKafkaWriter(o KafkaOptions) *KafkaWriter {
+	if o.BatchSize <= 0 {
+		o.BatchSize = DefaultKafkaBatchSize
+	}
+	if o.Linger <= 0 {
+		o.Linger = DefaultKafkaLinger
+	}
+	if o.Timeout <= 0 {
+		o.Timeout = DefaultKafkaTimeout
+	}
+	return &KafkaWriter{opts: o}
+}
+
+// Write adds p to the current batch. It never fails, delivery errors are
+// reported to the OnError callback.
+func (w *KafkaWriter) Write(p []byte) (int, error) {
+	record := append([]byte(nil), p...)
+	if n := len(record); n > 0 && record[n-1] == '\n' {
+		record = record[:n-1]
+	}
+	msg := KafkaMessage{Topic: w.opts.Topic, Value: record}
+	if w.opts.Key != nil {
+		msg.Key = w.opts.Key(record)
+	}
+
+	w.mu.Lock()
+	w.batch = append(w.batch, msg)
+	full := len(w.batch) >= w.opts.BatchSize
+	if !full && w.timer == nil {
+		w.timer = time.AfterFunc(w.opts.Linger, w.Flush)
+	}
+	w.mu.Unlock()
+
+	if full {
+		w.Flush()
+	}
+	return len(p), nil
+}
+
+func (w *KafkaWriter) messageOriented() bool { return true }
+
+// take returns the current batch and starts a new one. It must be called
+// with w.mu held.
+func (w *KafkaWriter) take() []KafkaMessage {
+	batch := w.batch
+	w.batch = nil
+	if w.timer != nil {
+		w.timer.Stop()
+		w.timer = nil
+	}
+	return batch
+}
+
+// Flush produces the current batch.
+func (w *KafkaWriter) Flush() {
+	w.produceMu.Lock()
+	defer w.produceMu.Unlock()
+
+	w.mu.Lock()
+	batch := w.take()
+	w.mu.Unlock()
+	if len(batch) == 0 {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), w.opts.Timeout)
+	defer cancel()
+	if err := w.opts.Producer.Produce(ctx, batch); err != nil && w.opts.OnError != nil {
+		w.opts.OnError(err, batch)
+	}
+}
+
+// Close produces the current batch. It doesn't close the producer.
+func (w *KafkaWriter) Close() error {
+	w.Flush()
+	return nil
+}
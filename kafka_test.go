@@ -0,0 +1,81 @@
+package plog
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type fakeProducer struct {
+	mu      sync.Mutex
+	batches [][]KafkaMessage
+	err     error
+}
+
+func (p *fakeProducer) Produce(_ context.Context, msgs []KafkaMessage) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.batches = append(p.batches, msgs)
+	return p.err
+}
+
+func (p *fakeProducer) Batches() [][]KafkaMessage {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.batches
+}
+
+func TestKafkaWriter(t *testing.T) {
+	p := &fakeProducer{}
+	w := NewKafkaWriter(KafkaOptions{
+		Producer:  p,
+		Topic:     "logs",
+		Key:       KafkaKeyField(PrefixKey),
+		BatchSize: 2,
+		Linger:    time.Hour,
+	})
+	l := NewWithOptions(w, Options{Formatter: JSONFormatter})
+	l.WithPrefix("oven").Info("baking")
+	require.Empty(t, p.Batches())
+	l.Warn("hot")
+
+	require.Equal(t, [][]KafkaMessage{{
+		{Topic: "logs", Key: []byte("oven"), Value: []byte(`{"level":"info","prefix":"oven","msg":"baking"}`)},
+		{Topic: "logs", Value: []byte(`{"level":"warn","msg":"hot"}`)},
+	}}, p.Batches())
+
+	// Flushing the logger produces partial batches.
+	l.Error("burnt")
+	l.Flush()
+	require.Len(t, p.Batches(), 2)
+	require.Len(t, p.Batches()[1], 1)
+}
+
+func TestKafkaWriterLinger(t *testing.T) {
+	p := &fakeProducer{}
+	w := NewKafkaWriter(KafkaOptions{Producer: p, Topic: "logs", Linger: time.Millisecond})
+	_, err := w.Write([]byte("record\n"))
+	require.NoError(t, err)
+	require.Eventually(t, func() bool { return len(p.Batches()) == 1 }, time.Second, time.Millisecond)
+	require.Equal(t, []byte("record"), p.Batches()[0][0].Value)
+}
+
+func TestKafkaWriterError(t *testing.T) {
+	p := &fakeProducer{err: errors.New("broker down")}
+	var failed []KafkaMessage
+	w := NewKafkaWriter(KafkaOptions{
+		Producer: p,
+		Topic:    "logs",
+		OnError: func(err error, msgs []KafkaMessage) {
+			require.EqualError(t, err, "broker down")
+			failed = msgs
+		},
+	})
+	_, _ = w.Write([]byte("record"))
+	require.NoError(t, w.Close())
+	require.Len(t, failed, 1)
+}
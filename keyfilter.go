@@ -0,0 +1,118 @@
+package plog
+
+import "fmt"
+
+// KeyFilter selects the keyvals of records by key. The built-in keys, like
+// MessageKey and LevelKey, are always kept.
+type KeyFilter struct {
+	// Allow are the only keys kept, when not empty.
+	Allow []string
+	// Deny are keys dropped, e.g. "password".
+	Deny []string
+}
+
+// keyFilter is a compiled KeyFilter.
+type keyFilter struct {
+	allow map[string]struct{}
+	deny  map[string]struct{}
+}
+
+func newKeyFilter(f *KeyFilter) *keyFilter {
+	if f == nil || len(f.Allow)+len(f.Deny) == 0 {
+		return nil
+	}
+	kf := &keyFilter{}
+	if len(f.Allow) > 0 {
+		kf.allow = make(map[string]struct{}, len(f.Allow))
+		for _, k := range f.Allow {
+			kf.allow[k] = struct{}{}
+		}
+	}
+	kf.deny = make(map[string]struct{}, len(f.Deny))
+	for _, k := range f.Deny {
+		kf.deny[k] = struct{}{}
+	}
+	return kf
+}
+
+// keep returns whether the keyvals under key are kept.
+func (f *keyFilter) keep(key interface{}) bool {
+	k, ok := key.(string)
+	if !ok {
+		k = fmt.Sprint(key)
+	}
+	switch k {
+	case TimestampKey, LevelKey, CallerKey, PrefixKey, MessageKey, SchemaVersionKey:
+		return true
+	}
+	if _, ok := f.deny[k]; ok {
+		return false
+	}
+	if f.allow != nil {
+		_, ok := f.allow[k]
+		return ok
+	}
+	return true
+}
+
+// apply returns keyvals without the filtered out keys. It returns keyvals as
+// is if f is nil or nothing is filtered out.
+func (f *keyFilter) apply(keyvals []interface{}) []interface{} {
+	if f == nil {
+		return keyvals
+	}
+	var out []interface{}
+	for i := 0; i+1 < len(keyvals); i += 2 {
+		if f.keep(keyvals[i]) {
+			if out != nil {
+				out = append(out, keyvals[i], keyvals[i+1])
+			}
+			continue
+		}
+		if out == nil {
+			out = append(make([]interface{}, 0, len(keyvals)), keyvals[:i]...)
+		}
+	}
+	if out == nil {
+		return keyvals
+	}
+	return out
+}
+
+// SetKeyFilter sets the keys of the records kept by the logger, applied to
+// the logger fields and the keyvals of each record, before the processors. A
+// nil f disables the filter.
+func (l *Logger) SetKeyFilter(f *KeyFilter) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.keyFilter = newKeyFilter(f)
+}
+
+// WithKeyFilter returns a new logger keeping the keys of the records selected
+// by f. See SetKeyFilter.
+func (l *Logger) WithKeyFilter(f KeyFilter) *Logger {
+	sl := l.With()
+	sl.SetKeyFilter(&f)
+	return sl
+}
+
+// SetMachineKeyFilter sets the keys of the records written to the machine
+// output, e.g. to only ship some keys to a network sink while the main output
+// has them all. A nil f disables the filter.
+func (l *Logger) SetMachineKeyFilter(f *KeyFilter) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.machineFilter = newKeyFilter(f)
+}
+
+// machineRecords returns the records filtered for the machine output.
+func (l *Logger) machineRecords(records [][]interface{}) [][]interface{} {
+	if l.machineFilter == nil {
+		return records
+	}
+	out := make([][]interface{}, len(records))
+	for i, kvs := range records {
+		out[i] = l.machineFilter.apply(kvs)
+	}
+	return out
+}
@@ -0,0 +1,38 @@
+package plog
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestKeyFilter(t *testing.T) {
+	var buf bytes.Buffer
+	l := NewWithOptions(&buf, Options{
+		Formatter: LogfmtFormatter,
+		KeyFilter: &KeyFilter{Deny: []string{"password"}},
+	})
+	l.With("password", "hunter2").Print("login", "user", "bob", "password", "x")
+	require.Equal(t, "msg=login user=bob\n", buf.String())
+
+	buf.Reset()
+	l.WithKeyFilter(KeyFilter{Allow: []string{"trace_id"}}).
+		With("trace_id", "abc", "user", "bob").
+		Info("login", "n", 1)
+	require.Equal(t, "level=info msg=login trace_id=abc\n", buf.String())
+
+	buf.Reset()
+	l.SetKeyFilter(nil)
+	l.Print("login", "password", "x")
+	require.Equal(t, "msg=login password=x\n", buf.String())
+}
+
+func TestMachineKeyFilter(t *testing.T) {
+	var buf, machine bytes.Buffer
+	l := NewWithOptions(&buf, Options{Formatter: LogfmtFormatter, MachineOutput: &machine})
+	l.SetMachineKeyFilter(&KeyFilter{Allow: []string{KeyTraceID}})
+	l.Print("login", KeyTraceID, "abc", "user", "bob")
+	require.Equal(t, "msg=login trace_id=abc user=bob\n", buf.String())
+	require.Equal(t, `{"msg":"login","trace_id":"abc"}`+"\n", machine.String())
+}
@@ -0,0 +1,90 @@
+package plog
+
+import "time"
+
+// Well-known keys. Using them, directly or through the helpers below, keeps
+// field names consistent across services so dashboards and queries can rely
+// on them.
+const (
+	// KeyRequestID is the key for the ID of the request being handled.
+	KeyRequestID = "request_id"
+	// KeyCorrelationID is the key for an ID shared by related requests.
+	KeyCorrelationID = "correlation_id"
+	// KeyTraceID is the key for the distributed tracing trace ID.
+	KeyTraceID = "trace_id"
+	// KeySpanID is the key for the distributed tracing span ID.
+	KeySpanID = "span_id"
+	// KeyUserID is the key for the ID of the user on whose behalf the work is
+	// done.
+	KeyUserID = "user_id"
+	// KeySessionID is the key for the user session ID.
+	KeySessionID = "session_id"
+	// KeyComponent is the key for the component emitting the record.
+	KeyComponent = "component"
+	// KeyDurationMS is the key for a duration in milliseconds.
+	KeyDurationMS = "duration_ms"
+	// KeyError is the key for an error.
+	KeyError = "error"
+	// KeyPanic is the key for a recovered panic value.
+	KeyPanic = "panic"
+	// KeyStack is the key for a stack trace.
+	KeyStack = "stack"
+	// KeyMethod is the key for the HTTP method of a request.
+	KeyMethod = "method"
+	// KeyPath is the key for the URL path of a request.
+	KeyPath = "path"
+	// KeyStatus is the key for the HTTP status code of a response.
+	KeyStatus = "status"
+	// KeyRemoteAddr is the key for the network address of a client.
+	KeyRemoteAddr = "remote_addr"
+	// KeyProto is the key for the protocol of a request, e.g. "HTTP/1.1".
+	KeyProto = "proto"
+	// KeyBytes is the key for the size of a response body in bytes.
+	KeyBytes = "bytes"
+	// KeyReferer is the key for the referer of a request.
+	KeyReferer = "referer"
+	// KeyUserAgent is the key for the user agent of a request.
+	KeyUserAgent = "user_agent"
+)
+
+// WithRequestID returns a new logger with the KeyRequestID field set to id.
+func (l *Logger) WithRequestID(id string) *Logger {
+	return l.With(KeyRequestID, id)
+}
+
+// WithTraceID returns a new logger with the KeyTraceID and KeySpanID fields
+// set. An empty spanID is omitted.
+func (l *Logger) WithTraceID(traceID, spanID string) *Logger {
+	if spanID == "" {
+		return l.With(KeyTraceID, traceID)
+	}
+	return l.With(KeyTraceID, traceID, KeySpanID, spanID)
+}
+
+// WithUserID returns a new logger with the KeyUserID field set to id.
+func (l *Logger) WithUserID(id string) *Logger {
+	return l.With(KeyUserID, id)
+}
+
+// WithComponent returns a new logger with the KeyComponent field set to name.
+func (l *Logger) WithComponent(name string) *Logger {
+	return l.With(KeyComponent, name)
+}
+
+// WithError returns a new logger with err under ErrorKey.
+func (l *Logger) WithError(err error) *Logger {
+	return l.With(ErrorKey, err)
+}
+
+// WithDuration returns a new logger with the KeyDurationMS field set to d in
+// fractional milliseconds.
+func (l *Logger) WithDuration(d time.Duration) *Logger {
+	return l.With(KeyDurationMS, DurationMS(d))
+}
+
+// DurationMS returns d in fractional milliseconds, the unit of KeyDurationMS:
+//
+//	log.Info("done", log.KeyDurationMS, log.DurationMS(time.Since(start)))
+func DurationMS(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
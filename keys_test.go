@@ -0,0 +1,26 @@
+package plog
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestKeyHelpers(t *testing.T) {
+	var buf bytes.Buffer
+	l := NewWithOptions(&buf, Options{Formatter: JSONFormatter}).
+		WithRequestID("req-1").
+		WithTraceID("abc", "").
+		WithUserID("alice").
+		WithComponent("oven").
+		WithDuration(1500 * time.Microsecond)
+	l.Info("done")
+	require.Equal(t, `{"level":"info","msg":"done","request_id":"req-1","trace_id":"abc",`+
+		`"user_id":"alice","component":"oven","duration_ms":1.5}`+"\n", buf.String())
+
+	buf.Reset()
+	l.WithTraceID("abc", "def").Info("span")
+	require.Contains(t, buf.String(), `"trace_id":"abc","span_id":"def"`)
+}
@@ -1,6 +1,7 @@
 package plog
 
 import (
+	"bytes"
 	"errors"
 	"fmt"
 	"time"
@@ -8,24 +9,40 @@ import (
 	"github.com/go-logfmt/logfmt"
 )
 
-func (l *Logger) logfmtFormatter(keyvals ...interface{}) {
-	e := logfmt.NewEncoder(&l.b)
+func (l *Logger) logfmtFormatter(b *bytes.Buffer, keyvals ...interface{}) {
+	e := logfmt.NewEncoder(b)
 
 	for i := 0; i < len(keyvals); i += 2 {
-		switch keyvals[i] {
+		key, val := keyvals[i], keyvals[i+1]
+		switch key {
 		case TimestampKey:
-			if t, ok := keyvals[i+1].(time.Time); ok {
-				keyvals[i+1] = t.Format(l.timeFormat)
+			if t, ok := val.(time.Time); ok {
+				val = l.formatTime(LogfmtFormatter, t)
 			}
 		default:
-			if key := fmt.Sprint(keyvals[i]); key != "" {
-				keyvals[i] = key
+			// Only non-string keys are converted, to avoid boxing them
+			// again.
+			k, ok := key.(string)
+			if !ok {
+				k = fmt.Sprint(key)
+				if k != "" {
+					key = k
+				}
+			}
+			if s, ok := l.formatNumber(k, val); ok {
+				val = s
+			}
+			if s, ok := l.formatDuration(val); ok {
+				val = s
+			}
+			if s, ok := l.formatBinary(val); ok {
+				val = s
 			}
 		}
-		err := e.EncodeKeyval(keyvals[i], keyvals[i+1])
+		err := e.EncodeKeyval(key, val)
 		if err != nil && errors.Is(err, logfmt.ErrUnsupportedValueType) {
 			// If the value is not supported by logfmt, we try to convert it to a string.
-			_ = e.EncodeKeyval(keyvals[i], fmt.Sprintf("%+v", keyvals[i+1]))
+			_ = e.EncodeKeyval(key, fmt.Sprintf("%+v", val))
 		}
 	}
 	_ = e.EndRecord()
@@ -6,6 +6,7 @@ import (
 	"io"
 	"os"
 	"runtime"
+	"sort"
 	"strings"
 	"sync"
 	"sync/atomic"
@@ -23,10 +24,11 @@ type LoggerOption = func(*Logger)
 
 // Logger is a Logger that implements Logger.
 type Logger struct {
-	w  io.Writer
-	b  bytes.Buffer
-	mu *sync.RWMutex
-	re *lipgloss.Renderer
+	w       io.Writer
+	machine io.Writer
+	wmu     *sync.Mutex
+	mu      *sync.RWMutex
+	re      *lipgloss.Renderer
 
 	isDiscard uint32
 
@@ -36,30 +38,89 @@ type Logger struct {
 	timeFormat      string
 	callerOffset    int
 	callerFormatter CallerFormatter
+	terminalCheck   TerminalCheck
 	formatter       Formatter
-
-	reportCaller    bool
-	reportTimestamp bool
-
-	fields []interface{}
-
-	helpers *sync.Map
-	styles  *Styles
+	jsonPrefix      JSONPrefixMode
+	jsonTime        JSONTimeMode
+	jsonIndent      string
+	jsonColors      bool
+	jsonNames       JSONFieldNames
+	jsonNested      bool
+	framing         Framing
+
+	reportCaller        bool
+	reportTimestamp     bool
+	reportSchemaVersion bool
+	errorChains         bool
+	shutdownSummary     bool
+	rawValues           bool
+	flattenStructs      bool
+	maxValueLen         int
+	maxFields           int
+	sanitizeMode        Sanitize
+	duplicateKeys       DuplicateKeys
+	omitEmpty           bool
+	separator           string
+	indentSeparator     string
+	multiline           MultilineLayout
+	align               *alignState
+	levelWidth          int
+	colorProfile        *termenv.Profile
+	teeRenderers        []*lipgloss.Renderer
+	styleFunc           StyleFunc
+	typeColors          bool
+	keyColors           bool
+	callerLink          CallerLink
+	hyperlinks          bool
+	goroutineID         bool
+	utc                 bool
+	relativeTime        bool
+	created             time.Time
+	timeFormats         map[Formatter]string
+	durationFormat      *DurationFormat
+	binaryFormat        BinaryFormat
+	binaryMaxLen        int
+	prettyValues        bool
+
+	hashBucket time.Duration
+	emf        *EMFOptions
+	csv        *csvState
+
+	fields        []interface{}
+	meta          map[string]FieldMeta
+	numberFormats map[string]NumberFormat
+	processors    []Processor
+	async         *atomic.Pointer[asyncQueue]
+	keyFilter     *keyFilter
+	machineFilter *keyFilter
+
+	helpers     *sync.Map
+	callerDebug *sync.Once
+	stackLevel  *Level
+	styles      *Styles
+	stats       *stats
 }
 
 // Logf logs a message with formatting.
 func (l *Logger) Logf(level Level, format string, args ...interface{}) {
+	if !l.enabled(level) {
+		return
+	}
 	l.Log(level, fmt.Sprintf(format, args...))
 }
 
-// Log logs the given message with the given keyvals for the given level.
-func (l *Logger) Log(level Level, msg interface{}, keyvals ...interface{}) {
+// enabled returns whether records of the given level are logged. It doesn't
+// allocate, so that calls below the logger level are free.
+func (l *Logger) enabled(level Level) bool {
 	if atomic.LoadUint32(&l.isDiscard) != 0 {
-		return
+		return false
 	}
+	return atomic.LoadInt32(&l.level) <= int32(level) && (debugEnabled || level > DebugLevel)
+}
 
-	// check if the level is allowed
-	if atomic.LoadInt32(&l.level) > int32(level) {
+// Log logs the given message with the given keyvals for the given level.
+func (l *Logger) Log(level Level, msg interface{}, keyvals ...interface{}) {
+	if !l.enabled(level) {
 		return
 	}
 
@@ -67,74 +128,401 @@ func (l *Logger) Log(level Level, msg interface{}, keyvals ...interface{}) {
 	if l.reportCaller {
 		// Skip log.log, the caller, and any offset added.
 		frames := l.frames(l.callerOffset + 2)
+		var considered []runtime.Frame
+		var exhausted bool
 		for {
 			f, more := frames.Next()
 			_, helper := l.helpers.Load(f.Function)
+			if l.callerDebug != nil {
+				considered = append(considered, f)
+			}
 			if !helper || !more {
 				// Found a frame that wasn't a helper function.
 				// Or we ran out of frames to check.
 				frame = f
+				exhausted = helper
 				break
 			}
 		}
+		if l.callerDebug != nil {
+			l.debugCaller(frame, considered, exhausted)
+		}
+	}
+	if l.wantsStack(level) {
+		if len(keyvals)%2 != 0 {
+			keyvals = append(keyvals[:len(keyvals):len(keyvals)], ErrMissingValue)
+		}
+		keyvals = append(keyvals[:len(keyvals):len(keyvals)], KeyStack, l.stacktrace(l.callerOffset+1))
 	}
 	l.handle(level, l.timeFunc(time.Now()), []runtime.Frame{frame}, msg, keyvals...)
 }
 
 func (l *Logger) handle(level Level, ts time.Time, frames []runtime.Frame, msg interface{}, keyvals ...interface{}) {
-	var kvs []interface{}
-	if l.reportTimestamp && !ts.IsZero() {
-		kvs = append(kvs, TimestampKey, ts)
-	}
-
-	_, ok := l.styles.Levels[level]
-	if ok {
-		kvs = append(kvs, LevelKey, level)
+	e := Entry{
+		Time:   ts,
+		Level:  level,
+		Prefix: l.prefix,
 	}
 
 	if l.reportCaller && len(frames) > 0 && frames[0].PC != 0 {
 		file, line, fn := l.location(frames)
 		if file != "" {
-			caller := l.callerFormatter(file, line, fn)
-			kvs = append(kvs, CallerKey, caller)
+			e.Caller = l.callerFormatter(file, line, fn)
+			e.file, e.line = file, line
 		}
 	}
 
-	if l.prefix != "" {
-		kvs = append(kvs, PrefixKey, l.prefix)
+	switch m := msg.(type) {
+	case nil:
+	case string:
+		e.Message, e.msg = m, msg
+	default:
+		e.Message = fmt.Sprint(m)
 	}
 
-	if msg != nil {
-		if m := fmt.Sprint(msg); m != "" {
-			kvs = append(kvs, MessageKey, m)
-		}
+	e.Keyvals = appendErrorStack(l.appendFields(keyvals))
+	if !l.prepare(&e) {
+		return
 	}
 
+	// The keyvals are only used while formatting, so reuse their slice.
+	p := keyvalsPool.Get().(*[]interface{})
+	kvs := l.appendKeyvals((*p)[:0], &e)
+	l.write(e.Level, kvs)
+	if cap(kvs) <= maxPooledKeyvals {
+		clear(kvs)
+		*p = kvs
+		keyvalsPool.Put(p)
+	}
+}
+
+// boxedKeys holds the built-in keys boxed once, so that records don't box
+// them again. The keys are variables, so it only holds their default value.
+var boxedKeys = func() map[string]interface{} {
+	m := make(map[string]interface{})
+	for _, k := range []string{SchemaVersionKey, TimestampKey, LevelKey, CallerKey, PrefixKey, MessageKey} {
+		m[k] = k
+	}
+	return m
+}()
+
+// boxKey returns the built-in key k, boxed in an interface.
+func boxKey(k string) interface{} {
+	if v, ok := boxedKeys[k]; ok {
+		return v
+	}
+	return k
+}
+
+// keyvalsPool holds the keyvals slices of records being formatted.
+var keyvalsPool = sync.Pool{New: func() interface{} { return new([]interface{}) }}
+
+// maxPooledKeyvals is the capacity above which keyvals slices aren't pooled.
+const maxPooledKeyvals = 256
+
+// appendFields returns the logger fields followed by keyvals, with their
+// LogValuer values resolved.
+func (l *Logger) appendFields(keyvals []interface{}) []interface{} {
+	// Single slot fields and groups take two slots once normalized, so
+	// reserve enough room for a single allocation.
+	kvs := make([]interface{}, 0, 2*(len(l.fields)+len(keyvals))+4)
+
 	// append logger fields
-	kvs = append(kvs, l.fields...)
-	if len(l.fields)%2 != 0 {
+	kvs = appendNormalized(kvs, l.fields)
+	if len(kvs)%2 != 0 {
 		kvs = append(kvs, ErrMissingValue)
 	}
+	if l.goroutineID {
+		kvs = append(kvs, KeyGoroutineID, goroutineID())
+	}
 
 	// append the rest
-	kvs = append(kvs, keyvals...)
-	if len(keyvals)%2 != 0 {
+	n := len(kvs)
+	kvs = appendNormalized(kvs, keyvals)
+	if (len(kvs)-n)%2 != 0 {
 		kvs = append(kvs, ErrMissingValue)
 	}
+	resolveLogValuers(kvs)
+	kvs = l.resolveDuplicates(l.omitEmptyValues(kvs))
+	return l.truncateValues(l.limitFields(l.keyFilter.apply(kvs)))
+}
 
-	l.mu.Lock()
-	defer l.mu.Unlock()
-	switch l.formatter {
+// prepare runs the processors on the entry and records it. It returns false
+// if the entry was dropped.
+func (l *Logger) prepare(e *Entry) bool {
+	if !l.process(e) {
+		return false
+	}
+
+	if l.hashBucket > 0 {
+		e.Keyvals = append(e.Keyvals, HashKey, recordHash(e, l.hashBucket))
+	}
+
+	l.stats.record(e)
+	return true
+}
+
+// LogBatch logs the given entries, formatting them under a single lock and
+// writing them to the output in a single write. It's meant for importers and
+// replay tools emitting many records at once. Outputs sending each write as
+// a message, such as GELFWriter, KafkaWriter, or a NetWriter on a datagram
+// network, get one write per record instead.
+//
+// Entries below the logger level are skipped, as are debug entries in builds
+// with the plog_nodebug tag. The logger fields are added to
+// every entry, and entries without a time or prefix get the current time and
+// the logger prefix. Processors run on every entry as usual.
+func (l *Logger) LogBatch(entries []Entry) {
+	batch := make([][]interface{}, 0, len(entries))
+	levels := make([]Level, 0, len(entries))
+	// The highest level of the entries, noLevel if none has one, so that
+	// entries without a level don't make the batch a priority.
+	maxLevel := noLevel
+	for _, e := range entries {
+		if !l.enabled(e.Level) {
+			continue
+		}
+		if e.Time.IsZero() {
+			e.Time = l.timeFunc(time.Now())
+		}
+		if e.Prefix == "" {
+			e.Prefix = l.prefix
+		}
+		e.Keyvals = appendErrorStack(l.appendFields(e.Keyvals))
+		if !l.prepare(&e) {
+			continue
+		}
+		batch = append(batch, l.keyvals(&e))
+		levels = append(levels, e.Level)
+		if e.Level != noLevel && (maxLevel == noLevel || e.Level > maxLevel) {
+			maxLevel = e.Level
+		}
+	}
+	if len(batch) == 0 {
+		return
+	}
+	if l.messageOutput() {
+		for i, kvs := range batch {
+			l.write(levels[i], kvs)
+		}
+		return
+	}
+	l.write(maxLevel, batch...)
+}
+
+// messageWriter is implemented by outputs that send each write as a single
+// message, e.g. a datagram, and so must get one record per write.
+type messageWriter interface {
+	messageOriented() bool
+}
+
+// messageOutput reports whether any of the outputs is message oriented.
+func (l *Logger) messageOutput() bool {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	writers := []io.Writer{l.w, l.machine}
+	if t, ok := l.w.(*TeeWriter); ok {
+		writers = append(writers, t.writers...)
+	}
+	for _, w := range writers {
+		if m, ok := w.(messageWriter); ok && m.messageOriented() {
+			return true
+		}
+	}
+	return false
+}
+
+// keyvals returns the entry as a flat list of keyvals, starting with the
+// built-in keys, as expected by the formatters.
+func (l *Logger) keyvals(e *Entry) []interface{} {
+	return l.appendKeyvals(make([]interface{}, 0, len(e.Keyvals)+12), e)
+}
+
+// appendKeyvals appends the keyvals of the entry to kvs, see keyvals.
+func (l *Logger) appendKeyvals(kvs []interface{}, e *Entry) []interface{} {
+	if l.reportSchemaVersion {
+		kvs = append(kvs, boxKey(SchemaVersionKey), SchemaVersion)
+	}
+
+	if l.reportTimestamp && !e.Time.IsZero() {
+		if l.utc {
+			kvs = append(kvs, boxKey(TimestampKey), e.Time.UTC())
+		} else {
+			kvs = append(kvs, boxKey(TimestampKey), e.Time)
+		}
+	}
+
+	_, ok := l.styles.Levels[e.Level]
+	if ok {
+		kvs = append(kvs, boxKey(LevelKey), e.Level)
+	}
+
+	if e.Caller != "" {
+		if l.callerLink != nil && e.file != "" {
+			kvs = append(kvs, boxKey(CallerKey), callerLink{e.Caller, l.callerLink(e.file, e.line)})
+		} else {
+			kvs = append(kvs, boxKey(CallerKey), e.Caller)
+		}
+	}
+
+	if e.Prefix != "" {
+		kvs = append(kvs, boxKey(PrefixKey), e.Prefix)
+	}
+
+	if e.Message != "" {
+		if m, ok := e.msg.(string); ok && m == e.Message {
+			kvs = append(kvs, boxKey(MessageKey), e.msg)
+		} else {
+			kvs = append(kvs, boxKey(MessageKey), e.Message)
+		}
+	}
+
+	return append(kvs, e.Keyvals...)
+}
+
+// bufferPool holds the buffers the records are formatted into, so that
+// concurrent calls don't wait for each other while formatting.
+var bufferPool = sync.Pool{New: func() interface{} { return new(bytes.Buffer) }}
+
+// maxPooledBuffer is the capacity above which buffers aren't pooled, so that
+// a single huge record doesn't pin its memory.
+const maxPooledBuffer = 64 << 10
+
+func getBuffer() *bytes.Buffer {
+	return bufferPool.Get().(*bytes.Buffer)
+}
+
+func putBuffer(b *bytes.Buffer) {
+	if b.Cap() > maxPooledBuffer {
+		return
+	}
+	b.Reset()
+	bufferPool.Put(b)
+}
+
+// write formats the records and writes them to the outputs, in a single write
+// per output. Level is the highest level of the records. The records are
+// formatted into a buffer of the call, and only the writes are serialized.
+func (l *Logger) write(level Level, records ...[]interface{}) {
+	b := getBuffer()
+	defer putBuffer(b)
+
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	if q := l.async.Load(); q != nil {
+		r := asyncRecord{mu: l.wmu}
+		var tee []asyncRecord
+		if l.teeing() {
+			tee = l.teeRecords(b, records)
+		} else if l.w != io.Discard {
+			l.formatRecords(b, l.formatter, true, records)
+			r.w, r.p, r.csv = l.w, takeBuffer(b), l.csvHeader()
+		}
+		if l.machine != nil {
+			l.formatRecords(b, JSONFormatter, false, l.machineRecords(records))
+			r.machine, r.mp = l.machine, takeBuffer(b)
+		}
+		priority := level >= ErrorLevel && level != noLevel
+		for _, tr := range tee {
+			q.push(tr, priority)
+		}
+		if r.w != nil || r.machine != nil {
+			q.push(r, priority)
+		}
+		return
+	}
+
+	if l.teeing() {
+		for _, r := range l.teeRecords(b, records) {
+			r.write()
+		}
+	} else if l.w != io.Discard {
+		l.formatRecords(b, l.formatter, true, records)
+		l.writeBuffer(l.w, b, l.csvHeader())
+	}
+	if l.machine != nil {
+		l.formatRecords(b, JSONFormatter, false, l.machineRecords(records))
+		l.writeBuffer(l.machine, b, nil)
+	}
+}
+
+// writeBuffer writes the buffer to w, serialized with the other writes of the
+// logger and its sub-loggers, and resets it. The CSV header of csv, if set,
+// goes before the first record.
+func (l *Logger) writeBuffer(w io.Writer, b *bytes.Buffer, csv *csvState) {
+	l.wmu.Lock()
+	defer l.wmu.Unlock()
+	p := b.Bytes()
+	if csv != nil {
+		p = csv.withHeader(p)
+	}
+	w.Write(p) //nolint: errcheck
+	b.Reset()
+}
+
+// formatRecords formats the records into the buffer using the given
+// formatter, applying the framing if frame is set.
+func (l *Logger) formatRecords(b *bytes.Buffer, f Formatter, frame bool, records [][]interface{}) {
+	for _, kvs := range records {
+		start := b.Len()
+		l.format(b, f, kvs)
+		if frame && f == JSONFormatter && l.jsonIndent != "" {
+			l.indentJSON(b, start)
+		}
+		if frame && f == JSONFormatter && l.jsonColors {
+			l.colorJSON(b, start)
+		}
+		if frame {
+			l.frame(b, start)
+		}
+	}
+}
+
+// takeBuffer returns a copy of the buffer and resets it.
+func takeBuffer(b *bytes.Buffer) []byte {
+	p := append([]byte(nil), b.Bytes()...)
+	b.Reset()
+	return p
+}
+
+// format formats the keyvals into the buffer using the given formatter.
+func (l *Logger) format(b *bytes.Buffer, f Formatter, kvs []interface{}) {
+	if l.callerLink != nil && f != TextFormatter {
+		kvs = unlinkCaller(kvs)
+	}
+	if f != JSONFormatter {
+		kvs = expandGroups(kvs)
+	}
+	if f != TextFormatter && f != JSONFormatter {
+		kvs = boxFields(kvs)
+	}
+	if l.flattenStructs && (f == TextFormatter || f == LogfmtFormatter) {
+		kvs = flattenStructs(kvs)
+	}
+	switch f {
 	case LogfmtFormatter:
-		l.logfmtFormatter(kvs...)
+		l.logfmtFormatter(b, kvs...)
 	case JSONFormatter:
-		l.jsonFormatter(kvs...)
+		l.jsonFormatter(b, kvs...)
+	case GELFFormatter:
+		l.gelfFormatter(b, kvs...)
+	case ECSFormatter:
+		l.ecsFormatter(b, kvs...)
+	case GCPFormatter:
+		l.gcpFormatter(b, kvs...)
+	case LogstashFormatter:
+		l.logstashFormatter(b, kvs...)
+	case MsgPackFormatter:
+		l.msgpackFormatter(b, kvs...)
+	case CSVFormatter:
+		l.csvFormatter(b, kvs...)
+	case CommonLogFormatter:
+		l.accessLogFormatter(b, false, kvs...)
+	case CombinedLogFormatter:
+		l.accessLogFormatter(b, true, kvs...)
 	default:
-		l.textFormatter(kvs...)
+		l.textFormatter(b, kvs...)
 	}
-
-	// WriteTo will reset the buffer
-	l.b.WriteTo(l.w) //nolint: errcheck
 }
 
 // Helper marks the calling function as a helper
@@ -216,6 +604,14 @@ func (l *Logger) SetReportTimestamp(report bool) {
 	l.reportTimestamp = report
 }
 
+// SetReportSchemaVersion sets whether the output schema version should be
+// reported.
+func (l *Logger) SetReportSchemaVersion(report bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.reportSchemaVersion = report
+}
+
 // SetReportCaller sets whether the caller location should be reported.
 func (l *Logger) SetReportCaller(report bool) {
 	l.mu.Lock()
@@ -251,10 +647,14 @@ func (l *Logger) SetPrefix(prefix string) {
 	l.prefix = prefix
 }
 
-// SetTimeFormat sets the time format.
+// SetTimeFormat sets the time format. An empty format restores
+// DefaultTimeFormat, and ParseTimeFormat returns the layouts of the presets.
 func (l *Logger) SetTimeFormat(format string) {
 	l.mu.Lock()
 	defer l.mu.Unlock()
+	if format == "" {
+		format = DefaultTimeFormat
+	}
 	l.timeFormat = format
 }
 
@@ -265,6 +665,15 @@ func (l *Logger) SetTimeFunction(f TimeFunction) {
 	l.timeFunc = f
 }
 
+// SetUTC sets whether the timestamps are converted to UTC before being
+// formatted, whatever the time function, so the records of hosts in different
+// time zones correlate easily.
+func (l *Logger) SetUTC(utc bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.utc = utc
+}
+
 // SetOutput sets the output destination.
 func (l *Logger) SetOutput(w io.Writer) {
 	l.mu.Lock()
@@ -273,18 +682,69 @@ func (l *Logger) SetOutput(w io.Writer) {
 		w = os.Stderr
 	}
 	l.w = w
-	var isDiscard uint32
-	if w == io.Discard {
-		isDiscard = 1
+	l.updateDiscard()
+	l.re = l.renderer(w)
+	l.teeRenderers = nil
+	if t, ok := w.(*TeeWriter); ok {
+		for _, w := range t.writers {
+			l.teeRenderers = append(l.teeRenderers, l.renderer(w))
+		}
+	}
+}
+
+// renderer returns the renderer of the output w.
+func (l *Logger) renderer(w io.Writer) *lipgloss.Renderer {
+	if l.colorProfile != nil {
+		// The renderer may be shared, use a dedicated one.
+		re := lipgloss.NewRenderer(w, termenv.WithColorCache(true))
+		re.SetColorProfile(*l.colorProfile)
+		if *l.colorProfile != termenv.Ascii {
+			// The profile is explicit, ignore legacy consoles.
+			_ = enableVirtualTerminal(w)
+		}
+		return re
+	}
+	if l.terminalCheck != nil {
+		return l.terminalRenderer(w)
 	}
-	atomic.StoreUint32(&l.isDiscard, isDiscard)
 	// Reuse cached renderers
 	if v, ok := registry.Load(w); ok {
-		l.re = v.(*lipgloss.Renderer)
-	} else {
-		l.re = lipgloss.NewRenderer(w, termenv.WithColorCache(true))
-		registry.Store(w, l.re)
+		return v.(*lipgloss.Renderer)
+	}
+	opts := []termenv.OutputOption{termenv.WithColorCache(true)}
+	if colorForced() {
+		// Detect the profile from the environment as for a terminal.
+		opts = append(opts, termenv.WithTTY(true))
+	}
+	re := lipgloss.NewRenderer(w, opts...)
+	if err := enableVirtualTerminal(w); err != nil {
+		// A legacy Windows console would print the escape sequences
+		// as is, render without styles instead.
+		re.SetColorProfile(termenv.Ascii)
 	}
+	registry.Store(w, re)
+	return re
+}
+
+// SetMachineOutput sets a second output receiving every record as JSON, in
+// addition to the output and formatter of the logger. This lets a single call
+// write styled text for humans to a terminal and canonical JSON for machines
+// to a file or a pipe. A nil w disables the machine output.
+func (l *Logger) SetMachineOutput(w io.Writer) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.machine = w
+	l.updateDiscard()
+}
+
+// updateDiscard updates whether the logger discards everything. It must be
+// called with l.mu held.
+func (l *Logger) updateDiscard() {
+	var isDiscard uint32
+	if l.w == io.Discard && (l.machine == nil || l.machine == io.Discard) {
+		isDiscard = 1
+	}
+	atomic.StoreUint32(&l.isDiscard, isDiscard)
 }
 
 // SetFormatter sets the formatter.
@@ -301,6 +761,16 @@ func (l *Logger) SetCallerFormatter(f CallerFormatter) {
 	l.callerFormatter = f
 }
 
+// WithCallerFormatter returns a new logger using the given caller formatter.
+func (l *Logger) WithCallerFormatter(f CallerFormatter) *Logger {
+	sl := l.With()
+	sl.callerFormatter = f
+	if f == nil {
+		sl.callerFormatter = ShortCallerFormatter
+	}
+	return sl
+}
+
 // SetCallerOffset sets the caller offset.
 func (l *Logger) SetCallerOffset(offset int) {
 	l.mu.Lock()
@@ -308,12 +778,52 @@ func (l *Logger) SetCallerOffset(offset int) {
 	l.callerOffset = offset
 }
 
+// WithCallerSkip returns a new logger skipping n more stack frames when
+// reporting the caller, so libraries wrapping the logger in their own helpers
+// attribute the records to their callers.
+func (l *Logger) WithCallerSkip(n int) *Logger {
+	sl := l.With()
+	sl.callerOffset += n
+	return sl
+}
+
 // SetColorProfile force sets the underlying Lip Gloss renderer color profile
 // for the TextFormatter.
 func (l *Logger) SetColorProfile(profile termenv.Profile) {
 	l.re.SetColorProfile(profile)
 }
 
+// WithStyleFunc returns a new logger styling the values of the TextFormatter
+// with fn, so they can be colored conditionally.
+func (l *Logger) WithStyleFunc(fn StyleFunc) *Logger {
+	sl := l.With()
+	sl.styleFunc = fn
+	return sl
+}
+
+// WithColorProfile returns a new logger rendering its styles with the given
+// color profile regardless of the output detection, e.g. to keep colors when
+// piping through tools supporting them. Unlike SetColorProfile, it doesn't
+// affect the other loggers sharing the output.
+func (l *Logger) WithColorProfile(profile termenv.Profile) *Logger {
+	sl := l.With()
+	sl.colorProfile = &profile
+	sl.SetOutput(sl.w)
+	return sl
+}
+
+// HasDarkBackground reports whether the output terminal has a dark background,
+// which selects the dark variant of the adaptive colors of the styles.
+func (l *Logger) HasDarkBackground() bool {
+	return l.re.HasDarkBackground()
+}
+
+// SetHasDarkBackground overrides the background detection of the output
+// terminal.
+func (l *Logger) SetHasDarkBackground(dark bool) {
+	l.re.SetHasDarkBackground(dark)
+}
+
 // SetStyles sets the logger styles for the TextFormatter.
 func (l *Logger) SetStyles(s *Styles) {
 	if s == nil {
@@ -331,7 +841,6 @@ func (l *Logger) With(keyvals ...interface{}) *Logger {
 	sl := *l
 	st = *l.styles
 	l.mu.Unlock()
-	sl.b = bytes.Buffer{}
 	sl.mu = &sync.RWMutex{}
 	sl.helpers = &sync.Map{}
 	sl.fields = append(make([]interface{}, 0, len(l.fields)+len(keyvals)), l.fields...)
@@ -340,6 +849,21 @@ func (l *Logger) With(keyvals ...interface{}) *Logger {
 	return &sl
 }
 
+// WithFields returns a new logger with the given fields added, in sorted key
+// order so the output is deterministic.
+func (l *Logger) WithFields(fields map[string]interface{}) *Logger {
+	keys := make([]string, 0, len(fields))
+	for k := range fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	keyvals := make([]interface{}, 0, len(fields)*2)
+	for _, k := range keys {
+		keyvals = append(keyvals, k, fields[k])
+	}
+	return l.With(keyvals...)
+}
+
 // WithPrefix returns a new logger with the given prefix.
 func (l *Logger) WithPrefix(prefix string) *Logger {
 	sl := l.With()
@@ -349,6 +873,9 @@ func (l *Logger) WithPrefix(prefix string) *Logger {
 
 // Debug prints a debug message.
 func (l *Logger) Debug(msg interface{}, keyvals ...interface{}) {
+	if !debugEnabled {
+		return
+	}
 	l.Log(DebugLevel, msg, keyvals...)
 }
 
@@ -370,7 +897,7 @@ func (l *Logger) Error(msg interface{}, keyvals ...interface{}) {
 // Fatal prints a fatal message and exits.
 func (l *Logger) Fatal(msg interface{}, keyvals ...interface{}) {
 	l.Log(FatalLevel, msg, keyvals...)
-	os.Exit(1)
+	l.exit()
 }
 
 // Print prints a message with no level.
@@ -380,31 +907,48 @@ func (l *Logger) Print(msg interface{}, keyvals ...interface{}) {
 
 // Debugf prints a debug message with formatting.
 func (l *Logger) Debugf(format string, args ...interface{}) {
+	if !l.enabled(DebugLevel) {
+		return
+	}
 	l.Log(DebugLevel, fmt.Sprintf(format, args...))
 }
 
 // Infof prints an info message with formatting.
 func (l *Logger) Infof(format string, args ...interface{}) {
+	if !l.enabled(InfoLevel) {
+		return
+	}
 	l.Log(InfoLevel, fmt.Sprintf(format, args...))
 }
 
 // Warnf prints a warning message with formatting.
 func (l *Logger) Warnf(format string, args ...interface{}) {
+	if !l.enabled(WarnLevel) {
+		return
+	}
 	l.Log(WarnLevel, fmt.Sprintf(format, args...))
 }
 
 // Errorf prints an error message with formatting.
 func (l *Logger) Errorf(format string, args ...interface{}) {
+	if !l.enabled(ErrorLevel) {
+		return
+	}
 	l.Log(ErrorLevel, fmt.Sprintf(format, args...))
 }
 
 // Fatalf prints a fatal message with formatting and exits.
 func (l *Logger) Fatalf(format string, args ...interface{}) {
-	l.Log(FatalLevel, fmt.Sprintf(format, args...))
-	os.Exit(1)
+	if l.enabled(FatalLevel) {
+		l.Log(FatalLevel, fmt.Sprintf(format, args...))
+	}
+	l.exit()
 }
 
 // Printf prints a message with no level and formatting.
 func (l *Logger) Printf(format string, args ...interface{}) {
+	if !l.enabled(noLevel) {
+		return
+	}
 	l.Log(noLevel, fmt.Sprintf(format, args...))
 }
@@ -274,3 +274,8 @@ func TestSlogAttr(t *testing.T) {
 		})
 	}
 }
+
+// emptyGroupValuer resolves to an empty group.
+type emptyGroupValuer struct{}
+
+func (emptyGroupValuer) LogValue() slog.Value { return slog.GroupValue() }
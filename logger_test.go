@@ -2,6 +2,7 @@ package plog
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
 	"io"
 	"sync"
@@ -285,3 +286,100 @@ func TestCustomLevel(t *testing.T) {
 	l.Logf(level500, "foo")
 	assert.Equal(t, "foo\n", buf.String())
 }
+
+func TestWithFields(t *testing.T) {
+	var buf bytes.Buffer
+	l := NewWithOptions(&buf, Options{Formatter: LogfmtFormatter})
+	fields := map[string]interface{}{"zeta": 3, "alpha": 1, "mid": "x"}
+	for i := 0; i < 5; i++ {
+		buf.Reset()
+		l.WithFields(fields).Print("hello", "extra", true)
+		assert.Equal(t, "msg=hello alpha=1 mid=x zeta=3 extra=true\n", buf.String())
+	}
+}
+
+func TestAllocs(t *testing.T) {
+	l := NewWithOptions(discardWriter{}, Options{Level: InfoLevel})
+	err := errors.New("oops")
+	allocs := testing.AllocsPerRun(100, func() {
+		l.Debug("hello", "key", "value", "n", 42, "err", err)
+		l.Debugf("hello %s", "world")
+	})
+	assert.Zero(t, allocs, "disabled")
+
+	// The bounds track regressions of the enabled path, including the
+	// boxing of the fields by the caller.
+	bounds := map[Formatter]float64{TextFormatter: 8, JSONFormatter: 5, LogfmtFormatter: 11}
+	for f, bound := range bounds {
+		l.SetFormatter(f)
+		allocs := testing.AllocsPerRun(100, func() {
+			l.Info("hello", Str("key", "value"), Int("n", 42), Bool("ok", true))
+		})
+		assert.LessOrEqual(t, allocs, bound, "formatter %d", f)
+	}
+}
+
+func BenchmarkDisabled(b *testing.B) {
+	l := NewWithOptions(discardWriter{}, Options{Level: InfoLevel})
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		l.Debug("hello", "key", "value", "n", 42)
+	}
+}
+
+func BenchmarkEnabled(b *testing.B) {
+	formatters := map[string]Formatter{"text": TextFormatter, "json": JSONFormatter, "logfmt": LogfmtFormatter}
+	for name, f := range formatters {
+		b.Run(name, func(b *testing.B) {
+			l := NewWithOptions(discardWriter{}, Options{Formatter: f, ReportTimestamp: true})
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				l.Info("hello", Str("key", "value"), Int("n", i), Bool("ok", true))
+			}
+		})
+	}
+}
+
+func TestConcurrentWrites(t *testing.T) {
+	var buf bytes.Buffer
+	l := NewWithOptions(&buf, Options{Formatter: LogfmtFormatter})
+	sl := l.With("sub", true)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				l.Print("hello", "i", i, "j", j)
+				sl.Print("hello", "i", i, "j", j)
+			}
+		}(i)
+	}
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		// The formatting settings can change while records are formatted.
+		for j := 0; j < 100; j++ {
+			l.SetFraming(FramingCRLF)
+			l.SetFraming(FramingNewline)
+		}
+	}()
+	wg.Wait()
+
+	lines := bytes.Split(bytes.TrimSuffix(buf.Bytes(), []byte{'\n'}), []byte{'\n'})
+	assert.Len(t, lines, 8*100*2)
+	for _, line := range lines {
+		assert.Contains(t, string(line), "msg=hello")
+	}
+}
+
+func BenchmarkParallel(b *testing.B) {
+	l := NewWithOptions(discardWriter{}, Options{Formatter: JSONFormatter, ReportTimestamp: true})
+	b.ReportAllocs()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			l.Info("hello", Str("key", "value"), Int("n", 42), Bool("ok", true))
+		}
+	})
+}
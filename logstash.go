@@ -0,0 +1,71 @@
+package plog
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"sort"
+	"time"
+)
+
+func (l *Logger) logstashFormatter(b *bytes.Buffer, keyvals ...interface{}) {
+	jw := &jsonWriter{w: b}
+	jw.start()
+	for i := 0; i < len(keyvals); i += 2 {
+		switch keyvals[i] {
+		case TimestampKey:
+			if t, ok := keyvals[i+1].(time.Time); ok {
+				jw.objectItem("@timestamp", t.UTC().Format(time.RFC3339Nano))
+			}
+		}
+	}
+	jw.objectItem("@version", "1")
+
+	for i := 0; i < len(keyvals); i += 2 {
+		switch keyvals[i] {
+		case TimestampKey:
+		case LevelKey:
+			if level, ok := keyvals[i+1].(Level); ok {
+				jw.objectItem("level", level.String())
+			}
+		case CallerKey:
+			if caller, ok := keyvals[i+1].(string); ok {
+				jw.objectItem("caller", caller)
+			}
+		case PrefixKey:
+			if prefix, ok := keyvals[i+1].(string); ok {
+				jw.objectItem("logger_name", prefix)
+			}
+		case MessageKey:
+			jw.objectItem("message", fmt.Sprint(keyvals[i+1]))
+		default:
+			l.logstashField(jw, fmt.Sprint(keyvals[i]), keyvals[i+1])
+		}
+	}
+
+	jw.end()
+	b.WriteRune('\n')
+}
+
+// logstashField writes the field, flattening maps into dotted keys.
+func (l *Logger) logstashField(jw *jsonWriter, key string, value interface{}) {
+	rv := reflect.ValueOf(value)
+	if rv.Kind() != reflect.Map || rv.Len() == 0 {
+		l.jsonFormatterItem(jw, key, value)
+		return
+	}
+
+	keys := rv.MapKeys()
+	names := make([]string, len(keys))
+	for i, k := range keys {
+		names[i] = fmt.Sprint(k.Interface())
+	}
+	idx := make([]int, len(keys))
+	for i := range idx {
+		idx[i] = i
+	}
+	sort.Slice(idx, func(a, b int) bool { return names[idx[a]] < names[idx[b]] })
+	for _, i := range idx {
+		l.logstashField(jw, key+"."+names[i], rv.MapIndex(keys[i]).Interface())
+	}
+}
@@ -0,0 +1,54 @@
+package plog
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLogstash(t *testing.T) {
+	var buf bytes.Buffer
+	l := NewWithOptions(&buf, Options{
+		Formatter:       LogstashFormatter,
+		ReportTimestamp: true,
+		TimeFunction: func(time.Time) time.Time {
+			return time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+		},
+		Prefix: "oven",
+	})
+
+	cases := []struct {
+		name     string
+		expected string
+		kvs      []interface{}
+	}{
+		{
+			name: "simple",
+			expected: `{"@timestamp":"2024-01-01T12:00:00Z","@version":"1","level":"info",` +
+				`"logger_name":"oven","message":"baking"}` + "\n",
+		},
+		{
+			name: "flattened fields",
+			expected: `{"@timestamp":"2024-01-01T12:00:00Z","@version":"1","level":"info",` +
+				`"logger_name":"oven","message":"baking","temp":180,` +
+				`"http.request.method":"GET","http.status":200,"empty":{}}` + "\n",
+			kvs: []interface{}{
+				"temp", 180,
+				"http", map[string]interface{}{
+					"status":  200,
+					"request": map[string]string{"method": "GET"},
+				},
+				"empty", map[string]string{},
+			},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			buf.Reset()
+			l.Info("baking", c.kvs...)
+			require.Equal(t, c.expected, buf.String())
+		})
+	}
+}
@@ -0,0 +1,50 @@
+package plog
+
+// maxLogValuerDepth bounds the resolution of LogValuer values returning
+// other LogValuer values.
+const maxLogValuerDepth = 100
+
+// LogValuer is implemented by types that control their own log
+// representation. LogValue is only called when a record is emitted at an
+// enabled level, so it can defer expensive computations:
+//
+//	type user struct{ id int }
+//
+//	func (u user) LogValue() interface{} { return map[string]int{"id": u.id} }
+//
+// The returned value is formatted as usual. It's resolved again if it's a
+// LogValuer itself.
+type LogValuer interface {
+	LogValue() interface{}
+}
+
+// Lazy is a value computed only when a record is emitted at an enabled level,
+// so expensive debug-only values cost nothing when the level is disabled:
+//
+//	logger.Debug("state", "dump", log.Lazy(func() interface{} { return s.Dump() }))
+//
+// Values of type func() interface{} are treated the same.
+type Lazy func() interface{}
+
+// LogValue implements LogValuer.
+func (f Lazy) LogValue() interface{} {
+	return f()
+}
+
+// resolveLogValuers replaces, in place, the LogValuer and func() interface{}
+// values of keyvals with their value.
+func resolveLogValuers(keyvals []interface{}) {
+	for i := 1; i < len(keyvals); i += 2 {
+		for depth := 0; depth < maxLogValuerDepth; depth++ {
+			switch v := keyvals[i].(type) {
+			case LogValuer:
+				keyvals[i] = v.LogValue()
+				continue
+			case func() interface{}:
+				keyvals[i] = v()
+				continue
+			}
+			break
+		}
+	}
+}
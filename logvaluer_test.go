@@ -0,0 +1,51 @@
+package plog
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type countingValuer struct {
+	calls *int
+	value interface{}
+}
+
+func (v countingValuer) LogValue() interface{} {
+	*v.calls++
+	return v.value
+}
+
+func TestLogValuer(t *testing.T) {
+	var buf bytes.Buffer
+	var calls int
+	l := NewWithOptions(&buf, Options{Formatter: JSONFormatter, Level: InfoLevel})
+
+	l.Debug("hidden", "v", countingValuer{&calls, "expensive"})
+	require.Zero(t, calls)
+	require.Empty(t, buf.String())
+
+	inner := countingValuer{&calls, map[string]int{"id": 1}}
+	sl := l.With("user", countingValuer{&calls, inner})
+	sl.Info("visible", "v", countingValuer{&calls, "expensive"})
+	require.Equal(t, 3, calls)
+	require.Equal(t, `{"level":"info","msg":"visible","user":{"id":1},"v":"expensive"}`+"\n", buf.String())
+}
+
+func TestLazy(t *testing.T) {
+	var buf bytes.Buffer
+	var calls int
+	expensive := func() interface{} {
+		calls++
+		return calls
+	}
+	l := NewWithOptions(&buf, Options{Formatter: JSONFormatter, Level: InfoLevel})
+
+	l.Debug("hidden", "a", Lazy(expensive), "b", expensive)
+	require.Zero(t, calls)
+
+	l.Info("visible", "a", Lazy(expensive), "b", expensive)
+	require.Equal(t, 2, calls)
+	require.Equal(t, `{"level":"info","msg":"visible","a":1,"b":2}`+"\n", buf.String())
+}
@@ -0,0 +1,61 @@
+package plog
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMachineOutput(t *testing.T) {
+	var human, machine bytes.Buffer
+	l := NewWithOptions(&human, Options{
+		ReportTimestamp: true,
+		TimeFunction:    func(time.Time) time.Time { return time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC) },
+		Prefix:          "oven",
+		Fields:          []interface{}{"shift", "night"},
+		MachineOutput:   &machine,
+	})
+	kvs := []interface{}{"temp", 180, "ok", true, "err", errors.New("too_hot")}
+	l.Print("baking", kvs...)
+
+	require.Equal(t, "2024/01/01 00:00:00 oven: baking shift=night temp=180 ok=true err=too_hot\n", human.String())
+	require.Equal(t, `{"time":"2024/01/01 00:00:00","prefix":"oven","msg":"baking",`+
+		`"shift":"night","temp":180,"ok":true,"err":"too_hot"}`+"\n", machine.String())
+
+	// Every field of the human output is in the machine output, with the
+	// same value.
+	var m map[string]interface{}
+	require.NoError(t, json.Unmarshal(machine.Bytes(), &m))
+	fields := strings.Fields(strings.TrimSpace(human.String()))
+	require.Equal(t, m[TimestampKey], fields[0]+" "+fields[1])
+	require.Equal(t, m[PrefixKey].(string)+":", fields[2])
+	require.Equal(t, m[MessageKey], fields[3])
+	for _, f := range fields[4:] {
+		key, val, ok := strings.Cut(f, "=")
+		require.True(t, ok)
+		require.Contains(t, m, key)
+		b, err := json.Marshal(m[key])
+		require.NoError(t, err)
+		require.Equal(t, strings.Trim(string(b), `"`), val)
+	}
+	require.Len(t, m, len(fields)-1)
+}
+
+func TestMachineOutputDiscard(t *testing.T) {
+	var machine bytes.Buffer
+	l := NewWithOptions(io.Discard, Options{MachineOutput: &machine, Formatter: LogfmtFormatter})
+	l.Info("hello")
+	require.Equal(t, `{"level":"info","msg":"hello"}`+"\n", machine.String())
+
+	machine.Reset()
+	l.SetMachineOutput(nil)
+	l.Info("hello")
+	require.Empty(t, machine.String())
+	require.Equal(t, uint32(1), l.isDiscard)
+}
@@ -0,0 +1,24 @@
+package plog
+
+// KeyDroppedFields is the key of the number of keyvals pairs dropped from a
+// record by WithMaxFields.
+const KeyDroppedFields = "_dropped_fields"
+
+// WithMaxFields returns a new logger keeping at most n keyvals pairs per
+// record, the logger fields first. The excess pairs are dropped and counted
+// under KeyDroppedFields, protecting against code paths that accidentally
+// attach unbounded metadata. A n of zero or less disables the limit.
+func (l *Logger) WithMaxFields(n int) *Logger {
+	sl := l.With()
+	sl.maxFields = n
+	return sl
+}
+
+// limitFields returns keyvals with at most the maximum number of fields.
+func (l *Logger) limitFields(keyvals []interface{}) []interface{} {
+	if l.maxFields <= 0 || len(keyvals) <= l.maxFields*2 {
+		return keyvals
+	}
+	dropped := len(keyvals)/2 - l.maxFields
+	return append(keyvals[:l.maxFields*2], KeyDroppedFields, dropped)
+}
@@ -0,0 +1,20 @@
+package plog
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMaxFields(t *testing.T) {
+	var buf bytes.Buffer
+	l := NewWithOptions(&buf, Options{Formatter: LogfmtFormatter}).WithMaxFields(3)
+	sl := l.With("app", "bakery")
+	sl.Print("meta", "a", 1, "b", 2, "c", 3, "d", 4)
+	require.Equal(t, "msg=meta app=bakery a=1 b=2 _dropped_fields=2\n", buf.String())
+
+	buf.Reset()
+	sl.Print("fine", "a", 1, "b", 2)
+	require.Equal(t, "msg=fine app=bakery a=1 b=2\n", buf.String())
+}
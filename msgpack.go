@@ -0,0 +1,240 @@
+package plog
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"time"
+)
+
+// msgpackTimestampExt is the MessagePack extension type of timestamps.
+const msgpackTimestampExt = -1
+
+func (l *Logger) msgpackFormatter(b *bytes.Buffer, keyvals ...interface{}) {
+	var (
+		fields []byte
+		n      int
+	)
+	for i := 0; i+1 < len(keyvals); i += 2 {
+		key, value := keyvals[i], keyvals[i+1]
+		switch key {
+		case TimestampKey:
+			t, ok := value.(time.Time)
+			if !ok {
+				continue
+			}
+			fields = msgpackAppendString(fields, TimestampKey)
+			fields = msgpackAppendTime(fields, t)
+		case LevelKey:
+			level, ok := value.(Level)
+			if !ok {
+				continue
+			}
+			fields = msgpackAppendString(fields, LevelKey)
+			fields = msgpackAppendString(fields, level.String())
+		case MessageKey:
+			fields = msgpackAppendString(fields, MessageKey)
+			fields = msgpackAppendString(fields, fmt.Sprint(value))
+		default:
+			fields = msgpackAppendString(fields, msgpackKey(key))
+			fields = msgpackAppendValue(fields, value)
+		}
+		n++
+	}
+
+	b.Write(msgpackAppendMapHeader(nil, n)) //nolint: errcheck
+	b.Write(fields)                         //nolint: errcheck
+}
+
+func msgpackKey(key interface{}) string {
+	switch k := key.(type) {
+	case fmt.Stringer:
+		return k.String()
+	case error:
+		return k.Error()
+	default:
+		return fmt.Sprint(k)
+	}
+}
+
+func msgpackAppendValue(b []byte, value interface{}) []byte {
+	switch v := value.(type) {
+	case nil:
+		return append(b, 0xc0)
+	case bool:
+		if v {
+			return append(b, 0xc3)
+		}
+		return append(b, 0xc2)
+	case string:
+		return msgpackAppendString(b, v)
+	case []byte:
+		return msgpackAppendBytes(b, v)
+	case int:
+		return msgpackAppendInt(b, int64(v))
+	case int8:
+		return msgpackAppendInt(b, int64(v))
+	case int16:
+		return msgpackAppendInt(b, int64(v))
+	case int32:
+		return msgpackAppendInt(b, int64(v))
+	case int64:
+		return msgpackAppendInt(b, v)
+	case uint:
+		return msgpackAppendUint(b, uint64(v))
+	case uint8:
+		return msgpackAppendUint(b, uint64(v))
+	case uint16:
+		return msgpackAppendUint(b, uint64(v))
+	case uint32:
+		return msgpackAppendUint(b, uint64(v))
+	case uint64:
+		return msgpackAppendUint(b, v)
+	case float32:
+		b = append(b, 0xca)
+		return binary.BigEndian.AppendUint32(b, math.Float32bits(v))
+	case float64:
+		b = append(b, 0xcb)
+		return binary.BigEndian.AppendUint64(b, math.Float64bits(v))
+	case time.Time:
+		return msgpackAppendTime(b, v)
+	case time.Duration:
+		return msgpackAppendInt(b, int64(v))
+	case error:
+		return msgpackAppendString(b, v.Error())
+	case fmt.Stringer:
+		return msgpackAppendString(b, v.String())
+	}
+
+	rv := reflect.ValueOf(value)
+	switch rv.Kind() {
+	case reflect.Slice, reflect.Array:
+		b = msgpackAppendArrayHeader(b, rv.Len())
+		for i := 0; i < rv.Len(); i++ {
+			b = msgpackAppendValue(b, rv.Index(i).Interface())
+		}
+		return b
+	case reflect.Map:
+		keys := rv.MapKeys()
+		names := make([]string, len(keys))
+		for i, k := range keys {
+			names[i] = msgpackKey(k.Interface())
+		}
+		idx := make([]int, len(keys))
+		for i := range idx {
+			idx[i] = i
+		}
+		sort.Slice(idx, func(a, b int) bool { return names[idx[a]] < names[idx[b]] })
+		b = msgpackAppendMapHeader(b, len(keys))
+		for _, i := range idx {
+			b = msgpackAppendString(b, names[i])
+			b = msgpackAppendValue(b, rv.MapIndex(keys[i]).Interface())
+		}
+		return b
+	case reflect.Pointer, reflect.Interface:
+		if rv.IsNil() {
+			return append(b, 0xc0)
+		}
+		return msgpackAppendValue(b, rv.Elem().Interface())
+	case reflect.Struct:
+		// Encode structs the way the JSON formatter does, honoring json tags
+		// and json.Marshaler.
+		var v interface{}
+		if data, err := json.Marshal(value); err == nil && json.Unmarshal(data, &v) == nil {
+			return msgpackAppendValue(b, v)
+		}
+	}
+	return msgpackAppendString(b, fmt.Sprintf("%+v", value))
+}
+
+func msgpackAppendInt(b []byte, v int64) []byte {
+	switch {
+	case v >= 0:
+		return msgpackAppendUint(b, uint64(v))
+	case v >= -32:
+		return append(b, byte(v))
+	case v >= math.MinInt8:
+		return append(b, 0xd0, byte(v))
+	case v >= math.MinInt16:
+		return binary.BigEndian.AppendUint16(append(b, 0xd1), uint16(v))
+	case v >= math.MinInt32:
+		return binary.BigEndian.AppendUint32(append(b, 0xd2), uint32(v))
+	default:
+		return binary.BigEndian.AppendUint64(append(b, 0xd3), uint64(v))
+	}
+}
+
+func msgpackAppendUint(b []byte, v uint64) []byte {
+	switch {
+	case v <= 0x7f:
+		return append(b, byte(v))
+	case v <= math.MaxUint8:
+		return append(b, 0xcc, byte(v))
+	case v <= math.MaxUint16:
+		return binary.BigEndian.AppendUint16(append(b, 0xcd), uint16(v))
+	case v <= math.MaxUint32:
+		return binary.BigEndian.AppendUint32(append(b, 0xce), uint32(v))
+	default:
+		return binary.BigEndian.AppendUint64(append(b, 0xcf), v)
+	}
+}
+
+func msgpackAppendString(b []byte, s string) []byte {
+	switch n := len(s); {
+	case n <= 31:
+		b = append(b, 0xa0|byte(n))
+	case n <= math.MaxUint8:
+		b = append(b, 0xd9, byte(n))
+	case n <= math.MaxUint16:
+		b = binary.BigEndian.AppendUint16(append(b, 0xda), uint16(n))
+	default:
+		b = binary.BigEndian.AppendUint32(append(b, 0xdb), uint32(n))
+	}
+	return append(b, s...)
+}
+
+func msgpackAppendBytes(b []byte, p []byte) []byte {
+	switch n := len(p); {
+	case n <= math.MaxUint8:
+		b = append(b, 0xc4, byte(n))
+	case n <= math.MaxUint16:
+		b = binary.BigEndian.AppendUint16(append(b, 0xc5), uint16(n))
+	default:
+		b = binary.BigEndian.AppendUint32(append(b, 0xc6), uint32(n))
+	}
+	return append(b, p...)
+}
+
+func msgpackAppendArrayHeader(b []byte, n int) []byte {
+	switch {
+	case n <= 15:
+		return append(b, 0x90|byte(n))
+	case n <= math.MaxUint16:
+		return binary.BigEndian.AppendUint16(append(b, 0xdc), uint16(n))
+	default:
+		return binary.BigEndian.AppendUint32(append(b, 0xdd), uint32(n))
+	}
+}
+
+func msgpackAppendMapHeader(b []byte, n int) []byte {
+	switch {
+	case n <= 15:
+		return append(b, 0x80|byte(n))
+	case n <= math.MaxUint16:
+		return binary.BigEndian.AppendUint16(append(b, 0xde), uint16(n))
+	default:
+		return binary.BigEndian.AppendUint32(append(b, 0xdf), uint32(n))
+	}
+}
+
+// msgpackAppendTime appends t as a 96-bit MessagePack timestamp, which keeps
+// the nanoseconds and any date.
+func msgpackAppendTime(b []byte, t time.Time) []byte {
+	b = append(b, 0xc7, 12, byte(msgpackTimestampExt&0xff))
+	b = binary.BigEndian.AppendUint32(b, uint32(t.Nanosecond()))
+	return binary.BigEndian.AppendUint64(b, uint64(t.Unix()))
+}
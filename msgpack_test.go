@@ -0,0 +1,60 @@
+package plog
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/Malanris/plog/parse"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMsgPack(t *testing.T) {
+	ts := time.Date(2024, 1, 1, 12, 0, 0, 123456789, time.UTC)
+	var buf bytes.Buffer
+	l := NewWithOptions(&buf, Options{
+		Formatter:       MsgPackFormatter,
+		ReportTimestamp: true,
+		TimeFunction:    func(time.Time) time.Time { return ts },
+		Prefix:          "oven",
+	})
+	l.Info("hello", "int", -1000, "uint", uint64(1<<40), "float", 1.5, "bool", true,
+		"nil", nil, "bytes", []byte{1, 2}, "dur", time.Second, "err", errors.New("boom"),
+		"list", []string{"a", "b"}, "map", map[string]int{"x": 1},
+		"struct", struct {
+			Name string `json:"name"`
+		}{"cake"})
+	l.Print("big", "long", string(bytes.Repeat([]byte("x"), 300)))
+
+	dec := parse.NewMsgPackDecoder(&buf)
+	rec, err := dec.Decode()
+	require.NoError(t, err)
+	require.True(t, ts.Equal(rec["time"].(time.Time)))
+	delete(rec, "time")
+	require.Equal(t, map[string]interface{}{
+		"level":  "info",
+		"prefix": "oven",
+		"msg":    "hello",
+		"int":    int64(-1000),
+		"uint":   uint64(1 << 40),
+		"float":  1.5,
+		"bool":   true,
+		"nil":    nil,
+		"bytes":  []byte{1, 2},
+		"dur":    uint64(time.Second),
+		"err":    "boom",
+		"list":   []interface{}{"a", "b"},
+		"map":    map[string]interface{}{"x": int64(1)},
+		"struct": map[string]interface{}{"name": "cake"},
+	}, rec)
+
+	rec, err = dec.Decode()
+	require.NoError(t, err)
+	require.Equal(t, 300, len(rec["long"].(string)))
+	require.NotContains(t, rec, "level")
+
+	_, err = dec.Decode()
+	require.Equal(t, io.EOF, err)
+}
@@ -0,0 +1,102 @@
+package plog
+
+import "strings"
+
+// SetJSONNestedKeys sets whether the JSONFormatter nests dotted keys into
+// objects, e.g. "http.status" and "http.method" render as
+// {"http":{"status":200,"method":"GET"}}, as expected by ingestion systems
+// like Elasticsearch and Datadog. Keys sharing a prefix are merged into the
+// same object, along with groups of the same name, at the position of the
+// first one. When a key is both a value and an object, the last one wins.
+func (l *Logger) SetJSONNestedKeys(nested bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.jsonNested = nested
+}
+
+// nestNode is an object built from dotted keys, keeping the key order.
+type nestNode struct {
+	keys []string
+	vals map[string]interface{}
+}
+
+func newNestNode() *nestNode {
+	return &nestNode{vals: map[string]interface{}{}}
+}
+
+func (n *nestNode) set(key string, v interface{}) {
+	if _, ok := n.vals[key]; !ok {
+		n.keys = append(n.keys, key)
+	}
+	n.vals[key] = v
+}
+
+// child returns the object under key, replacing a value if needed.
+func (n *nestNode) child(key string) *nestNode {
+	if c, ok := n.vals[key].(*nestNode); ok {
+		return c
+	}
+	c := newNestNode()
+	n.set(key, c)
+	return c
+}
+
+func (n *nestNode) insert(path []string, v interface{}) {
+	for len(path) > 1 {
+		n = n.child(path[0])
+		path = path[1:]
+	}
+	if g, ok := v.(groupValue); ok {
+		c := n.child(path[0])
+		for i := 0; i+1 < len(g); i += 2 {
+			key, _ := g[i].(string)
+			c.insert(strings.Split(key, "."), g[i+1])
+		}
+		return
+	}
+	n.set(path[0], v)
+}
+
+func (n *nestNode) keyvals() []interface{} {
+	kvs := make([]interface{}, 0, len(n.keys)*2)
+	for _, k := range n.keys {
+		v := n.vals[k]
+		if c, ok := v.(*nestNode); ok {
+			v = groupValue(c.keyvals())
+		}
+		kvs = append(kvs, k, v)
+	}
+	return kvs
+}
+
+// nestKeys returns keyvals with their dotted keys nested into groups. It
+// returns keyvals as is if it has no dotted keys or groups.
+func nestKeys(keyvals []interface{}) []interface{} {
+	nest := false
+	for i := 0; i+1 < len(keyvals); i += 2 {
+		if k, ok := keyvals[i].(string); ok && strings.Contains(k, ".") {
+			nest = true
+			break
+		}
+		if _, ok := keyvals[i+1].(groupValue); ok {
+			nest = true
+			break
+		}
+	}
+	if !nest {
+		return keyvals
+	}
+
+	root := newNestNode()
+	for i := 0; i+1 < len(keyvals); i += 2 {
+		key, ok := keyvals[i].(string)
+		if !ok {
+			// Keys that aren't strings are kept as is, under their
+			// text form.
+			root.set(jsonKey(keyvals[i]), keyvals[i+1])
+			continue
+		}
+		root.insert(strings.Split(key, "."), keyvals[i+1])
+	}
+	return root.keyvals()
+}
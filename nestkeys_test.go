@@ -0,0 +1,27 @@
+package plog
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestJSONNestedKeys(t *testing.T) {
+	var buf bytes.Buffer
+	l := NewWithOptions(&buf, Options{Formatter: JSONFormatter, JSONNestedKeys: true})
+	l.Print("request",
+		"http.status", 200,
+		"user", "bob",
+		"http.request.method", "GET",
+		Group("http", "version", "1.1"),
+		"a", 1, "a.b", 2,
+	)
+	require.Equal(t, `{"msg":"request","http":{"status":200,"request":{"method":"GET"},"version":"1.1"},`+
+		`"user":"bob","a":{"b":2}}`+"\n", buf.String())
+
+	buf.Reset()
+	l.SetJSONNestedKeys(false)
+	l.Print("request", "http.status", 200)
+	require.Equal(t, `{"msg":"request","http.status":200}`+"\n", buf.String())
+}
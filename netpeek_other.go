@@ -0,0 +1,11 @@
+//go:build !unix
+
+package plog
+
+import "net"
+
+// connClosed can't tell whether the remote closed conn outside of Unix, where
+// the socket can be peeked at without consuming bytes, so it reports false.
+func connClosed(net.Conn) bool {
+	return false
+}
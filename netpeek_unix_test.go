@@ -0,0 +1,50 @@
+//go:build unix
+
+package plog
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestConnClosed(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer ln.Close() //nolint: errcheck
+
+	accepted := make(chan net.Conn)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			close(accepted)
+			return
+		}
+		accepted <- conn
+	}()
+	conn, err := net.Dial("tcp", ln.Addr().String())
+	require.NoError(t, err)
+	defer conn.Close() //nolint: errcheck
+	remote := <-accepted
+	require.NotNil(t, remote)
+
+	require.False(t, connClosed(conn))
+
+	// Peeking leaves what the remote sent to be read.
+	_, err = remote.Write([]byte("x"))
+	require.NoError(t, err)
+	require.Eventually(t, func() bool {
+		return !connClosed(conn)
+	}, time.Second, time.Millisecond)
+	b := make([]byte, 1)
+	_, err = conn.Read(b)
+	require.NoError(t, err)
+	require.Equal(t, "x", string(b))
+
+	require.NoError(t, remote.Close())
+	require.Eventually(t, func() bool {
+		return connClosed(conn)
+	}, time.Second, time.Millisecond)
+}
@@ -0,0 +1,350 @@
+package plog
+
+import (
+	"context"
+	"crypto/tls"
+	"net"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultNetDialTimeout is the default timeout for establishing a
+	// connection.
+	DefaultNetDialTimeout = 5 * time.Second
+	// DefaultNetWriteTimeout is the default timeout for writing a record.
+	DefaultNetWriteTimeout = 5 * time.Second
+	// DefaultNetMinBackoff is the default delay before the first reconnection
+	// attempt.
+	DefaultNetMinBackoff = 100 * time.Millisecond
+	// DefaultNetMaxBackoff is the default maximum delay between reconnection
+	// attempts.
+	DefaultNetMaxBackoff = 30 * time.Second
+	// DefaultNetBufferSize is the default size of the overflow buffer.
+	DefaultNetBufferSize = 1 << 20
+	// DefaultNetIdleCheck is the default idle time after which a connection is
+	// checked before being written to.
+	DefaultNetIdleCheck = 10 * time.Second
+)
+
+// NetWriterOptions are the options for a NetWriter.
+type NetWriterOptions struct {
+	// TLSConfig enables TLS on stream connections when set, see
+	// TLSOptions.Config.
+	TLSConfig *tls.Config
+	// DialTimeout is the timeout for establishing a connection. The default is
+	// DefaultNetDialTimeout.
+	DialTimeout time.Duration
+	// WriteTimeout is the timeout for writing a record, after which the
+	// connection is considered lost. The default is DefaultNetWriteTimeout.
+	WriteTimeout time.Duration
+	// MinBackoff is the delay before the first reconnection attempt. It's
+	// doubled after each failed attempt. The default is DefaultNetMinBackoff.
+	MinBackoff time.Duration
+	// MaxBackoff is the maximum delay between reconnection attempts. The
+	// default is DefaultNetMaxBackoff.
+	MaxBackoff time.Duration
+	// BufferSize is the maximum number of bytes kept while disconnected. When
+	// full, the oldest records are dropped. The default is
+	// DefaultNetBufferSize.
+	BufferSize int
+	// KeepAlive is the period of the TCP keepalive probes, which keep
+	// middleboxes from dropping idle connections. Zero uses the net.Dialer
+	// default, a negative value disables them.
+	KeepAlive time.Duration
+	// IdleCheck is the idle time after which a stream connection is checked
+	// for staleness before the next write. A connection closed by the remote
+	// is replaced right away, so the next burst of records isn't lost to it.
+	// The check peeks at the socket without consuming what the remote sent,
+	// and is only available on Unix. The default is DefaultNetIdleCheck, a
+	// negative value disables the check.
+	IdleCheck time.Duration
+	// MaxIdle is the idle time after which a connection is closed and
+	// redialed before the next write. The default is no limit.
+	MaxIdle time.Duration
+	// Proxy returns the proxy used to reach TCP addresses, e.g.
+	// ProxyFromEnvironment or ProxyURL. The default is to connect directly.
+	Proxy ProxyFunc
+}
+
+// NetWriter is an io.Writer that writes records to a TCP, UDP, or Unix
+// socket. It reconnects with exponential backoff when the connection is lost
+// and keeps records in an overflow buffer until the connection is back.
+//
+// Writes never fail. The write that finds the connection down dials it and
+// sends the pending records, so it may take up to DialTimeout, plus up to
+// WriteTimeout per record. Meanwhile, the other writes only buffer their
+// records and return. Use the Async logger option to keep the dial and the
+// sends off the callers' path. Records that don't fit in the overflow buffer
+// are dropped and counted, see Dropped.
+//
+// There is no background loop: the buffered records are only retried on the
+// next Write, Flush, or Close, once the backoff delay has elapsed.
+type NetWriter struct {
+	network string
+	addr    string
+	opts    NetWriterOptions
+
+	// iomu guards the connection, and is held while sending.
+	iomu     sync.Mutex
+	conn     net.Conn
+	backoff  time.Duration
+	retryAt  time.Time
+	lastUsed time.Time
+
+	// mu guards the pending records.
+	mu      sync.Mutex
+	pending [][]byte
+	size    int
+	dropped uint64
+
+	dial   func() (net.Conn, error)
+	closed func(net.Conn) bool
+	now    func() time.Time
+}
+
+// NewNetWriter returns a new NetWriter for the given network and address.
+// Network is one of "tcp", "tcp4", "tcp6", "udp", "udp4", "udp6", "unix", or
+// "unixgram". The connection is established on the first write.
+func NewNetWriter(network, addr string, o NetWriterOptions) *NetWriter {
+	if o.DialTimeout <= 0 {
+		o.DialTimeout = DefaultNetDialTimeout
+	}
+	if o.WriteTimeout <= 0 {
+		o.WriteTimeout = DefaultNetWriteTimeout
+	}
+	if o.MinBackoff <= 0 {
+		o.MinBackoff = DefaultNetMinBackoff
+	}
+	if o.MaxBackoff < o.MinBackoff {
+		o.MaxBackoff = DefaultNetMaxBackoff
+	}
+	if o.BufferSize <= 0 {
+		o.BufferSize = DefaultNetBufferSize
+	}
+	if o.IdleCheck == 0 {
+		o.IdleCheck = DefaultNetIdleCheck
+	}
+	w := &NetWriter{
+		network: network,
+		addr:    addr,
+		opts:    o,
+		now:     time.Now,
+	}
+	w.dial = w.dialConn
+	w.closed = connClosed
+	return w
+}
+
+func (w *NetWriter) dialConn() (net.Conn, error) {
+	d := &net.Dialer{Timeout: w.opts.DialTimeout, KeepAlive: w.opts.KeepAlive}
+	if w.opts.Proxy != nil && strings.HasPrefix(w.network, "tcp") {
+		proxy, err := w.opts.Proxy(w.addr)
+		if err != nil {
+			return nil, err
+		}
+		if proxy != nil {
+			ctx, cancel := context.WithTimeout(context.Background(), w.opts.DialTimeout)
+			defer cancel()
+			conn, err := dialProxy(ctx, d, proxy, w.addr)
+			if err != nil || w.opts.TLSConfig == nil {
+				return conn, err
+			}
+			return tlsClient(ctx, conn, w.opts.TLSConfig, w.addr)
+		}
+	}
+	if w.opts.TLSConfig != nil && isStreamNetwork(w.network) {
+		return tls.DialWithDialer(d, w.network, w.addr, w.opts.TLSConfig)
+	}
+	return d.Dial(w.network, w.addr)
+}
+
+func isStreamNetwork(network string) bool {
+	switch network {
+	case "tcp", "tcp4", "tcp6", "unix":
+		return true
+	default:
+		return false
+	}
+}
+
+// Write writes p to the connection, or buffers it if the connection is down
+// or another write is sending.
+func (w *NetWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	w.enqueue(p)
+	w.mu.Unlock()
+
+	// Only one write sends at a time, the others leave their records to it.
+	for w.iomu.TryLock() {
+		ok := w.flush()
+		w.iomu.Unlock()
+		if !ok || w.empty() {
+			break
+		}
+	}
+	return len(p), nil
+}
+
+// enqueue adds a copy of p to the pending records, dropping the oldest
+// records if the buffer is full. It must be called with w.mu held.
+func (w *NetWriter) enqueue(p []byte) {
+	if len(p) > w.opts.BufferSize {
+		w.dropped++
+		return
+	}
+	for w.size+len(p) > w.opts.BufferSize {
+		w.size -= len(w.pending[0])
+		w.pending[0] = nil
+		w.pending = w.pending[1:]
+		w.dropped++
+	}
+	w.pending = append(w.pending, append([]byte(nil), p...))
+	w.size += len(p)
+}
+
+// next removes and returns the oldest pending record, or nil if there is
+// none.
+func (w *NetWriter) next() []byte {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if len(w.pending) == 0 {
+		return nil
+	}
+	p := w.pending[0]
+	w.pending[0] = nil
+	w.pending = w.pending[1:]
+	w.size -= len(p)
+	return p
+}
+
+// requeue puts back the unsent part of a record in front of the pending
+// records, or drops it if the buffer has filled up in the meantime.
+func (w *NetWriter) requeue(p []byte) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.size+len(p) > w.opts.BufferSize {
+		w.dropped++
+		return
+	}
+	w.pending = append([][]byte{p}, w.pending...)
+	w.size += len(p)
+}
+
+func (w *NetWriter) empty() bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return len(w.pending) == 0
+}
+
+// flush writes the pending records, connecting first if needed. It reports
+// false if the connection is down. It must be called with w.iomu held.
+func (w *NetWriter) flush() bool {
+	if w.conn != nil && w.stale() {
+		// Replace the connection right away, without backing off.
+		w.conn.Close() //nolint: errcheck
+		w.conn = nil
+	}
+	if w.empty() {
+		return true
+	}
+	if w.conn == nil && !w.connect() {
+		return false
+	}
+	for p := w.next(); p != nil; p = w.next() {
+		w.conn.SetWriteDeadline(time.Now().Add(w.opts.WriteTimeout)) //nolint: errcheck
+		n, err := w.conn.Write(p)
+		if err != nil {
+			// Only resend what didn't make it, so the remote doesn't get
+			// the start of the record twice. A datagram is never split.
+			if n == 0 || isStreamNetwork(w.network) {
+				w.requeue(p[n:])
+			}
+			w.disconnect()
+			return false
+		}
+		w.lastUsed = w.now()
+	}
+	return true
+}
+
+// stale reports whether the connection has been idle for too long, or was
+// closed by the remote while idle.
+func (w *NetWriter) stale() bool {
+	idle := w.now().Sub(w.lastUsed)
+	if w.opts.MaxIdle > 0 && idle >= w.opts.MaxIdle {
+		return true
+	}
+	if w.opts.IdleCheck < 0 || idle < w.opts.IdleCheck || !isStreamNetwork(w.network) {
+		return false
+	}
+	return w.closed(w.conn)
+}
+
+// connect dials the remote if the backoff delay has elapsed.
+func (w *NetWriter) connect() bool {
+	if w.now().Before(w.retryAt) {
+		return false
+	}
+	conn, err := w.dial()
+	if err != nil {
+		w.retry()
+		return false
+	}
+	w.conn = conn
+	w.backoff = 0
+	w.lastUsed = w.now()
+	return true
+}
+
+func (w *NetWriter) disconnect() {
+	w.conn.Close() //nolint: errcheck
+	w.conn = nil
+	w.retry()
+}
+
+// retry schedules the next connection attempt.
+func (w *NetWriter) retry() {
+	if w.backoff == 0 {
+		w.backoff = w.opts.MinBackoff
+	} else if w.backoff *= 2; w.backoff > w.opts.MaxBackoff {
+		w.backoff = w.opts.MaxBackoff
+	}
+	w.retryAt = w.now().Add(w.backoff)
+}
+
+// messageOriented reports whether each write is sent as its own datagram.
+func (w *NetWriter) messageOriented() bool {
+	return !isStreamNetwork(w.network)
+}
+
+// Dropped returns the number of records dropped because the overflow buffer
+// was full.
+func (w *NetWriter) Dropped() uint64 {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.dropped
+}
+
+// Flush tries to send the pending records, connecting first if the backoff
+// delay has elapsed. It waits for any write being sent.
+func (w *NetWriter) Flush() {
+	w.iomu.Lock()
+	defer w.iomu.Unlock()
+	w.flush()
+}
+
+// Close tries to send any pending records and closes the connection.
+func (w *NetWriter) Close() error {
+	w.iomu.Lock()
+	defer w.iomu.Unlock()
+
+	w.flush()
+	if w.conn == nil {
+		return nil
+	}
+	err := w.conn.Close()
+	w.conn = nil
+	return err
+}
@@ -0,0 +1,244 @@
+package plog
+
+import (
+	"bufio"
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNetWriterTCP(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer ln.Close() //nolint: errcheck
+
+	lines := make(chan string, 2)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close() //nolint: errcheck
+		s := bufio.NewScanner(conn)
+		for s.Scan() {
+			lines <- s.Text()
+		}
+	}()
+
+	w := NewNetWriter("tcp", ln.Addr().String(), NetWriterOptions{})
+	defer w.Close() //nolint: errcheck
+	l := NewWithOptions(w, Options{Formatter: LogfmtFormatter})
+	l.Info("hello", "foo", "bar")
+	l.Warn("bye")
+
+	for _, expected := range []string{"level=info msg=hello foo=bar", "level=warn msg=bye"} {
+		select {
+		case line := <-lines:
+			require.Equal(t, expected, line)
+		case <-time.After(time.Second):
+			t.Fatal("timed out waiting for record")
+		}
+	}
+}
+
+type fakeConn struct {
+	net.Conn
+	writes [][]byte
+	fail   bool
+	short  int
+	block  chan struct{}
+	closed bool
+}
+
+func (c *fakeConn) SetWriteDeadline(time.Time) error { return nil }
+
+func (c *fakeConn) Write(p []byte) (int, error) {
+	if c.block != nil {
+		<-c.block
+	}
+	if c.fail {
+		return 0, errors.New("broken pipe")
+	}
+	if c.short > 0 {
+		c.writes = append(c.writes, append([]byte(nil), p[:c.short]...))
+		c.short = 0
+		return len(c.writes[len(c.writes)-1]), errors.New("broken pipe")
+	}
+	c.writes = append(c.writes, append([]byte(nil), p...))
+	return len(p), nil
+}
+
+func (c *fakeConn) Close() error { return nil }
+
+func TestNetWriterReconnect(t *testing.T) {
+	now := time.Unix(0, 0)
+	conn := &fakeConn{}
+	up := false
+	dials := 0
+
+	w := NewNetWriter("tcp", "example.com:514", NetWriterOptions{
+		MinBackoff: time.Second,
+		MaxBackoff: 3 * time.Second,
+		BufferSize: 10,
+	})
+	w.now = func() time.Time { return now }
+	w.dial = func() (net.Conn, error) {
+		dials++
+		if !up {
+			return nil, errors.New("connection refused")
+		}
+		return conn, nil
+	}
+
+	// Connection refused, the record is buffered.
+	n, err := w.Write([]byte("one\n"))
+	require.NoError(t, err)
+	require.Equal(t, 4, n)
+	require.Equal(t, 1, dials)
+	require.Equal(t, time.Second, w.backoff)
+
+	// Still backing off, no dial attempt.
+	_, _ = w.Write([]byte("two\n"))
+	require.Equal(t, 1, dials)
+
+	// Backoff doubles, then caps at MaxBackoff.
+	now = now.Add(time.Second)
+	_, _ = w.Write([]byte("three\n"))
+	require.Equal(t, 2, dials)
+	require.Equal(t, 2*time.Second, w.backoff)
+	now = now.Add(2 * time.Second)
+	_, _ = w.Write([]byte("four\n"))
+	require.Equal(t, 3*time.Second, w.backoff)
+
+	// The buffer only holds 10 bytes, the oldest records were dropped.
+	require.Equal(t, uint64(3), w.Dropped())
+
+	// Back up, pending records are flushed in order.
+	up = true
+	now = now.Add(3 * time.Second)
+	_, _ = w.Write([]byte("five\n"))
+	require.Equal(t, [][]byte{[]byte("four\n"), []byte("five\n")}, conn.writes)
+	require.Zero(t, w.backoff)
+
+	// A failed write reconnects on the next attempt.
+	conn.fail = true
+	_, _ = w.Write([]byte("six\n"))
+	require.Nil(t, w.conn)
+	conn.fail = false
+	now = now.Add(time.Second)
+	_, _ = w.Write([]byte("seven\n"))
+	require.Equal(t, []byte("seven\n"), conn.writes[len(conn.writes)-1])
+	require.Equal(t, []byte("six\n"), conn.writes[len(conn.writes)-2])
+}
+
+func TestNetWriterIdle(t *testing.T) {
+	now := time.Unix(0, 0)
+	var conns []*fakeConn
+	w := NewNetWriter("tcp", "example.com:514", NetWriterOptions{
+		IdleCheck: time.Minute,
+		MaxIdle:   time.Hour,
+	})
+	w.now = func() time.Time { return now }
+	w.dial = func() (net.Conn, error) {
+		conns = append(conns, &fakeConn{})
+		return conns[len(conns)-1], nil
+	}
+	w.closed = func(conn net.Conn) bool { return conn.(*fakeConn).closed }
+
+	_, _ = w.Write([]byte("one\n"))
+	require.Len(t, conns, 1)
+
+	// Idle but alive, the connection is kept.
+	now = now.Add(2 * time.Minute)
+	_, _ = w.Write([]byte("two\n"))
+	require.Len(t, conns, 1)
+
+	// Closed by the remote while idle, replaced before writing.
+	now = now.Add(2 * time.Minute)
+	conns[0].closed = true
+	_, _ = w.Write([]byte("three\n"))
+	require.Len(t, conns, 2)
+	require.Equal(t, [][]byte{[]byte("three\n")}, conns[1].writes)
+
+	// Idle for too long, redialed.
+	now = now.Add(time.Hour)
+	_, _ = w.Write([]byte("four\n"))
+	require.Len(t, conns, 3)
+	require.Zero(t, w.backoff)
+}
+
+func TestNetWriterFlush(t *testing.T) {
+	now := time.Unix(0, 0)
+	conn := &fakeConn{}
+	up := false
+	w := NewNetWriter("tcp", "example.com:514", NetWriterOptions{MinBackoff: time.Second})
+	w.now = func() time.Time { return now }
+	w.dial = func() (net.Conn, error) {
+		if !up {
+			return nil, errors.New("connection refused")
+		}
+		return conn, nil
+	}
+
+	_, _ = w.Write([]byte("one\n"))
+	require.Empty(t, conn.writes)
+
+	// The buffered record is sent without waiting for another write.
+	up = true
+	now = now.Add(time.Second)
+	w.Flush()
+	require.Equal(t, [][]byte{[]byte("one\n")}, conn.writes)
+}
+
+func TestNetWriterPartialWrite(t *testing.T) {
+	conn := &fakeConn{short: 2}
+	w := NewNetWriter("tcp", "example.com:514", NetWriterOptions{MinBackoff: time.Nanosecond})
+	w.dial = func() (net.Conn, error) { return conn, nil }
+
+	_, _ = w.Write([]byte("one\n"))
+	require.Nil(t, w.conn)
+	time.Sleep(time.Millisecond)
+	_, _ = w.Write([]byte("two\n"))
+	require.Equal(t, [][]byte{[]byte("on"), []byte("e\n"), []byte("two\n")}, conn.writes)
+}
+
+func TestNetWriterBlockedRemote(t *testing.T) {
+	conn := &fakeConn{block: make(chan struct{})}
+	w := NewNetWriter("tcp", "example.com:514", NetWriterOptions{})
+	dialed := make(chan struct{})
+	w.dial = func() (net.Conn, error) {
+		close(dialed)
+		return conn, nil
+	}
+
+	sent := make(chan struct{})
+	go func() {
+		_, _ = w.Write([]byte("one\n"))
+		close(sent)
+	}()
+	// The first write is now stuck sending.
+	<-dialed
+
+	done := make(chan struct{})
+	go func() {
+		_, _ = w.Write([]byte("two\n"))
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("write blocked behind the stuck remote")
+	}
+
+	close(conn.block)
+	<-sent
+	require.Equal(t, [][]byte{[]byte("one\n"), []byte("two\n")}, conn.writes)
+}
+
+func TestNetWriterMessageOriented(t *testing.T) {
+	require.True(t, NewNetWriter("udp", "127.0.0.1:0", NetWriterOptions{}).messageOriented())
+	require.False(t, NewNetWriter("tcp", "127.0.0.1:0", NetWriterOptions{}).messageOriented())
+}
@@ -0,0 +1,132 @@
+package plog
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+)
+
+// NotifyMode is a set of ways a NotifyProcessor gets the user attention.
+type NotifyMode uint8
+
+const (
+	// NotifyBell rings the terminal bell.
+	NotifyBell NotifyMode = 1 << iota
+	// NotifyDesktop shows a desktop notification using notify-send on Linux
+	// and BSDs, osascript on macOS, or PowerShell on Windows.
+	NotifyDesktop
+)
+
+// DefaultNotifyInterval is the default minimum time between notifications.
+const DefaultNotifyInterval = 5 * time.Second
+
+// NotifyOptions are the options for a NotifyProcessor.
+type NotifyOptions struct {
+	// Mode is how the user is notified. The default is NotifyBell.
+	Mode NotifyMode
+	// Title is the desktop notification title. The default is the program
+	// name.
+	Title string
+	// Bell is where the terminal bell is written. The default is os.Stderr.
+	Bell io.Writer
+	// Interval is the minimum time between notifications, so a burst of
+	// errors doesn't ring a burst of bells. The default is
+	// DefaultNotifyInterval.
+	Interval time.Duration
+}
+
+// NotifyProcessor is a Processor that rings the terminal bell or shows a
+// desktop notification on error and fatal records, so failures in long
+// running CLI tasks get noticed in background terminals.
+type NotifyProcessor struct {
+	opts NotifyOptions
+
+	mu   sync.Mutex
+	last time.Time
+	now  func() time.Time
+	show func(title, msg string) error
+}
+
+// NewNotifyProcessor returns a new NotifyProcessor.
+func NewNotifyProcessor(o NotifyOptions) *NotifyProcessor {
+	if o.Mode == 0 {
+		o.Mode = NotifyBell
+	}
+	if o.Title == "" {
+		o.Title = filepath.Base(os.Args[0])
+	}
+	if o.Bell == nil {
+		o.Bell = os.Stderr
+	}
+	if o.Interval <= 0 {
+		o.Interval = DefaultNotifyInterval
+	}
+	return &NotifyProcessor{
+		opts: o,
+		now:  time.Now,
+		show: func(title, msg string) error {
+			cmd := desktopNotifyCmd(runtime.GOOS, title, msg)
+			if cmd == nil {
+				return nil
+			}
+			return cmd.Run()
+		},
+	}
+}
+
+// Process notifies the user of error and fatal records.
+func (p *NotifyProcessor) Process(e *Entry) bool {
+	if e.Level < ErrorLevel || e.Level == noLevel {
+		return true
+	}
+
+	p.mu.Lock()
+	now := p.now()
+	if !p.last.IsZero() && now.Sub(p.last) < p.opts.Interval {
+		p.mu.Unlock()
+		return true
+	}
+	p.last = now
+	p.mu.Unlock()
+
+	if p.opts.Mode&NotifyBell != 0 {
+		_, _ = p.opts.Bell.Write([]byte{'\a'})
+	}
+	if p.opts.Mode&NotifyDesktop != 0 {
+		msg := e.Message
+		if e.Prefix != "" {
+			msg = e.Prefix + ": " + msg
+		}
+		title := fmt.Sprintf("%s: %s", p.opts.Title, strings.ToUpper(e.Level.String()))
+		// Don't hold up logging on the notification daemon.
+		go p.show(title, msg) //nolint: errcheck
+	}
+	return true
+}
+
+// desktopNotifyCmd returns the command showing a desktop notification on the
+// given OS, or nil if it's not supported.
+func desktopNotifyCmd(goos, title, msg string) *exec.Cmd {
+	switch goos {
+	case "darwin":
+		script := fmt.Sprintf("display notification %q with title %q", msg, title)
+		return exec.Command("osascript", "-e", script) //nolint: gosec
+	case "windows":
+		quote := func(s string) string { return "'" + strings.ReplaceAll(s, "'", "''") + "'" }
+		script := "[reflection.assembly]::loadwithpartialname('System.Windows.Forms') | Out-Null; " +
+			"$n = New-Object System.Windows.Forms.NotifyIcon; " +
+			"$n.Icon = [System.Drawing.SystemIcons]::Error; $n.Visible = $true; " +
+			"$n.ShowBalloonTip(5000, " + quote(title) + ", " + quote(msg) + ", 'Error')"
+		return exec.Command("powershell", "-NoProfile", "-Command", script) //nolint: gosec
+	case "linux", "freebsd", "openbsd", "netbsd", "dragonfly":
+		return exec.Command("notify-send", "--urgency=critical", title, msg) //nolint: gosec
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,53 @@
+package plog
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNotifyProcessor(t *testing.T) {
+	var bell bytes.Buffer
+	now := time.Unix(0, 0)
+	shown := make(chan [2]string, 10)
+
+	p := NewNotifyProcessor(NotifyOptions{
+		Mode:  NotifyBell | NotifyDesktop,
+		Title: "bake",
+		Bell:  &bell,
+	})
+	p.now = func() time.Time { return now }
+	p.show = func(title, msg string) error {
+		shown <- [2]string{title, msg}
+		return nil
+	}
+
+	l := NewWithOptions(discardWriter{}, Options{Prefix: "oven", Processors: []Processor{p}})
+	l.Warn("ignored")
+	require.Empty(t, bell.String())
+
+	l.Error("burnt")
+	require.Equal(t, "\a", bell.String())
+	require.Equal(t, [2]string{"bake: ERROR", "oven: burnt"}, <-shown)
+
+	// Notifications are throttled.
+	now = now.Add(time.Second)
+	l.Error("burnt again")
+	require.Equal(t, "\a", bell.String())
+
+	now = now.Add(DefaultNotifyInterval)
+	l.Error("still burnt")
+	require.Equal(t, "\a\a", bell.String())
+	require.Equal(t, [2]string{"bake: ERROR", "oven: still burnt"}, <-shown)
+}
+
+func TestDesktopNotifyCmd(t *testing.T) {
+	require.Equal(t, []string{"notify-send", "--urgency=critical", "title", "msg"},
+		desktopNotifyCmd("linux", "title", "msg").Args)
+	require.Equal(t, []string{"osascript", "-e", `display notification "say \"hi\"" with title "title"`},
+		desktopNotifyCmd("darwin", "title", `say "hi"`).Args)
+	require.Contains(t, desktopNotifyCmd("windows", "it's", "msg").Args[3], "'it''s'")
+	require.Nil(t, desktopNotifyCmd("plan9", "title", "msg"))
+}
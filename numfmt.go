@@ -0,0 +1,142 @@
+package plog
+
+import (
+	"strconv"
+	"strings"
+)
+
+// NumberFormat is a formatting hint for the numeric values of a field. It's
+// applied by the TextFormatter and LogfmtFormatter; the other formatters keep
+// numbers as numbers.
+type NumberFormat struct {
+	// Precision is the number of decimal places of floats. A negative value
+	// uses the smallest number of places representing the value exactly.
+	// Integers have no decimal places unless Scientific is set.
+	Precision int
+	// Scientific uses the scientific notation, e.g. 1.23e+06.
+	Scientific bool
+	// Thousands groups the digits of the integer part by thousands, e.g.
+	// 1,234,567.
+	Thousands bool
+	// Separator is the thousands separator. The default is ','.
+	Separator string
+}
+
+// WithNumberFormat returns a new logger formatting the numeric values of the
+// given key with f, e.g. to render latencies with two decimal places.
+func (l *Logger) WithNumberFormat(key string, f NumberFormat) *Logger {
+	sl := l.With()
+	m := make(map[string]NumberFormat, len(sl.numberFormats)+1)
+	for k, v := range sl.numberFormats {
+		m[k] = v
+	}
+	m[key] = f
+	sl.numberFormats = m
+	return sl
+}
+
+// formatNumber returns v formatted with the number format of key. It returns
+// false if key has no number format or v isn't a number.
+func (l *Logger) formatNumber(key string, v interface{}) (string, bool) {
+	f, ok := l.numberFormats[key]
+	if !ok {
+		return "", false
+	}
+	if fv, ok := v.(Field); ok {
+		v = fv.Value()
+	}
+	var s string
+	switch n := v.(type) {
+	case int:
+		s = f.formatInt(int64(n))
+	case int8:
+		s = f.formatInt(int64(n))
+	case int16:
+		s = f.formatInt(int64(n))
+	case int32:
+		s = f.formatInt(int64(n))
+	case int64:
+		s = f.formatInt(n)
+	case uint:
+		s = f.formatUint(uint64(n))
+	case uint8:
+		s = f.formatUint(uint64(n))
+	case uint16:
+		s = f.formatUint(uint64(n))
+	case uint32:
+		s = f.formatUint(uint64(n))
+	case uint64:
+		s = f.formatUint(n)
+	case float32:
+		s = f.formatFloat(float64(n), 32)
+	case float64:
+		s = f.formatFloat(n, 64)
+	default:
+		return "", false
+	}
+	return s, true
+}
+
+func (f NumberFormat) formatInt(n int64) string {
+	if f.Scientific {
+		return f.formatFloat(float64(n), 64)
+	}
+	return f.group(strconv.FormatInt(n, 10))
+}
+
+func (f NumberFormat) formatUint(n uint64) string {
+	if f.Scientific {
+		return f.formatFloat(float64(n), 64)
+	}
+	return f.group(strconv.FormatUint(n, 10))
+}
+
+func (f NumberFormat) formatFloat(n float64, bitSize int) string {
+	prec := f.Precision
+	if prec < 0 {
+		prec = -1
+	}
+	if f.Scientific {
+		return strconv.FormatFloat(n, 'e', prec, bitSize)
+	}
+	return f.group(strconv.FormatFloat(n, 'f', prec, bitSize))
+}
+
+// group inserts the thousands separator in the integer part of the decimal
+// number s.
+func (f NumberFormat) group(s string) string {
+	if !f.Thousands {
+		return s
+	}
+	sep := f.Separator
+	if sep == "" {
+		sep = ","
+	}
+
+	sign := ""
+	if strings.HasPrefix(s, "-") {
+		sign, s = "-", s[1:]
+	}
+	intPart, frac := s, ""
+	if i := strings.IndexByte(s, '.'); i >= 0 {
+		intPart, frac = s[:i], s[i:]
+	}
+	if len(intPart) <= 3 {
+		return sign + s
+	}
+
+	var b strings.Builder
+	b.WriteString(sign)
+	head := len(intPart) % 3
+	if head > 0 {
+		b.WriteString(intPart[:head])
+	}
+	for i := head; i < len(intPart); i += 3 {
+		if i > 0 {
+			b.WriteString(sep)
+		}
+		b.WriteString(intPart[i : i+3])
+	}
+	b.WriteString(frac)
+	return b.String()
+}
@@ -0,0 +1,41 @@
+package plog
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNumberFormat(t *testing.T) {
+	cases := []struct {
+		name   string
+		format NumberFormat
+		value  interface{}
+		want   string
+	}{
+		{"precision", NumberFormat{Precision: 2}, 12.3456, "12.35"},
+		{"shortest", NumberFormat{Precision: -1}, float32(0.1), "0.1"},
+		{"no decimals", NumberFormat{}, 2.5, "2"},
+		{"int precision", NumberFormat{Precision: 2}, 42, "42"},
+		{"scientific", NumberFormat{Precision: 2, Scientific: true}, 1234567, "1.23e+06"},
+		{"thousands", NumberFormat{Thousands: true}, int64(-1234567), "-1,234,567"},
+		{"thousands float", NumberFormat{Precision: 2, Thousands: true}, 1234.5, "1,234.50"},
+		{"thousands short", NumberFormat{Thousands: true}, uint16(999), "999"},
+		{"separator", NumberFormat{Thousands: true, Separator: "_"}, uint64(123456), "123_456"},
+		{"not a number", NumberFormat{Precision: 2}, "1.234", "1.234"},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			l := NewWithOptions(&buf, Options{Formatter: LogfmtFormatter}).WithNumberFormat("n", c.format)
+			l.Print("", "n", c.value)
+			require.Equal(t, "n="+c.want+"\n", buf.String())
+		})
+	}
+
+	var buf bytes.Buffer
+	l := New(&buf).WithNumberFormat("latency", NumberFormat{Precision: 1})
+	l.Print("done", "latency", 12.345, "other", 12.345)
+	require.Equal(t, "done latency=12.3 other=12.345\n", buf.String())
+}
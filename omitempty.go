@@ -0,0 +1,49 @@
+package plog
+
+import (
+	"reflect"
+	"time"
+)
+
+// SetOmitEmpty sets whether keyvals with an empty value are skipped. A value
+// is empty if it is nil, a nil pointer, an empty string or Str field, or a
+// zero time. It keeps records compact when optional fields are passed
+// unconditionally.
+func (l *Logger) SetOmitEmpty(omit bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.omitEmpty = omit
+}
+
+// omitEmptyValues returns keyvals without the pairs having an empty value.
+func (l *Logger) omitEmptyValues(keyvals []interface{}) []interface{} {
+	if !l.omitEmpty {
+		return keyvals
+	}
+	out := keyvals[:0]
+	for i := 0; i+1 < len(keyvals); i += 2 {
+		if !isEmptyValue(keyvals[i+1]) {
+			out = append(out, keyvals[i], keyvals[i+1])
+		}
+	}
+	return out
+}
+
+// isEmptyValue reports whether v is nil, a nil pointer, an empty string or
+// Str field, or a zero time.
+func isEmptyValue(v interface{}) bool {
+	switch v := v.(type) {
+	case nil:
+		return true
+	case string:
+		return v == ""
+	case time.Time:
+		return v.IsZero()
+	case *time.Time:
+		return v == nil || v.IsZero()
+	case Field:
+		return v.kind == fieldString && v.str == ""
+	}
+	rv := reflect.ValueOf(v)
+	return rv.Kind() == reflect.Pointer && rv.IsNil()
+}
@@ -0,0 +1,27 @@
+package plog
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestOmitEmpty(t *testing.T) {
+	var buf bytes.Buffer
+	var nilPtr *int
+	l := NewWithOptions(&buf, Options{Formatter: LogfmtFormatter})
+	kvs := []interface{}{"a", nil, "b", "", "c", time.Time{}, "d", nilPtr, "e", 0, "f", "x"}
+	l.Print("hi", kvs...)
+	require.Equal(t, `msg=hi a=null b= c=0001-01-01T00:00:00Z d=null e=0 f=x`+"\n", buf.String())
+
+	buf.Reset()
+	l.SetOmitEmpty(true)
+	l.With("req", "").Print("hi", kvs...)
+	require.Equal(t, "msg=hi e=0 f=x\n", buf.String())
+
+	buf.Reset()
+	l.Print("hi", Str("a", ""), Str("b", "x"), Int("c", 0), Err(nil))
+	require.Equal(t, "msg=hi b=x c=0\n", buf.String())
+}
@@ -2,6 +2,11 @@ package plog
 
 import (
 	"fmt"
+	"io"
+	"path"
+	"runtime/debug"
+	"strings"
+	"sync"
 	"time"
 )
 
@@ -36,26 +41,156 @@ func LongCallerFormatter(file string, line int, _ string) string {
 	return fmt.Sprintf("%s:%d", file, line)
 }
 
+// ModuleCallerFormatter is a caller formatter that returns the path relative
+// to the root of the main module, detected from the build info, and line
+// number, like "internal/store/db.go:42". Callers outside of the main module
+// are prefixed with their package path instead. As main packages have no
+// import path, their callers are formatted by ShortCallerFormatter.
+func ModuleCallerFormatter(file string, line int, fn string) string {
+	dir := callerPackage(fn)
+	if dir == "main" {
+		return ShortCallerFormatter(file, line, fn)
+	}
+	if mod := mainModule(); dir == mod {
+		dir = ""
+	} else if strings.HasPrefix(dir, mod+"/") && mod != "" {
+		dir = dir[len(mod)+1:]
+	}
+	if dir == "" {
+		return fmt.Sprintf("%s:%d", path.Base(file), line)
+	}
+	return fmt.Sprintf("%s/%s:%d", dir, path.Base(file), line)
+}
+
+// FunctionCallerFormatter is a caller formatter that returns the package
+// name and function, like "plog.(*Logger).Info".
+func FunctionCallerFormatter(_ string, _ int, fn string) string {
+	if i := strings.LastIndexByte(fn, '/'); i != -1 {
+		fn = fn[i+1:]
+	}
+	return fn
+}
+
+// mainModule returns the path of the main module.
+var mainModule = sync.OnceValue(func() string {
+	if bi, ok := debug.ReadBuildInfo(); ok {
+		return bi.Main.Path
+	}
+	return ""
+})
+
+// callerPackage returns the package path of the fully qualified function fn.
+func callerPackage(fn string) string {
+	slash := strings.LastIndexByte(fn, '/') + 1
+	if dot := strings.IndexByte(fn[slash:], '.'); dot != -1 {
+		return fn[:slash+dot]
+	}
+	return ""
+}
+
 // Options is the options for the logger.
 type Options struct {
 	// TimeFunction is the time function for the logger. The default is time.Now.
 	TimeFunction TimeFunction
 	// TimeFormat is the time format for the logger. The default is "2006/01/02 15:04:05".
 	TimeFormat string
+	// FormatterTimeFormats are the time formats of specific formatters, overriding TimeFormat. The default is nil.
+	FormatterTimeFormats map[Formatter]string
+	// DurationFormat is how durations are rendered. The default is nil, the Go formatting.
+	DurationFormat *DurationFormat
+	// BinaryFormat is how []byte values are rendered. The default is BinaryDefault.
+	BinaryFormat BinaryFormat
+	// BinaryMaxLen is the number of bytes of []byte values rendered by the BinaryFormat. The default is 0, no limit.
+	BinaryMaxLen int
+	// PrettyValues renders the text maps, slices and structs as indented trees. The default is false.
+	PrettyValues bool
 	// Level is the level for the logger. The default is InfoLevel.
 	Level Level
+	// LevelWidth is the width the text level labels are padded to. The default is 0, no padding.
+	LevelWidth int
 	// Prefix is the prefix for the logger. The default is no prefix.
 	Prefix string
 	// ReportTimestamp is whether the logger should report the timestamp. The default is false.
 	ReportTimestamp bool
+	// UTC converts the timestamps to UTC before formatting them. The default is false.
+	UTC bool
+	// RelativeTime renders the timestamps as offsets since the logger creation, like "+00:03.412". The default is false.
+	RelativeTime bool
 	// ReportCaller is whether the logger should report the caller location. The default is false.
 	ReportCaller bool
+	// ReportSchemaVersion is whether the logger should report the output schema version. The default is false.
+	ReportSchemaVersion bool
+	// ErrorChains is whether error values are rendered with their cause chain. The default is false.
+	ErrorChains bool
+	// RawValues is whether the TextFormatter ignores the String and MarshalText methods of values. The default is false.
+	RawValues bool
+	// Sanitize is how the TextFormatter handles terminal control characters. The default is SanitizeEscape.
+	Sanitize Sanitize
+	// DuplicateKeys is how keyvals sharing a key are resolved. The default is DuplicateKeepAll.
+	DuplicateKeys DuplicateKeys
+	// OmitEmpty skips keyvals whose value is nil, an empty string or a zero time. The default is false.
+	OmitEmpty bool
+	// Separator is the key/value separator of the TextFormatter. The default is "=".
+	Separator string
+	// IndentSeparator is the multiline value prefix of the TextFormatter. The default is "  │ ".
+	IndentSeparator string
+	// MultilineLayout is how the lines of the text multiline values are decorated. The default is MultilineBar.
+	MultilineLayout MultilineLayout
+	// AlignColumns pads the text output columns to line up across the recent records. The default is false.
+	AlignColumns bool
+	// TypeColors styles the text values by type with the Types styles. The default is false.
+	TypeColors bool
+	// KeyColors colors each text key with a stable color of the KeyPalette. The default is false.
+	KeyColors bool
+	// FlattenStructs is whether the TextFormatter and LogfmtFormatter expand struct values into prefixed keyvals. The default is false.
+	FlattenStructs bool
+	// ShutdownSummary is whether Shutdown and Fatal log a shutdown summary record first. The default is false.
+	ShutdownSummary bool
 	// CallerFormatter is the caller format for the logger. The default is ShortCallerFormatter.
 	CallerFormatter CallerFormatter
-	// CallerOffset is the caller format for the logger. The default is 0.
+	// CallerLink returns the URL of the text caller hyperlinks. The default is nil, no hyperlinks.
+	CallerLink CallerLink
+	// Hyperlinks is whether the TextFormatter renders the caller links as OSC 8 hyperlinks. The default is false.
+	Hyperlinks bool
+	// TerminalCheck decides whether the output is a terminal, styles are disabled when it isn't. The default is automatic detection.
+	TerminalCheck TerminalCheck
+	// CallerOffset is the number of stack frames skipped when reporting the caller, see WithCallerSkip. The default is 0.
 	CallerOffset int
 	// Fields is the fields for the logger. The default is no fields.
 	Fields []interface{}
 	// Formatter is the formatter for the logger. The default is TextFormatter.
 	Formatter Formatter
+	// JSONPrefixMode is how the JSONFormatter renders the prefix. The default is JSONPrefixField.
+	JSONPrefixMode JSONPrefixMode
+	// JSONTimeMode is how the JSONFormatter renders the timestamps. The default is JSONTimeFormatted.
+	JSONTimeMode JSONTimeMode
+	// JSONIndent is the indentation of the JSON records. The default is "", single-line records.
+	JSONIndent string
+	// JSONColors highlights the JSON records on styled outputs. The default is false.
+	JSONColors bool
+	// JSONFieldNames are the names of the built-in JSON fields. The default keeps the default keys.
+	JSONFieldNames JSONFieldNames
+	// JSONNestedKeys is whether the JSONFormatter nests dotted keys into objects. The default is false.
+	JSONNestedKeys bool
+	// Framing is how records are delimited on the output. The default is FramingNewline.
+	Framing Framing
+	// FieldMeta is the metadata for the logger fields. The default is no metadata.
+	FieldMeta map[string]FieldMeta
+	// Processors are the processors run on every record. The default is no processors.
+	Processors []Processor
+	// KeyFilter selects the keys of the records kept by the logger. The default keeps them all.
+	KeyFilter *KeyFilter
+	// RecordHashBucket enables stamping records with a content hash, with the
+	// record time truncated to this duration. The default is no hash.
+	RecordHashBucket time.Duration
+	// EMF enables the AWS CloudWatch Embedded Metric Format mode of the
+	// JSONFormatter. The default is disabled.
+	EMF *EMFOptions
+	// CSV configures the columns of the CSVFormatter. The default is the time, level, prefix, and message.
+	CSV *CSVOptions
+	// Async enables the async mode, see SetAsync. The default is synchronous writes.
+	Async *AsyncOptions
+	// MachineOutput is a second output receiving every record as JSON. The
+	// default is no machine output.
+	MachineOutput io.Writer
 }
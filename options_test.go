@@ -4,7 +4,9 @@ import (
 	"bytes"
 	"fmt"
 	"io"
+	"runtime"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 )
@@ -71,3 +73,70 @@ func TestCallerFormatter(t *testing.T) {
 		})
 	}
 }
+
+func TestWithCallerFormatter(t *testing.T) {
+	require.Equal(t, "plog.(*Logger).Info", FunctionCallerFormatter("", 0, "github.com/Malanris/plog.(*Logger).Info"))
+	require.Equal(t, "main.main", FunctionCallerFormatter("", 0, "main.main"))
+
+	var buf bytes.Buffer
+	l := NewWithOptions(&buf, Options{ReportCaller: true, Formatter: LogfmtFormatter})
+	sl := l.WithCallerFormatter(FunctionCallerFormatter)
+	sl.Print("hi")
+	require.Equal(t, "caller=plog.TestWithCallerFormatter msg=hi\n", buf.String())
+
+	buf.Reset()
+	l.Print("hi")
+	_, file, _, _ := runtime.Caller(0)
+	require.Contains(t, buf.String(), "caller="+trimCallerPath(file, 2)+":")
+}
+
+func TestWithCallerSkip(t *testing.T) {
+	var buf bytes.Buffer
+	l := NewWithOptions(&buf, Options{ReportCaller: true, Formatter: LogfmtFormatter, CallerFormatter: FunctionCallerFormatter})
+	wrapped := l.WithCallerSkip(1)
+	logInfo := func(msg string) { wrapped.Info(msg) }
+	logInfo("hi")
+	require.Equal(t, "level=info caller=plog.TestWithCallerSkip msg=hi\n", buf.String())
+
+	buf.Reset()
+	nested := wrapped.WithCallerSkip(1)
+	inner := func() { nested.Info("hi") }
+	outer := func() { inner() }
+	outer()
+	require.Equal(t, "level=info caller=plog.TestWithCallerSkip msg=hi\n", buf.String())
+}
+
+func TestModuleCallerFormatter(t *testing.T) {
+	cases := []struct {
+		fn, want string
+	}{
+		{"github.com/Malanris/plog.TestModuleCallerFormatter", "file.go:7"},
+		{"github.com/Malanris/plog/parse.(*Decoder).Next", "parse/file.go:7"},
+		{"github.com/Malanris/plog/internal/deep/pkg.F.func1", "internal/deep/pkg/file.go:7"},
+		{"github.com/other/mod/pkg.F", "github.com/other/mod/pkg/file.go:7"},
+		{"main.main", "x/file.go:7"},
+	}
+	for _, c := range cases {
+		require.Equal(t, c.want, ModuleCallerFormatter("/src/x/file.go", 7, c.fn), c.fn)
+	}
+}
+
+func TestUTC(t *testing.T) {
+	var buf bytes.Buffer
+	zone := time.FixedZone("UTC+2", 2*60*60)
+	now := time.Date(2024, 1, 2, 15, 4, 5, 0, zone)
+	l := NewWithOptions(&buf, Options{
+		ReportTimestamp: true,
+		TimeFormat:      time.Kitchen,
+		TimeFunction:    func(time.Time) time.Time { return now },
+		Formatter:       LogfmtFormatter,
+		UTC:             true,
+	})
+	l.Print("hi")
+	require.Equal(t, "time=1:04PM msg=hi\n", buf.String())
+
+	buf.Reset()
+	l.SetUTC(false)
+	l.Print("hi")
+	require.Equal(t, "time=3:04PM msg=hi\n", buf.String())
+}
@@ -0,0 +1,257 @@
+// Package parse decodes records written by the binary formatters of plog.
+package parse
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"time"
+)
+
+// MaxLength is the maximum length of a decoded string, byte slice, array, or
+// map. It guards against allocating huge buffers when decoding corrupt input.
+const MaxLength = 64 << 20
+
+// ErrInvalid is returned when the input isn't a valid record.
+var ErrInvalid = errors.New("invalid msgpack record")
+
+// MsgPackDecoder decodes the records written by plog.MsgPackFormatter:
+//
+//	dec := parse.NewMsgPackDecoder(r)
+//	for {
+//		rec, err := dec.Decode()
+//		if err == io.EOF {
+//			break
+//		}
+//		...
+//	}
+//
+// Integers are decoded as int64 or uint64, floats as float32 or float64,
+// timestamps as time.Time, binary data as []byte, arrays as []interface{},
+// and maps as map[string]interface{}.
+type MsgPackDecoder struct {
+	r *bufio.Reader
+}
+
+// NewMsgPackDecoder returns a new MsgPackDecoder reading from r.
+func NewMsgPackDecoder(r io.Reader) *MsgPackDecoder {
+	return &MsgPackDecoder{r: bufio.NewReader(r)}
+}
+
+// Decode decodes the next record. It returns io.EOF when there are no more
+// records, and io.ErrUnexpectedEOF when the input ends in the middle of one.
+func (d *MsgPackDecoder) Decode() (map[string]interface{}, error) {
+	if _, err := d.r.Peek(1); err != nil {
+		return nil, err
+	}
+	v, err := d.value()
+	if errors.Is(err, io.EOF) {
+		err = io.ErrUnexpectedEOF
+	}
+	if err != nil {
+		return nil, err
+	}
+	rec, ok := v.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("%w: record is a %T, not a map", ErrInvalid, v)
+	}
+	return rec, nil
+}
+
+func (d *MsgPackDecoder) value() (interface{}, error) {
+	c, err := d.r.ReadByte()
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case c <= 0x7f:
+		return int64(c), nil
+	case c >= 0xe0:
+		return int64(int8(c)), nil
+	case c&0xe0 == 0xa0:
+		return d.str(int(c & 0x1f))
+	case c&0xf0 == 0x90:
+		return d.array(int(c & 0x0f))
+	case c&0xf0 == 0x80:
+		return d.object(int(c & 0x0f))
+	}
+
+	switch c {
+	case 0xc0:
+		return nil, nil
+	case 0xc2:
+		return false, nil
+	case 0xc3:
+		return true, nil
+	case 0xc4, 0xc5, 0xc6:
+		n, err := d.length(c - 0xc4)
+		if err != nil {
+			return nil, err
+		}
+		return d.bytes(n)
+	case 0xc7, 0xc8, 0xc9:
+		n, err := d.length(c - 0xc7)
+		if err != nil {
+			return nil, err
+		}
+		return d.ext(n)
+	case 0xca:
+		b, err := d.bytes(4)
+		if err != nil {
+			return nil, err
+		}
+		return math.Float32frombits(binary.BigEndian.Uint32(b)), nil
+	case 0xcb:
+		b, err := d.bytes(8)
+		if err != nil {
+			return nil, err
+		}
+		return math.Float64frombits(binary.BigEndian.Uint64(b)), nil
+	case 0xcc, 0xcd, 0xce, 0xcf:
+		u, err := d.uint(1 << (c - 0xcc))
+		if err != nil {
+			return nil, err
+		}
+		return u, nil
+	case 0xd0, 0xd1, 0xd2, 0xd3:
+		u, err := d.uint(1 << (c - 0xd0))
+		if err != nil {
+			return nil, err
+		}
+		switch c {
+		case 0xd0:
+			return int64(int8(u)), nil
+		case 0xd1:
+			return int64(int16(u)), nil
+		case 0xd2:
+			return int64(int32(u)), nil
+		default:
+			return int64(u), nil
+		}
+	case 0xd4, 0xd5, 0xd6, 0xd7, 0xd8:
+		return d.ext(1 << (c - 0xd4))
+	case 0xd9, 0xda, 0xdb:
+		n, err := d.length(c - 0xd9)
+		if err != nil {
+			return nil, err
+		}
+		return d.str(n)
+	case 0xdc, 0xdd:
+		n, err := d.length(c - 0xdc + 1)
+		if err != nil {
+			return nil, err
+		}
+		return d.array(n)
+	case 0xde, 0xdf:
+		n, err := d.length(c - 0xde + 1)
+		if err != nil {
+			return nil, err
+		}
+		return d.object(n)
+	}
+	return nil, fmt.Errorf("%w: unknown type 0x%02x", ErrInvalid, c)
+}
+
+// length reads a 1, 2, or 4 byte length, for size 0, 1, and 2 respectively.
+func (d *MsgPackDecoder) length(size byte) (int, error) {
+	u, err := d.uint(1 << size)
+	if err != nil {
+		return 0, err
+	}
+	if u > MaxLength {
+		return 0, fmt.Errorf("%w: length %d exceeds MaxLength", ErrInvalid, u)
+	}
+	return int(u), nil
+}
+
+func (d *MsgPackDecoder) uint(size int) (uint64, error) {
+	b, err := d.bytes(size)
+	if err != nil {
+		return 0, err
+	}
+	var u uint64
+	for _, c := range b {
+		u = u<<8 | uint64(c)
+	}
+	return u, nil
+}
+
+func (d *MsgPackDecoder) bytes(n int) ([]byte, error) {
+	b := make([]byte, n)
+	if _, err := io.ReadFull(d.r, b); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+func (d *MsgPackDecoder) str(n int) (interface{}, error) {
+	b, err := d.bytes(n)
+	if err != nil {
+		return nil, err
+	}
+	return string(b), nil
+}
+
+func (d *MsgPackDecoder) array(n int) (interface{}, error) {
+	a := make([]interface{}, 0, min(n, 1024))
+	for i := 0; i < n; i++ {
+		v, err := d.value()
+		if err != nil {
+			return nil, err
+		}
+		a = append(a, v)
+	}
+	return a, nil
+}
+
+func (d *MsgPackDecoder) object(n int) (interface{}, error) {
+	m := make(map[string]interface{}, min(n, 1024))
+	for i := 0; i < n; i++ {
+		k, err := d.value()
+		if err != nil {
+			return nil, err
+		}
+		v, err := d.value()
+		if err != nil {
+			return nil, err
+		}
+		if s, ok := k.(string); ok {
+			m[s] = v
+		} else {
+			m[fmt.Sprint(k)] = v
+		}
+	}
+	return m, nil
+}
+
+// ext decodes an extension value of n bytes. Only timestamps are supported,
+// other extensions are returned as raw bytes.
+func (d *MsgPackDecoder) ext(n int) (interface{}, error) {
+	typ, err := d.r.ReadByte()
+	if err != nil {
+		return nil, err
+	}
+	b, err := d.bytes(n)
+	if err != nil {
+		return nil, err
+	}
+	if int8(typ) != -1 {
+		return b, nil
+	}
+	switch n {
+	case 4:
+		return time.Unix(int64(binary.BigEndian.Uint32(b)), 0), nil
+	case 8:
+		v := binary.BigEndian.Uint64(b)
+		return time.Unix(int64(v&(1<<34-1)), int64(v>>34)), nil
+	case 12:
+		nsec := binary.BigEndian.Uint32(b)
+		sec := int64(binary.BigEndian.Uint64(b[4:]))
+		return time.Unix(sec, int64(nsec)), nil
+	}
+	return nil, fmt.Errorf("%w: timestamp of %d bytes", ErrInvalid, n)
+}
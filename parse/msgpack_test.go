@@ -0,0 +1,66 @@
+package parse
+
+import (
+	"bytes"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMsgPackDecoder(t *testing.T) {
+	cases := []struct {
+		name     string
+		input    []byte
+		expected map[string]interface{}
+		err      error
+	}{
+		{
+			name:     "fixed types",
+			input:    []byte{0x83, 0xa1, 'a', 0x01, 0xa1, 'b', 0xff, 0xa1, 'c', 0x91, 0xc3},
+			expected: map[string]interface{}{"a": int64(1), "b": int64(-1), "c": []interface{}{true}},
+		},
+		{
+			name:     "timestamp 32",
+			input:    []byte{0x81, 0xa1, 't', 0xd6, 0xff, 0x00, 0x00, 0x00, 0x3c},
+			expected: map[string]interface{}{"t": time.Unix(60, 0)},
+		},
+		{
+			name:     "non string key",
+			input:    []byte{0x81, 0x07, 0xc0},
+			expected: map[string]interface{}{"7": nil},
+		},
+		{
+			name:  "truncated",
+			input: []byte{0x82, 0xa1, 'a', 0x01},
+			err:   io.ErrUnexpectedEOF,
+		},
+		{
+			name:  "not a map",
+			input: []byte{0x01},
+			err:   ErrInvalid,
+		},
+		{
+			name:  "unknown type",
+			input: []byte{0x81, 0xa1, 'a', 0xc1},
+			err:   ErrInvalid,
+		},
+		{
+			name:  "too long",
+			input: []byte{0x81, 0xa1, 'a', 0xdb, 0xff, 0xff, 0xff, 0xff},
+			err:   ErrInvalid,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			rec, err := NewMsgPackDecoder(bytes.NewReader(c.input)).Decode()
+			if c.err != nil {
+				require.ErrorIs(t, err, c.err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, c.expected, rec)
+		})
+	}
+}
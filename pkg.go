@@ -1,7 +1,8 @@
 package plog
 
 import (
-	"bytes"
+	"context"
+	"crypto/rsa"
 	"fmt"
 	"io"
 	"log"
@@ -49,22 +50,67 @@ func New(w io.Writer) *Logger {
 // NewWithOptions returns a new logger using the provided options.
 func NewWithOptions(w io.Writer, o Options) *Logger {
 	l := &Logger{
-		b:               bytes.Buffer{},
-		mu:              &sync.RWMutex{},
-		helpers:         &sync.Map{},
-		level:           int32(o.Level),
-		reportTimestamp: o.ReportTimestamp,
-		reportCaller:    o.ReportCaller,
-		prefix:          o.Prefix,
-		timeFunc:        o.TimeFunction,
-		timeFormat:      o.TimeFormat,
-		formatter:       o.Formatter,
-		fields:          o.Fields,
-		callerFormatter: o.CallerFormatter,
-		callerOffset:    o.CallerOffset,
+		wmu:                 &sync.Mutex{},
+		mu:                  &sync.RWMutex{},
+		async:               &atomic.Pointer[asyncQueue]{},
+		helpers:             &sync.Map{},
+		stats:               newStats(),
+		level:               int32(o.Level),
+		reportTimestamp:     o.ReportTimestamp,
+		utc:                 o.UTC,
+		relativeTime:        o.RelativeTime,
+		created:             time.Now(),
+		reportCaller:        o.ReportCaller,
+		reportSchemaVersion: o.ReportSchemaVersion,
+		errorChains:         o.ErrorChains,
+		shutdownSummary:     o.ShutdownSummary,
+		rawValues:           o.RawValues,
+		sanitizeMode:        o.Sanitize,
+		duplicateKeys:       o.DuplicateKeys,
+		omitEmpty:           o.OmitEmpty,
+		separator:           o.Separator,
+		indentSeparator:     o.IndentSeparator,
+		multiline:           o.MultilineLayout,
+		align:               newAlignState(o.AlignColumns),
+		levelWidth:          o.LevelWidth,
+		typeColors:          o.TypeColors,
+		keyColors:           o.KeyColors,
+		flattenStructs:      o.FlattenStructs,
+		prefix:              o.Prefix,
+		timeFunc:            o.TimeFunction,
+		timeFormat:          o.TimeFormat,
+		timeFormats:         o.FormatterTimeFormats,
+		durationFormat:      o.DurationFormat,
+		binaryFormat:        o.BinaryFormat,
+		binaryMaxLen:        o.BinaryMaxLen,
+		prettyValues:        o.PrettyValues,
+		formatter:           o.Formatter,
+		jsonPrefix:          o.JSONPrefixMode,
+		jsonTime:            o.JSONTimeMode,
+		jsonIndent:          o.JSONIndent,
+		jsonColors:          o.JSONColors,
+		jsonNames:           o.JSONFieldNames,
+		jsonNested:          o.JSONNestedKeys,
+		framing:             o.Framing,
+		fields:              o.Fields,
+		meta:                o.FieldMeta,
+		processors:          o.Processors,
+		keyFilter:           newKeyFilter(o.KeyFilter),
+		hashBucket:          o.RecordHashBucket,
+		emf:                 o.EMF,
+		csv:                 newCSVState(o.CSV),
+		callerFormatter:     o.CallerFormatter,
+		callerOffset:        o.CallerOffset,
+		callerLink:          o.CallerLink,
+		hyperlinks:          o.Hyperlinks,
+		terminalCheck:       o.TerminalCheck,
 	}
 
 	l.SetOutput(w)
+	l.SetMachineOutput(o.MachineOutput)
+	if o.Async != nil {
+		l.SetAsync(o.Async)
+	}
 	l.SetLevel(Level(l.level))
 	l.SetStyles(DefaultStyles())
 
@@ -93,6 +139,12 @@ func SetReportCaller(report bool) {
 	Default().SetReportCaller(report)
 }
 
+// SetReportSchemaVersion sets whether to report the output schema version for
+// the default logger.
+func SetReportSchemaVersion(report bool) {
+	Default().SetReportSchemaVersion(report)
+}
+
 // SetLevel sets the level for the default logger.
 func SetLevel(level Level) {
 	Default().SetLevel(level)
@@ -108,16 +160,49 @@ func SetTimeFormat(format string) {
 	Default().SetTimeFormat(format)
 }
 
+// SetFormatterTimeFormat sets the time format of the formatter f for the
+// default logger.
+func SetFormatterTimeFormat(f Formatter, format string) {
+	Default().SetFormatterTimeFormat(f, format)
+}
+
+// SetDurationFormat sets how the default logger renders durations.
+func SetDurationFormat(f *DurationFormat) {
+	Default().SetDurationFormat(f)
+}
+
+// SetBinaryFormat sets how the default logger renders []byte values.
+func SetBinaryFormat(f BinaryFormat, maxLen int) {
+	Default().SetBinaryFormat(f, maxLen)
+}
+
 // SetTimeFunction sets the time function for the default logger.
 func SetTimeFunction(f TimeFunction) {
 	Default().SetTimeFunction(f)
 }
 
+// SetUTC sets whether the default logger converts the timestamps to UTC.
+func SetUTC(utc bool) {
+	Default().SetUTC(utc)
+}
+
+// SetRelativeTime sets whether the default logger renders the timestamps as
+// offsets since its creation.
+func SetRelativeTime(relative bool) {
+	Default().SetRelativeTime(relative)
+}
+
 // SetOutput sets the output for the default logger.
 func SetOutput(w io.Writer) {
 	Default().SetOutput(w)
 }
 
+// SetMachineOutput sets a second output receiving every record of the default
+// logger as JSON. A nil w disables the machine output.
+func SetMachineOutput(w io.Writer) {
+	Default().SetMachineOutput(w)
+}
+
 // SetFormatter sets the formatter for the default logger.
 func SetFormatter(f Formatter) {
 	Default().SetFormatter(f)
@@ -128,11 +213,230 @@ func SetCallerFormatter(f CallerFormatter) {
 	Default().SetCallerFormatter(f)
 }
 
+// SetCallerLink sets the caller hyperlinks of the default logger.
+func SetCallerLink(link CallerLink) {
+	Default().SetCallerLink(link)
+}
+
+// SetHyperlinks sets whether the default logger TextFormatter renders the
+// caller links as OSC 8 hyperlinks.
+func SetHyperlinks(enabled bool) {
+	Default().SetHyperlinks(enabled)
+}
+
+// WithBuildInfo returns a new logger adding the main module version and VCS
+// info to every record.
+func WithBuildInfo() *Logger {
+	return Default().WithBuildInfo()
+}
+
+// WithProcessInfo returns a new logger adding the host name and process ID to
+// every record.
+func WithProcessInfo() *Logger {
+	return Default().WithProcessInfo()
+}
+
+// WithExecutableName returns a new logger adding the name of the executable to
+// every record.
+func WithExecutableName() *Logger {
+	return Default().WithExecutableName()
+}
+
+// WithGoroutineID returns a new logger adding the ID of the calling goroutine
+// to every record.
+func WithGoroutineID() *Logger {
+	return Default().WithGoroutineID()
+}
+
+// WithCallerSkip returns a new logger skipping n more stack frames when
+// reporting the caller.
+func WithCallerSkip(n int) *Logger {
+	return Default().WithCallerSkip(n)
+}
+
+// WithCallerFormatter returns a new logger using the given caller formatter.
+func WithCallerFormatter(f CallerFormatter) *Logger {
+	return Default().WithCallerFormatter(f)
+}
+
 // SetCallerOffset sets the caller offset for the default logger.
 func SetCallerOffset(offset int) {
 	Default().SetCallerOffset(offset)
 }
 
+// SetRecordHash enables stamping every record of the default logger with a
+// content hash. A zero bucket disables hashing.
+func SetRecordHash(bucket time.Duration) {
+	Default().SetRecordHash(bucket)
+}
+
+// SetEMF enables the AWS CloudWatch Embedded Metric Format mode of the
+// default logger JSONFormatter. A nil o disables it.
+func SetEMF(o *EMFOptions) {
+	Default().SetEMF(o)
+}
+
+// SetJSONPrefixMode sets how the default logger JSONFormatter renders the
+// prefix.
+func SetJSONPrefixMode(mode JSONPrefixMode) {
+	Default().SetJSONPrefixMode(mode)
+}
+
+// SetJSONIndent sets the indentation of the JSON records of the default
+// logger.
+func SetJSONIndent(indent string) {
+	Default().SetJSONIndent(indent)
+}
+
+// SetJSONColors sets whether the default logger highlights its JSON records.
+func SetJSONColors(enabled bool) {
+	Default().SetJSONColors(enabled)
+}
+
+// SetJSONFieldNames sets the names of the built-in JSON fields of the default
+// logger.
+func SetJSONFieldNames(names JSONFieldNames) {
+	Default().SetJSONFieldNames(names)
+}
+
+// SetJSONTimeMode sets how the default logger renders the JSON timestamps.
+func SetJSONTimeMode(mode JSONTimeMode) {
+	Default().SetJSONTimeMode(mode)
+}
+
+// SetJSONNestedKeys sets whether the default logger JSONFormatter nests
+// dotted keys into objects.
+func SetJSONNestedKeys(nested bool) {
+	Default().SetJSONNestedKeys(nested)
+}
+
+// SetCSV sets the columns of the default logger CSVFormatter. A nil o
+// restores the default columns.
+func SetCSV(o *CSVOptions) {
+	Default().SetCSV(o)
+}
+
+// SetFraming sets how the default logger records are delimited on the output.
+func SetFraming(f Framing) {
+	Default().SetFraming(f)
+}
+
+// SetAsync enables the async mode of the default logger. A nil o disables
+// it.
+func SetAsync(o *AsyncOptions) {
+	Default().SetAsync(o)
+}
+
+// Shutdown writes the records queued by the default logger async mode,
+// disables it, and flushes the processors and the output.
+func Shutdown(ctx context.Context) error {
+	return Default().Shutdown(ctx)
+}
+
+// SetErrorChains sets whether the default logger renders error values with
+// their cause chain.
+func SetErrorChains(enabled bool) {
+	Default().SetErrorChains(enabled)
+}
+
+// SetShutdownSummary sets whether Shutdown and Fatal log a shutdown summary
+// record with the default logger first.
+func SetShutdownSummary(enabled bool) {
+	Default().SetShutdownSummary(enabled)
+}
+
+// LogShutdownSummary logs a record summarizing the process run with the
+// default logger.
+func LogShutdownSummary() {
+	Default().LogShutdownSummary()
+}
+
+// SetRawValues sets whether the default logger TextFormatter ignores the
+// String and MarshalText methods of values.
+func SetRawValues(raw bool) {
+	Default().SetRawValues(raw)
+}
+
+// SetFlattenStructs sets whether the default logger expands struct values
+// into prefixed keyvals in the TextFormatter and LogfmtFormatter.
+func SetFlattenStructs(flatten bool) {
+	Default().SetFlattenStructs(flatten)
+}
+
+// SetKeyFilter sets the keys of the records kept by the default logger. A nil
+// f disables the filter.
+func SetKeyFilter(f *KeyFilter) {
+	Default().SetKeyFilter(f)
+}
+
+// SetMachineKeyFilter sets the keys of the records written to the default
+// logger machine output. A nil f disables the filter.
+func SetMachineKeyFilter(f *KeyFilter) {
+	Default().SetMachineKeyFilter(f)
+}
+
+// SetSanitize sets how the default logger TextFormatter handles terminal
+// control characters.
+func SetSanitize(s Sanitize) {
+	Default().SetSanitize(s)
+}
+
+// SetDuplicateKeys sets how the default logger resolves keyvals sharing a
+// key.
+func SetDuplicateKeys(d DuplicateKeys) {
+	Default().SetDuplicateKeys(d)
+}
+
+// SetOmitEmpty sets whether the default logger skips keyvals with an empty
+// value.
+func SetOmitEmpty(omit bool) {
+	Default().SetOmitEmpty(omit)
+}
+
+// SetSeparator sets the key/value separator of the default logger.
+func SetSeparator(sep string) {
+	Default().SetSeparator(sep)
+}
+
+// SetIndentSeparator sets the multiline value prefix of the default logger.
+func SetIndentSeparator(prefix string) {
+	Default().SetIndentSeparator(prefix)
+}
+
+// SetAlignColumns sets whether the default logger aligns the columns of its
+// text output.
+func SetAlignColumns(enabled bool) {
+	Default().SetAlignColumns(enabled)
+}
+
+// SetLevelWidth sets the width the default logger pads the level labels to.
+func SetLevelWidth(width int) {
+	Default().SetLevelWidth(width)
+}
+
+// SetTypeColors sets whether the default logger styles the values by type.
+func SetTypeColors(enabled bool) {
+	Default().SetTypeColors(enabled)
+}
+
+// SetKeyColors sets whether the default logger colors each key with a stable
+// palette color.
+func SetKeyColors(enabled bool) {
+	Default().SetKeyColors(enabled)
+}
+
+// SetPrettyValues sets whether the default logger renders composite values as
+// indented trees.
+func SetPrettyValues(pretty bool) {
+	Default().SetPrettyValues(pretty)
+}
+
+// SetMultilineLayout sets how the default logger decorates the lines of
+// multiline values.
+func SetMultilineLayout(layout MultilineLayout) {
+	Default().SetMultilineLayout(layout)
+}
+
 // SetPrefix sets the prefix for the default logger.
 func SetPrefix(prefix string) {
 	Default().SetPrefix(prefix)
@@ -144,6 +448,42 @@ func SetColorProfile(profile termenv.Profile) {
 	Default().SetColorProfile(profile)
 }
 
+// WithStyleFunc returns a new logger styling the values of the TextFormatter
+// with fn.
+func WithStyleFunc(fn StyleFunc) *Logger {
+	return Default().WithStyleFunc(fn)
+}
+
+// WithColorProfile returns a new logger rendering its styles with the given
+// color profile regardless of the output detection.
+func WithColorProfile(profile termenv.Profile) *Logger {
+	return Default().WithColorProfile(profile)
+}
+
+// HasDarkBackground reports whether the output terminal of the default logger
+// has a dark background.
+func HasDarkBackground() bool {
+	return Default().HasDarkBackground()
+}
+
+// SetHasDarkBackground overrides the background detection of the output
+// terminal of the default logger.
+func SetHasDarkBackground(dark bool) {
+	Default().SetHasDarkBackground(dark)
+}
+
+// SetTerminalCheck sets the function deciding whether the default logger
+// output is a terminal. A nil f restores the automatic detection.
+func SetTerminalCheck(f TerminalCheck) {
+	Default().SetTerminalCheck(f)
+}
+
+// WithTerminalCheck returns a new logger using f to decide whether the output
+// is a terminal.
+func WithTerminalCheck(f TerminalCheck) *Logger {
+	return Default().WithTerminalCheck(f)
+}
+
 // SetStyles sets the logger styles for the TextFormatter.
 func SetStyles(s *Styles) {
 	Default().SetStyles(s)
@@ -159,11 +499,116 @@ func With(keyvals ...interface{}) *Logger {
 	return Default().With(keyvals...)
 }
 
+// WithFields returns a new logger with the given fields, in sorted key order.
+func WithFields(fields map[string]interface{}) *Logger {
+	return Default().WithFields(fields)
+}
+
+// WithKeyFilter returns a new logger keeping the keys of the records selected
+// by f.
+func WithKeyFilter(f KeyFilter) *Logger {
+	return Default().WithKeyFilter(f)
+}
+
+// WithMaxValueLength returns a new logger truncating values longer than n
+// runes.
+func WithMaxValueLength(n int) *Logger {
+	return Default().WithMaxValueLength(n)
+}
+
+// WithMaxFields returns a new logger keeping at most n keyvals pairs per
+// record.
+func WithMaxFields(n int) *Logger {
+	return Default().WithMaxFields(n)
+}
+
 // WithPrefix returns a new logger with the given prefix.
 func WithPrefix(prefix string) *Logger {
 	return Default().WithPrefix(prefix)
 }
 
+// WithFieldMeta returns a new logger that annotates the given key with meta.
+func WithFieldMeta(key string, meta FieldMeta) *Logger {
+	return Default().WithFieldMeta(key, meta)
+}
+
+// WithUnit returns a new logger that annotates the given key with a unit.
+func WithUnit(key, unit string) *Logger {
+	return Default().WithUnit(key, unit)
+}
+
+// WithNumberFormat returns a new logger formatting the numeric values of the
+// given key with f.
+func WithNumberFormat(key string, f NumberFormat) *Logger {
+	return Default().WithNumberFormat(key, f)
+}
+
+// WithEncryptedKeys returns a new logger that encrypts the values of the given
+// keys with pub before they are formatted.
+func WithEncryptedKeys(pub *rsa.PublicKey, keys ...string) *Logger {
+	return Default().WithEncryptedKeys(pub, keys...)
+}
+
+// WithRequestID returns a new logger with the KeyRequestID field set to id.
+func WithRequestID(id string) *Logger {
+	return Default().WithRequestID(id)
+}
+
+// WithTraceID returns a new logger with the KeyTraceID and KeySpanID fields
+// set. An empty spanID is omitted.
+func WithTraceID(traceID, spanID string) *Logger {
+	return Default().WithTraceID(traceID, spanID)
+}
+
+// WithUserID returns a new logger with the KeyUserID field set to id.
+func WithUserID(id string) *Logger {
+	return Default().WithUserID(id)
+}
+
+// WithComponent returns a new logger with the KeyComponent field set to name.
+func WithComponent(name string) *Logger {
+	return Default().WithComponent(name)
+}
+
+// WithError returns a new logger with err under ErrorKey.
+func WithError(err error) *Logger {
+	return Default().WithError(err)
+}
+
+// WithDuration returns a new logger with the KeyDurationMS field set to d in
+// fractional milliseconds.
+func WithDuration(d time.Duration) *Logger {
+	return Default().WithDuration(d)
+}
+
+// LogBatch logs the given entries with the default logger, in a single write.
+func LogBatch(entries []Entry) {
+	Default().LogBatch(entries)
+}
+
+// AddProcessor appends processors to the default logger.
+func AddProcessor(p ...Processor) {
+	Default().AddProcessor(p...)
+}
+
+// Flush flushes the default logger processors and output when they buffer
+// records.
+func Flush() {
+	Default().Flush()
+}
+
+// WithCallerDebug returns a new logger that reports, once, the frames it
+// considered when the caller attribution looks wrong.
+func WithCallerDebug() *Logger {
+	return Default().WithCallerDebug()
+}
+
+// WithStacktrace returns a new logger that attaches the stack trace of the
+// logging goroutine to records at or above minLevel.
+func WithStacktrace(minLevel Level) *Logger {
+	return Default().WithStacktrace(minLevel)
+}
+
 // Helper marks the calling function as a helper
 // and skips it for source location information.
 // It's the equivalent of testing.TB.Helper().
@@ -178,6 +623,9 @@ func Log(level Level, msg interface{}, keyvals ...interface{}) {
 
 // Debug logs a debug message.
 func Debug(msg interface{}, keyvals ...interface{}) {
+	if !debugEnabled {
+		return
+	}
 	Default().Log(DebugLevel, msg, keyvals...)
 }
 
@@ -199,7 +647,7 @@ func Error(msg interface{}, keyvals ...interface{}) {
 // Fatal logs a fatal message and exit.
 func Fatal(msg interface{}, keyvals ...interface{}) {
 	Default().Log(FatalLevel, msg, keyvals...)
-	os.Exit(1)
+	Default().exit()
 }
 
 // Print logs a message with no level.
@@ -214,33 +662,56 @@ func Logf(level Level, format string, args ...interface{}) {
 
 // Debugf logs a debug message with formatting.
 func Debugf(format string, args ...interface{}) {
-	Default().Log(DebugLevel, fmt.Sprintf(format, args...))
+	l := Default()
+	if !l.enabled(DebugLevel) {
+		return
+	}
+	l.Log(DebugLevel, fmt.Sprintf(format, args...))
 }
 
 // Infof logs an info message with formatting.
 func Infof(format string, args ...interface{}) {
-	Default().Log(InfoLevel, fmt.Sprintf(format, args...))
+	l := Default()
+	if !l.enabled(InfoLevel) {
+		return
+	}
+	l.Log(InfoLevel, fmt.Sprintf(format, args...))
 }
 
 // Warnf logs a warning message with formatting.
 func Warnf(format string, args ...interface{}) {
-	Default().Log(WarnLevel, fmt.Sprintf(format, args...))
+	l := Default()
+	if !l.enabled(WarnLevel) {
+		return
+	}
+	l.Log(WarnLevel, fmt.Sprintf(format, args...))
 }
 
 // Errorf logs an error message with formatting.
 func Errorf(format string, args ...interface{}) {
-	Default().Log(ErrorLevel, fmt.Sprintf(format, args...))
+	l := Default()
+	if !l.enabled(ErrorLevel) {
+		return
+	}
+	l.Log(ErrorLevel, fmt.Sprintf(format, args...))
 }
 
 // Fatalf logs a fatal message with formatting and exit.
 func Fatalf(format string, args ...interface{}) {
-	Default().Log(FatalLevel, fmt.Sprintf(format, args...))
-	os.Exit(1)
+	l := Default()
+	if l.enabled(FatalLevel) {
+		l.Log(FatalLevel, fmt.Sprintf(format, args...))
+	}
+	l.exit()
 }
 
 // Printf logs a message with formatting and no level.
 func Printf(format string, args ...interface{}) {
-	Default().Log(noLevel, fmt.Sprintf(format, args...))
+	l := Default()
+	if !l.enabled(noLevel) {
+		return
+	}
+	l.Log(noLevel, fmt.Sprintf(format, args...))
 }
 
 // StandardLog returns a standard logger from the default logger.
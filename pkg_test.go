@@ -183,6 +183,21 @@ func TestErrorf(t *testing.T) {
 	assert.Equal(t, "12:00AM ERRO error foo\n", buf.String())
 }
 
+func TestGlobalfDisabled(t *testing.T) {
+	l := Default()
+	t.Cleanup(func() {
+		SetDefault(l)
+	})
+
+	SetDefault(NewWithOptions(discardWriter{}, Options{Level: FatalLevel}))
+	allocs := testing.AllocsPerRun(100, func() {
+		Infof("info %s", "foo")
+		Warnf("warn %s", "foo")
+		Errorf("error %s", "foo")
+	})
+	assert.Zero(t, allocs)
+}
+
 func TestWith(t *testing.T) {
 	var buf bytes.Buffer
 	SetOutput(&buf)
@@ -0,0 +1,103 @@
+package plog
+
+import (
+	"bytes"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const posterQueueSize = 100
+
+// poster posts request bodies in the background so processors never block
+// logging on the network.
+type poster struct {
+	client  *http.Client
+	timeout time.Duration
+	header  http.Header
+	queue   chan posterRequest
+
+	// pending counts the queued and in-flight requests, guarded by mu, so
+	// that flush can wait for them while others are posted.
+	mu      sync.Mutex
+	cond    *sync.Cond
+	pending int
+}
+
+type posterRequest struct {
+	url  string
+	body []byte
+}
+
+func newPoster(client *http.Client, timeout time.Duration, header http.Header) *poster {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	p := &poster{
+		client:  client,
+		timeout: timeout,
+		header:  header,
+		queue:   make(chan posterRequest, posterQueueSize),
+	}
+	p.cond = sync.NewCond(&p.mu)
+	go p.run()
+	return p
+}
+
+// post queues body to be posted to url. It reports false if the queue is
+// full and the request was dropped.
+func (p *poster) post(url string, body []byte) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	select {
+	case p.queue <- posterRequest{url: url, body: body}:
+		p.pending++
+		return true
+	default:
+		return false
+	}
+}
+
+// flush waits for the queued requests to be sent, up to the timeout.
+func (p *poster) flush() {
+	done := make(chan struct{})
+	go func() {
+		p.mu.Lock()
+		for p.pending > 0 {
+			p.cond.Wait()
+		}
+		p.mu.Unlock()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(p.timeout):
+	}
+}
+
+func (p *poster) run() {
+	for r := range p.queue {
+		p.send(r)
+		p.mu.Lock()
+		p.pending--
+		if p.pending == 0 {
+			p.cond.Broadcast()
+		}
+		p.mu.Unlock()
+	}
+}
+
+func (p *poster) send(r posterRequest) {
+	req, err := http.NewRequest(http.MethodPost, r.url, bytes.NewReader(r.body))
+	if err != nil {
+		return
+	}
+	for k, v := range p.header {
+		req.Header[k] = v
+	}
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return
+	}
+	resp.Body.Close() //nolint: errcheck
+}
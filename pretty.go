@@ -0,0 +1,110 @@
+package plog
+
+import (
+	"encoding"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+const (
+	// prettyIndent is the indentation of the nested levels of pretty values.
+	prettyIndent = "  "
+	// maxPrettyDepth bounds the nesting of pretty values, which may be
+	// cyclic.
+	maxPrettyDepth = 32
+)
+
+// SetPrettyValues sets whether the TextFormatter renders maps, slices, arrays
+// and structs as indented trees under their key, with a line per element,
+// instead of a one-line %+v.
+func (l *Logger) SetPrettyValues(pretty bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.prettyValues = pretty
+}
+
+// prettyValue returns the tree rendering of v. It returns false if pretty
+// values are disabled or v isn't a non-empty composite value.
+func (l *Logger) prettyValue(v interface{}) (string, bool) {
+	if !l.prettyValues {
+		return "", false
+	}
+	rv, ok := prettyComposite(v)
+	if !ok {
+		return "", false
+	}
+	var sb strings.Builder
+	l.writePretty(&sb, rv, "", 0)
+	return strings.TrimSuffix(sb.String(), "\n"), true
+}
+
+// prettyComposite returns the value of v, if v is a non-empty composite
+// without its own text representation.
+func prettyComposite(v interface{}) (reflect.Value, bool) {
+	switch v.(type) {
+	case nil, []byte, error, fmt.Stringer, encoding.TextMarshaler, Field:
+		return reflect.Value{}, false
+	}
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Pointer || rv.Kind() == reflect.Interface {
+		if rv.IsNil() {
+			return reflect.Value{}, false
+		}
+		rv = rv.Elem()
+	}
+	switch rv.Kind() {
+	case reflect.Map, reflect.Slice, reflect.Array:
+		return rv, rv.Len() > 0
+	case reflect.Struct:
+		return rv, rv.NumField() > 0
+	}
+	return reflect.Value{}, false
+}
+
+// writePretty writes the elements of the composite rv, a line each, nesting
+// the composite elements one level deeper.
+func (l *Logger) writePretty(sb *strings.Builder, rv reflect.Value, indent string, depth int) {
+	item := func(label string, v reflect.Value) {
+		sb.WriteString(indent)
+		sb.WriteString(label)
+		var ev interface{}
+		if v.CanInterface() {
+			ev = v.Interface()
+		}
+		if cv, ok := prettyComposite(ev); ok && depth < maxPrettyDepth {
+			sb.WriteString("\n")
+			l.writePretty(sb, cv, indent+prettyIndent, depth+1)
+			return
+		}
+		sb.WriteString(" ")
+		if ev == nil && v.IsValid() && !v.CanInterface() {
+			// Unexported struct field.
+			sb.WriteString(fmt.Sprintf("%+v", v))
+		} else {
+			sb.WriteString(l.textValue(ev))
+		}
+		sb.WriteString("\n")
+	}
+
+	switch rv.Kind() {
+	case reflect.Map:
+		keys := rv.MapKeys()
+		sort.Slice(keys, func(i, j int) bool {
+			return fmt.Sprint(keys[i]) < fmt.Sprint(keys[j])
+		})
+		for _, k := range keys {
+			item(fmt.Sprint(k)+":", rv.MapIndex(k))
+		}
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < rv.Len(); i++ {
+			item("-", rv.Index(i))
+		}
+	case reflect.Struct:
+		t := rv.Type()
+		for i := 0; i < rv.NumField(); i++ {
+			item(t.Field(i).Name+":", rv.Field(i))
+		}
+	}
+}
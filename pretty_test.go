@@ -0,0 +1,54 @@
+package plog
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPrettyValues(t *testing.T) {
+	type addr struct {
+		City string
+		Zip  int
+	}
+	type user struct {
+		Name  string
+		Tags  []string
+		Addr  *addr
+		Meta  map[string]int
+		Empty []int
+		note  string
+	}
+	u := user{
+		Name: "bob",
+		Tags: []string{"a", "b"},
+		Addr: &addr{"Paris", 75001},
+		Meta: map[string]int{"z": 1, "a": 2},
+		note: "x",
+	}
+
+	var buf bytes.Buffer
+	l := NewWithOptions(&buf, Options{PrettyValues: true})
+	l.Print("hi", "user", u, "n", 1)
+	require.Equal(t, "hi\n"+
+		"  user=\n"+
+		"  │ Name: bob\n"+
+		"  │ Tags:\n"+
+		"  │   - a\n"+
+		"  │   - b\n"+
+		"  │ Addr:\n"+
+		"  │   City: Paris\n"+
+		"  │   Zip: 75001\n"+
+		"  │ Meta:\n"+
+		"  │   a: 2\n"+
+		"  │   z: 1\n"+
+		"  │ Empty: []\n"+
+		"  │ note: x\n"+
+		" n=1\n", buf.String())
+
+	buf.Reset()
+	l.SetPrettyValues(false)
+	l.Print("hi", "tags", []string{"a", "b"})
+	require.Equal(t, `hi tags="[a b]"`+"\n", buf.String())
+}
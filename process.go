@@ -0,0 +1,37 @@
+package plog
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// Process info keys, as added by WithProcessInfo and WithExecutableName.
+const (
+	// KeyHostname is the key for the host name.
+	KeyHostname = "hostname"
+	// KeyPID is the key for the process ID.
+	KeyPID = "pid"
+	// KeyExecutable is the key for the executable name.
+	KeyExecutable = "executable"
+)
+
+// WithProcessInfo returns a new logger adding the host name and process ID to
+// every record, which most aggregation setups need to correlate records. The
+// host name is omitted if it can't be determined.
+func (l *Logger) WithProcessInfo() *Logger {
+	keyvals := make([]interface{}, 0, 4)
+	if host, err := os.Hostname(); err == nil {
+		keyvals = append(keyvals, KeyHostname, host)
+	}
+	return l.With(append(keyvals, KeyPID, os.Getpid())...)
+}
+
+// WithExecutableName returns a new logger adding the name of the executable to
+// every record.
+func (l *Logger) WithExecutableName() *Logger {
+	name := filepath.Base(os.Args[0])
+	if exe, err := os.Executable(); err == nil {
+		name = filepath.Base(exe)
+	}
+	return l.With(KeyExecutable, name)
+}
@@ -0,0 +1,27 @@
+package plog
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithProcessInfo(t *testing.T) {
+	var buf bytes.Buffer
+	l := NewWithOptions(&buf, Options{Formatter: JSONFormatter}).WithProcessInfo().WithExecutableName()
+	l.Print("hi")
+
+	var m map[string]interface{}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &m))
+	host, err := os.Hostname()
+	require.NoError(t, err)
+	exe, err := os.Executable()
+	require.NoError(t, err)
+	require.Equal(t, host, m[KeyHostname])
+	require.Equal(t, float64(os.Getpid()), m[KeyPID])
+	require.Equal(t, filepath.Base(exe), m[KeyExecutable])
+}
@@ -0,0 +1,94 @@
+package plog
+
+import "io"
+
+// Processor processes records that pass level filtering before they are
+// formatted. Processors run in the order they were added and may modify the
+// entry, forward it elsewhere, or drop it by returning false.
+//
+// Processors and outputs implementing Flush() are flushed by Logger.Flush
+// and before Fatal exits.
+type Processor interface {
+	Process(e *Entry) bool
+}
+
+// ProcessorFunc is a function that implements Processor.
+type ProcessorFunc func(e *Entry) bool
+
+// Process calls f(e).
+func (f ProcessorFunc) Process(e *Entry) bool {
+	return f(e)
+}
+
+type flusher interface {
+	Flush()
+}
+
+// AddProcessor appends processors to the logger.
+func (l *Logger) AddProcessor(p ...Processor) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.processors = append(l.processors[:len(l.processors):len(l.processors)], p...)
+}
+
+// Flush writes the records queued by the async mode, and flushes the
+// processors and the outputs when they buffer records, including the machine
+// output and the writers of a TeeWriter.
+func (l *Logger) Flush() {
+	l.mu.RLock()
+	processors := l.processors
+	w, machine := l.w, l.machine
+	async := l.async.Load()
+	l.mu.RUnlock()
+	if async != nil {
+		async.flush()
+	}
+	for _, p := range processors {
+		if f, ok := p.(flusher); ok {
+			f.Flush()
+		}
+	}
+
+	// Outputs are flushed under the write lock, as they aren't expected to
+	// support concurrent writes.
+	l.wmu.Lock()
+	defer l.wmu.Unlock()
+	flushOutput(w)
+	if machine != nil {
+		flushOutput(machine)
+	}
+}
+
+// flushOutput flushes w if it buffers records, whether its Flush method
+// returns an error, like bufio.Writer's, or not.
+func flushOutput(w io.Writer) {
+	switch f := w.(type) {
+	case flusher:
+		f.Flush()
+	case interface{ Flush() error }:
+		f.Flush() //nolint: errcheck
+	}
+}
+
+// process runs the processors on the entry and reports whether it should be
+// logged.
+func (l *Logger) process(e *Entry) bool {
+	l.mu.RLock()
+	processors := l.processors
+	l.mu.RUnlock()
+	if len(processors) == 0 {
+		return true
+	}
+
+	// Processors get a copy on the heap, so that the entry itself doesn't
+	// escape when there are none.
+	pe := new(Entry)
+	*pe = *e
+	defer func() { *e = *pe }()
+	for _, p := range processors {
+		if !p.Process(pe) {
+			return false
+		}
+	}
+	return true
+}
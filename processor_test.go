@@ -0,0 +1,67 @@
+package plog
+
+import (
+	"bufio"
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type flushCounter struct {
+	n int
+}
+
+func (f *flushCounter) Process(*Entry) bool { return true }
+func (f *flushCounter) Flush()              { f.n++ }
+
+func TestProcessor(t *testing.T) {
+	var buf bytes.Buffer
+	l := NewWithOptions(&buf, Options{Formatter: LogfmtFormatter})
+	l.AddProcessor(ProcessorFunc(func(e *Entry) bool {
+		e.Message = "[" + e.Message + "]"
+		e.Keyvals = append(e.Keyvals, "processed", true)
+		return true
+	}))
+	sub := l.With("sub", 1)
+	sub.AddProcessor(ProcessorFunc(func(e *Entry) bool {
+		return e.Message != "[drop]"
+	}))
+
+	l.Info("hello")
+	require.Equal(t, "level=info msg=[hello] processed=true\n", buf.String())
+
+	buf.Reset()
+	l.Info("drop")
+	require.Equal(t, "level=info msg=[drop] processed=true\n", buf.String())
+
+	buf.Reset()
+	sub.Info("drop")
+	require.Empty(t, buf.String())
+
+	fc := &flushCounter{}
+	l.AddProcessor(fc)
+	l.Flush()
+	require.Equal(t, 1, fc.n)
+}
+
+type flushWriter struct {
+	bytes.Buffer
+	n int
+}
+
+func (w *flushWriter) Flush() { w.n++ }
+
+func TestFlushOutputs(t *testing.T) {
+	var out, machine flushWriter
+	var buf bytes.Buffer
+	bw := bufio.NewWriter(&buf)
+	l := NewWithOptions(NewTeeWriter(&out, bw), Options{Formatter: LogfmtFormatter, MachineOutput: &machine})
+	l.Info("hello")
+	require.Empty(t, buf.String())
+
+	l.Flush()
+	require.Equal(t, 1, out.n)
+	require.Equal(t, 1, machine.n)
+	require.Equal(t, "level=info msg=hello\n", buf.String())
+}
@@ -0,0 +1,272 @@
+package plog
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrProxy is returned when a proxy refuses to connect to the remote.
+var ErrProxy = errors.New("proxy error")
+
+// ProxyFunc returns the proxy to use to reach addr, or nil to connect
+// directly. Supported proxy schemes are "http" for HTTP CONNECT proxies, and
+// "socks5" and "socks5h" for SOCKS5 proxies.
+type ProxyFunc func(addr string) (*url.URL, error)
+
+// ProxyURL returns a ProxyFunc always using u.
+func ProxyURL(u *url.URL) ProxyFunc {
+	return func(string) (*url.URL, error) {
+		return u, nil
+	}
+}
+
+// ProxyFromEnvironment is a ProxyFunc using the proxy set in the ALL_PROXY or
+// HTTPS_PROXY environment variables, or their lowercase versions, unless addr
+// matches NO_PROXY.
+//
+// HTTP sinks don't need it: their default client already honors the proxy
+// environment variables.
+func ProxyFromEnvironment(addr string) (*url.URL, error) {
+	proxy := getenv("ALL_PROXY", "all_proxy", "HTTPS_PROXY", "https_proxy")
+	if proxy == "" || noProxy(addr, getenv("NO_PROXY", "no_proxy")) {
+		return nil, nil
+	}
+	u, err := url.Parse(proxy)
+	if err != nil || u.Host == "" {
+		// Allow "host:port" without a scheme, as curl does.
+		if u, err = url.Parse("http://" + proxy); err != nil {
+			return nil, fmt.Errorf("invalid proxy address %q: %w", proxy, err)
+		}
+	}
+	return u, nil
+}
+
+func getenv(keys ...string) string {
+	for _, k := range keys {
+		if v := os.Getenv(k); v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// noProxy reports whether addr matches the comma separated NO_PROXY list.
+func noProxy(addr, list string) bool {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		host = addr
+	}
+	for _, p := range strings.Split(list, ",") {
+		p = strings.TrimSpace(p)
+		if p == "" {
+			continue
+		}
+		if p == "*" {
+			return true
+		}
+		if h, _, err := net.SplitHostPort(p); err == nil {
+			p = h
+		}
+		p = strings.TrimPrefix(p, ".")
+		if host == p || strings.HasSuffix(host, "."+p) {
+			return true
+		}
+	}
+	return false
+}
+
+// dialProxy connects to addr through the proxy at u.
+func dialProxy(ctx context.Context, d *net.Dialer, u *url.URL, addr string) (net.Conn, error) {
+	switch u.Scheme {
+	case "http", "socks5", "socks5h":
+	default:
+		return nil, fmt.Errorf("%w: unsupported proxy scheme %q", ErrProxy, u.Scheme)
+	}
+
+	proxyAddr := u.Host
+	if u.Port() == "" {
+		port := "1080"
+		if u.Scheme == "http" {
+			port = "80"
+		}
+		proxyAddr = net.JoinHostPort(u.Hostname(), port)
+	}
+	conn, err := d.DialContext(ctx, "tcp", proxyAddr)
+	if err != nil {
+		return nil, err
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)          //nolint: errcheck
+		defer conn.SetDeadline(time.Time{}) //nolint: errcheck
+	}
+
+	if u.Scheme == "http" {
+		conn, err = httpConnect(conn, u, addr)
+	} else {
+		err = socks5Connect(conn, u, addr)
+	}
+	if err != nil {
+		conn.Close() //nolint: errcheck
+		return nil, err
+	}
+	return conn, nil
+}
+
+// httpConnect opens a tunnel to addr with an HTTP CONNECT request.
+func httpConnect(conn net.Conn, u *url.URL, addr string) (net.Conn, error) {
+	req := &http.Request{
+		Method: http.MethodConnect,
+		URL:    &url.URL{Opaque: addr},
+		Host:   addr,
+		Header: http.Header{},
+	}
+	if u.User != nil {
+		pass, _ := u.User.Password()
+		auth := base64.StdEncoding.EncodeToString([]byte(u.User.Username() + ":" + pass))
+		req.Header.Set("Proxy-Authorization", "Basic "+auth)
+	}
+	if err := req.Write(conn); err != nil {
+		return nil, err
+	}
+
+	br := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(br, req)
+	if err != nil {
+		return nil, err
+	}
+	resp.Body.Close() //nolint: errcheck
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%w: %s", ErrProxy, resp.Status)
+	}
+	if br.Buffered() > 0 {
+		return &bufferedConn{Conn: conn, r: br}, nil
+	}
+	return conn, nil
+}
+
+// bufferedConn is a net.Conn whose first bytes were read into r.
+type bufferedConn struct {
+	net.Conn
+	r *bufio.Reader
+}
+
+func (c *bufferedConn) Read(p []byte) (int, error) {
+	return c.r.Read(p)
+}
+
+// socks5Connect connects to addr through a SOCKS5 proxy, as in RFC 1928 and
+// RFC 1929. The address is always resolved by the proxy.
+func socks5Connect(conn net.Conn, u *url.URL, addr string) error {
+	host, portStr, err := net.SplitHostPort(addr)
+	if err != nil {
+		return err
+	}
+	port, err := strconv.ParseUint(portStr, 10, 16)
+	if err != nil {
+		return fmt.Errorf("invalid port %q: %w", portStr, err)
+	}
+	if len(host) > 255 {
+		return fmt.Errorf("%w: host name too long", ErrProxy)
+	}
+
+	method := byte(0x00) // no authentication
+	if u.User != nil {
+		method = 0x02 // username and password
+	}
+	if _, err := conn.Write([]byte{0x05, 1, method}); err != nil {
+		return err
+	}
+	var reply [2]byte
+	if _, err := io.ReadFull(conn, reply[:]); err != nil {
+		return err
+	}
+	if reply[0] != 0x05 || reply[1] != method {
+		return fmt.Errorf("%w: authentication method refused", ErrProxy)
+	}
+
+	if method == 0x02 {
+		user := u.User.Username()
+		pass, _ := u.User.Password()
+		if len(user) > 255 || len(pass) > 255 {
+			return fmt.Errorf("%w: credentials too long", ErrProxy)
+		}
+		b := []byte{0x01, byte(len(user))}
+		b = append(b, user...)
+		b = append(b, byte(len(pass)))
+		b = append(b, pass...)
+		if _, err := conn.Write(b); err != nil {
+			return err
+		}
+		if _, err := io.ReadFull(conn, reply[:]); err != nil {
+			return err
+		}
+		if reply[1] != 0x00 {
+			return fmt.Errorf("%w: authentication failed", ErrProxy)
+		}
+	}
+
+	b := []byte{0x05, 0x01, 0x00, 0x03, byte(len(host))}
+	b = append(b, host...)
+	b = binary.BigEndian.AppendUint16(b, uint16(port))
+	if _, err := conn.Write(b); err != nil {
+		return err
+	}
+
+	var head [4]byte
+	if _, err := io.ReadFull(conn, head[:]); err != nil {
+		return err
+	}
+	if head[1] != 0x00 {
+		return fmt.Errorf("%w: connect failed with code %d", ErrProxy, head[1])
+	}
+	var skip int
+	switch head[3] {
+	case 0x01:
+		skip = net.IPv4len
+	case 0x04:
+		skip = net.IPv6len
+	case 0x03:
+		var n [1]byte
+		if _, err := io.ReadFull(conn, n[:]); err != nil {
+			return err
+		}
+		skip = int(n[0])
+	default:
+		return fmt.Errorf("%w: invalid address type %d", ErrProxy, head[3])
+	}
+	// Skip the bound address and port.
+	_, err = io.CopyN(io.Discard, conn, int64(skip+2))
+	return err
+}
+
+// tlsClient starts a TLS session over conn, verifying the server against the
+// host of addr unless the config sets a server name.
+func tlsClient(ctx context.Context, conn net.Conn, cfg *tls.Config, addr string) (net.Conn, error) {
+	if cfg.ServerName == "" {
+		host, _, err := net.SplitHostPort(addr)
+		if err != nil {
+			host = addr
+		}
+		cfg = cfg.Clone()
+		cfg.ServerName = host
+	}
+	tc := tls.Client(conn, cfg)
+	if err := tc.HandshakeContext(ctx); err != nil {
+		conn.Close() //nolint: errcheck
+		return nil, err
+	}
+	return tc, nil
+}
@@ -0,0 +1,152 @@
+package plog
+
+import (
+	"bufio"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// serveProxy accepts a single connection, runs the proxy handshake, and sends
+// the tunneled lines to the returned channel.
+func serveProxy(t *testing.T, handshake func(conn net.Conn, r *bufio.Reader) error) (string, <-chan string) {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { ln.Close() }) //nolint: errcheck
+
+	lines := make(chan string, 1)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close() //nolint: errcheck
+		r := bufio.NewReader(conn)
+		if err := handshake(conn, r); err != nil {
+			lines <- "handshake: " + err.Error()
+			return
+		}
+		line, _ := r.ReadString('\n')
+		lines <- line
+	}()
+	return ln.Addr().String(), lines
+}
+
+func TestNetWriterHTTPProxy(t *testing.T) {
+	addr, lines := serveProxy(t, func(conn net.Conn, r *bufio.Reader) error {
+		req, err := http.ReadRequest(r)
+		if err != nil {
+			return err
+		}
+		if req.Method != http.MethodConnect || req.Host != "logs.example.com:514" ||
+			req.Header.Get("Proxy-Authorization") != "Basic dXNlcjpwYXNz" {
+			_, err = io.WriteString(conn, "HTTP/1.1 403 Forbidden\r\n\r\n")
+			return err
+		}
+		_, err = io.WriteString(conn, "HTTP/1.1 200 Connection established\r\n\r\n")
+		return err
+	})
+
+	w := NewNetWriter("tcp", "logs.example.com:514", NetWriterOptions{
+		Proxy: ProxyURL(&url.URL{Scheme: "http", Host: addr, User: url.UserPassword("user", "pass")}),
+	})
+	defer w.Close() //nolint: errcheck
+	_, _ = w.Write([]byte("hello\n"))
+
+	select {
+	case line := <-lines:
+		require.Equal(t, "hello\n", line)
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for record")
+	}
+}
+
+func TestNetWriterSOCKS5Proxy(t *testing.T) {
+	addr, lines := serveProxy(t, func(conn net.Conn, r *bufio.Reader) error {
+		greeting := make([]byte, 3)
+		if _, err := io.ReadFull(r, greeting); err != nil {
+			return err
+		}
+		_, _ = conn.Write([]byte{0x05, 0x02})
+
+		// Username and password.
+		head := make([]byte, 2)
+		_, _ = io.ReadFull(r, head)
+		user := make([]byte, head[1])
+		_, _ = io.ReadFull(r, user)
+		n, _ := r.ReadByte()
+		pass := make([]byte, n)
+		_, _ = io.ReadFull(r, pass)
+		if string(user) != "user" || string(pass) != "pass" {
+			_, _ = conn.Write([]byte{0x01, 0x01})
+			return io.ErrUnexpectedEOF
+		}
+		_, _ = conn.Write([]byte{0x01, 0x00})
+
+		// Connect request with a domain name.
+		req := make([]byte, 5)
+		_, _ = io.ReadFull(r, req)
+		host := make([]byte, req[4]+2)
+		_, _ = io.ReadFull(r, host)
+		if string(host[:req[4]]) != "logs.example.com" || host[req[4]] != 0x02 || host[req[4]+1] != 0x02 {
+			_, _ = conn.Write([]byte{0x05, 0x04, 0x00, 0x01, 0, 0, 0, 0, 0, 0})
+			return io.ErrUnexpectedEOF
+		}
+		_, err := conn.Write([]byte{0x05, 0x00, 0x00, 0x01, 127, 0, 0, 1, 0x04, 0x00})
+		return err
+	})
+
+	w := NewNetWriter("tcp", "logs.example.com:514", NetWriterOptions{
+		Proxy: ProxyURL(&url.URL{Scheme: "socks5", Host: addr, User: url.UserPassword("user", "pass")}),
+	})
+	defer w.Close() //nolint: errcheck
+	_, _ = w.Write([]byte("hello\n"))
+
+	select {
+	case line := <-lines:
+		require.Equal(t, "hello\n", line)
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for record")
+	}
+}
+
+func TestProxyFromEnvironment(t *testing.T) {
+	for _, k := range []string{"all_proxy", "https_proxy", "HTTPS_PROXY", "no_proxy"} {
+		t.Setenv(k, "")
+	}
+	t.Setenv("ALL_PROXY", "socks5://proxy:1080")
+	t.Setenv("NO_PROXY", "localhost, .internal.example.com,10.0.0.1:514")
+
+	cases := []struct {
+		addr     string
+		expected string
+	}{
+		{addr: "logs.example.com:514", expected: "socks5://proxy:1080"},
+		{addr: "localhost:514"},
+		{addr: "a.internal.example.com:514"},
+		{addr: "internal.example.com:514"},
+		{addr: "10.0.0.1:514"},
+		{addr: "notinternal.example.com:514", expected: "socks5://proxy:1080"},
+	}
+	for _, c := range cases {
+		u, err := ProxyFromEnvironment(c.addr)
+		require.NoError(t, err)
+		if c.expected == "" {
+			require.Nil(t, u, c.addr)
+			continue
+		}
+		require.Equal(t, c.expected, u.String(), c.addr)
+	}
+
+	t.Setenv("ALL_PROXY", "")
+	t.Setenv("HTTPS_PROXY", "proxy:3128")
+	u, err := ProxyFromEnvironment("logs.example.com:514")
+	require.NoError(t, err)
+	require.Equal(t, "http://proxy:3128", u.String())
+}
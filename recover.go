@@ -0,0 +1,82 @@
+package plog
+
+import (
+	"fmt"
+	"runtime"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// Recover recovers a panic and logs it at ErrorLevel with the panic value
+// under KeyPanic and the stack trace of the panicking goroutine under
+// KeyStack. The record caller is the function that panicked. It must be
+// deferred directly:
+//
+//	go func() {
+//		defer log.Recover(logger)
+//		...
+//	}()
+//
+// A nil logger logs to the default logger.
+func Recover(l *Logger) {
+	if v := recover(); v != nil {
+		logPanic(l, v)
+	}
+}
+
+// RecoverRepanic is like Recover, but panics again with the same value once
+// the panic is logged, e.g. to let the process crash after the record is
+// written. It must be deferred directly.
+func RecoverRepanic(l *Logger) {
+	if v := recover(); v != nil {
+		logPanic(l, v)
+		panic(v)
+	}
+}
+
+func logPanic(l *Logger, v interface{}) {
+	if l == nil {
+		l = Default()
+	}
+	if atomic.LoadUint32(&l.isDiscard) != 0 || atomic.LoadInt32(&l.level) > int32(ErrorLevel) {
+		return
+	}
+	frames, stack := PanicStack()
+	l.handle(ErrorLevel, l.timeFunc(time.Now()), frames, "panic", KeyPanic, v, KeyStack, stack)
+}
+
+// PanicStack returns the frames of the panicking goroutine, starting at the
+// function that panicked, and their formatted stack trace. It must be called
+// from a deferred function while panicking; it returns nothing otherwise.
+func PanicStack() ([]runtime.Frame, string) {
+	const maxStackLen = 64
+	var pc [maxStackLen]uintptr
+	n := runtime.Callers(2, pc[:])
+	frames := runtime.CallersFrames(pc[:n])
+
+	var (
+		out      []runtime.Frame
+		panicked bool
+	)
+	for {
+		f, more := frames.Next()
+		switch {
+		case !panicked:
+			panicked = f.Function == "runtime.gopanic"
+		case len(out) == 0 && strings.HasPrefix(f.Function, "runtime."):
+			// Skip the runtime panic helpers, e.g. runtime.panicIndex.
+		default:
+			out = append(out, f)
+		}
+		if !more {
+			break
+		}
+	}
+
+	var b strings.Builder
+	for _, f := range out {
+		fmt.Fprintf(&b, "%s\n\t%s:%d\n", f.Function, f.File, f.Line)
+	}
+	return out, strings.TrimSuffix(b.String(), "\n")
+}
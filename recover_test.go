@@ -0,0 +1,40 @@
+package plog
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecover(t *testing.T) {
+	var buf bytes.Buffer
+	l := NewWithOptions(&buf, Options{
+		Formatter:       JSONFormatter,
+		ReportCaller:    true,
+		CallerFormatter: func(_ string, _ int, fn string) string { return fn },
+	})
+
+	var s []int
+	func() {
+		defer Recover(l)
+		_ = s[1] //nolint: govet
+	}()
+
+	var m map[string]interface{}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &m))
+	require.Equal(t, "error", m["level"])
+	require.Equal(t, "panic", m["msg"])
+	require.Equal(t, "github.com/Malanris/plog.TestRecover.func2", m["caller"])
+	require.Contains(t, m[KeyPanic], "index out of range")
+	require.True(t, strings.HasPrefix(m[KeyStack].(string), "github.com/Malanris/plog.TestRecover.func2\n\t"))
+
+	buf.Reset()
+	require.PanicsWithValue(t, "boom", func() {
+		defer RecoverRepanic(l)
+		panic("boom")
+	})
+	require.Contains(t, buf.String(), `"panic":"boom"`)
+}
@@ -0,0 +1,94 @@
+package plog
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/charmbracelet/x/ansi"
+)
+
+// Sanitize is how the TextFormatter handles terminal control characters in
+// messages, prefixes, keys, and values, so attacker-controlled strings can't
+// manipulate the terminal of the viewer or forge log lines.
+type Sanitize uint8
+
+const (
+	// SanitizeEscape escapes control characters, e.g. ESC as \x1b and line
+	// breaks in messages as \n, so that a message can't forge log lines.
+	// Multiline values keep their lines, each marked as part of the value.
+	// This is the default.
+	SanitizeEscape Sanitize = iota
+	// SanitizeStrip removes ANSI escape sequences and other control
+	// characters. Line breaks in messages are escaped as with
+	// SanitizeEscape.
+	SanitizeStrip
+	// SanitizeOff writes messages, prefixes, and keys as is, for trusted
+	// pre-styled content. Values are escaped as with SanitizeEscape.
+	SanitizeOff
+	// SanitizeKeepNewlines is SanitizeEscape, keeping the line breaks of
+	// messages, for trusted multiline messages.
+	SanitizeKeepNewlines
+)
+
+// SetSanitize sets how the TextFormatter handles terminal control characters.
+func (l *Logger) SetSanitize(s Sanitize) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.sanitizeMode = s
+}
+
+// sanitize returns s with its control characters handled as set by
+// SetSanitize.
+func (l *Logger) sanitize(s string) string {
+	switch l.sanitizeMode {
+	case SanitizeOff:
+		return s
+	case SanitizeStrip:
+		return stripControl(s)
+	default:
+		return escapeStringForOutput(s, false)
+	}
+}
+
+// sanitizeMessage returns the message s with its control characters handled
+// as set by SetSanitize. Line breaks are escaped as \n unless sanitizing is
+// off or keeps them.
+func (l *Logger) sanitizeMessage(s string) string {
+	if l.sanitizeMode == SanitizeOff || !strings.Contains(s, "\n") {
+		return l.sanitize(s)
+	}
+	sep := `\n`
+	if l.sanitizeMode == SanitizeKeepNewlines {
+		sep = "\n"
+	}
+	lines := strings.Split(s, "\n")
+	for i, line := range lines {
+		lines[i] = l.sanitize(line)
+	}
+	return strings.Join(lines, sep)
+}
+
+// sanitizeValue returns the value s with its control characters handled as
+// set by SetSanitize. Escaping is left to the value quoting.
+func (l *Logger) sanitizeValue(s string) string {
+	if l.sanitizeMode != SanitizeStrip {
+		return s
+	}
+	// Keep the line breaks of multiline values.
+	lines := strings.Split(s, "\n")
+	for i, line := range lines {
+		lines[i] = stripControl(line)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// stripControl removes the ANSI escape sequences and control characters of s.
+func stripControl(s string) string {
+	s = ansi.Strip(s)
+	return strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) {
+			return -1
+		}
+		return r
+	}, s)
+}
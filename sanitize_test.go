@@ -0,0 +1,32 @@
+package plog
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSanitize(t *testing.T) {
+	evil := "ok\x1b[2J\nINFO forged"
+
+	var buf bytes.Buffer
+	l := NewWithOptions(&buf, Options{Prefix: "p\x1b[2J"})
+	l.Print(evil, "k", "v\x1b[31m")
+	require.Equal(t, `p\x1b[2J: ok\x1b[2J\nINFO forged k="v\x1b[31m"`+"\n", buf.String())
+
+	buf.Reset()
+	l.SetSanitize(SanitizeStrip)
+	l.Print(evil, "k", "v\x1b[31m")
+	require.Equal(t, `p: ok\nINFO forged k=v`+"\n", buf.String())
+
+	buf.Reset()
+	l.SetSanitize(SanitizeKeepNewlines)
+	l.Print(evil, "k", "v\x1b[31m")
+	require.Equal(t, `p\x1b[2J: ok\x1b[2J`+"\n"+`INFO forged k="v\x1b[31m"`+"\n", buf.String())
+
+	buf.Reset()
+	l.SetSanitize(SanitizeOff)
+	l.Print(evil, "k", "v\x1b[31m")
+	require.Equal(t, "p\x1b[2J: "+evil+` k="v\x1b[31m"`+"\n", buf.String())
+}
@@ -0,0 +1,25 @@
+package plog
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSchemaVersion(t *testing.T) {
+	var buf bytes.Buffer
+	l := NewWithOptions(&buf, Options{Formatter: JSONFormatter, ReportSchemaVersion: true})
+	l.Info("hello")
+	require.Equal(t, `{"schema_version":3,"level":"info","msg":"hello"}`+"\n", buf.String())
+
+	buf.Reset()
+	l.SetFormatter(LogfmtFormatter)
+	l.Info("hello")
+	require.Equal(t, "schema_version=3 level=info msg=hello\n", buf.String())
+
+	buf.Reset()
+	l.SetReportSchemaVersion(false)
+	l.Info("hello")
+	require.Equal(t, "level=info msg=hello\n", buf.String())
+}
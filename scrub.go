@@ -0,0 +1,88 @@
+package plog
+
+import (
+	"fmt"
+	"regexp"
+	"time"
+)
+
+// ScrubRule replaces the matches of Pattern with Replacement, which can
+// refer to submatches as in regexp.Regexp.ReplaceAllString.
+type ScrubRule struct {
+	Pattern     *regexp.Regexp
+	Replacement string
+}
+
+// Built-in scrub rules.
+var (
+	// ScrubEmails replaces email addresses.
+	ScrubEmails = ScrubRule{
+		Pattern:     regexp.MustCompile(`[a-zA-Z0-9._%+\-]+@[a-zA-Z0-9.\-]+\.[a-zA-Z]{2,}`),
+		Replacement: "[EMAIL]",
+	}
+	// ScrubBearerTokens replaces the tokens of bearer authorizations.
+	ScrubBearerTokens = ScrubRule{
+		Pattern:     regexp.MustCompile(`(?i)(bearer\s+)[a-z0-9\-._~+/]+=*`),
+		Replacement: "${1}[TOKEN]",
+	}
+	// ScrubCreditCards replaces credit card numbers, with digits optionally
+	// grouped by spaces or dashes.
+	ScrubCreditCards = ScrubRule{
+		Pattern:     regexp.MustCompile(`\b(?:\d[ \-]?){12,18}\d\b`),
+		Replacement: "[CARD]",
+	}
+)
+
+// DefaultScrubRules are the rules of a ScrubProcessor created without rules.
+var DefaultScrubRules = []ScrubRule{ScrubEmails, ScrubBearerTokens, ScrubCreditCards}
+
+// ScrubProcessor is a Processor applying regex replacements to the message
+// and values of records before they reach any output, for compliance with
+// data handling policies. Values other than strings are scrubbed in their
+// text form, and replaced by it when a rule matches. Numbers, booleans, and
+// times are left as is.
+type ScrubProcessor struct {
+	rules []ScrubRule
+}
+
+// NewScrubProcessor returns a new ScrubProcessor applying the given rules in
+// order. The default is DefaultScrubRules.
+func NewScrubProcessor(rules ...ScrubRule) *ScrubProcessor {
+	if len(rules) == 0 {
+		rules = DefaultScrubRules
+	}
+	return &ScrubProcessor{rules: rules}
+}
+
+// Process scrubs the message and values of the record.
+func (p *ScrubProcessor) Process(e *Entry) bool {
+	e.Message = p.Scrub(e.Message)
+	for i := 1; i < len(e.Keyvals); i += 2 {
+		var s string
+		switch v := e.Keyvals[i].(type) {
+		case string:
+			s = v
+		case nil, bool, int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64,
+			float32, float64, time.Time, time.Duration, Secret:
+			continue
+		case error:
+			s = v.Error()
+		case fmt.Stringer:
+			s = v.String()
+		default:
+			s = fmt.Sprintf("%+v", v)
+		}
+		if scrubbed := p.Scrub(s); scrubbed != s {
+			e.Keyvals[i] = scrubbed
+		}
+	}
+	return true
+}
+
+// Scrub returns s with the rules applied.
+func (p *ScrubProcessor) Scrub(s string) string {
+	for _, r := range p.rules {
+		s = r.Pattern.ReplaceAllString(s, r.Replacement)
+	}
+	return s
+}
@@ -0,0 +1,30 @@
+package plog
+
+import (
+	"bytes"
+	"errors"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestScrubProcessor(t *testing.T) {
+	var buf bytes.Buffer
+	l := NewWithOptions(&buf, Options{
+		Formatter:  LogfmtFormatter,
+		Processors: []Processor{NewScrubProcessor()},
+	})
+	l.Print("contact bob@example.com",
+		"auth", "Bearer abc.DEF-123=",
+		"card", "4111 1111 1111 1111",
+		"err", errors.New("charge 4111-1111-1111-1111 failed"),
+		"n", 4111111111111111,
+		"ok", "nothing here",
+	)
+	require.Equal(t, `msg="contact [EMAIL]" auth="Bearer [TOKEN]" card=[CARD] `+
+		`err="charge [CARD] failed" n=4111111111111111 ok="nothing here"`+"\n", buf.String())
+
+	p := NewScrubProcessor(ScrubRule{Pattern: regexp.MustCompile(`id=(\d+)`), Replacement: "id=<$1>"})
+	require.Equal(t, "user id=<42>", p.Scrub("user id=42"))
+}
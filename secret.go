@@ -0,0 +1,60 @@
+package plog
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// RedactedValue is how Secret values are rendered.
+const RedactedValue = "[REDACTED]"
+
+// Secret wraps a value that must never be logged, like a credential. It
+// always renders as RedactedValue, with every formatter and with fmt, so it
+// can be passed through logging call sites safely. Build with the
+// plog_revealsecrets tag to render the wrapped values, when debugging.
+type Secret struct {
+	value interface{}
+}
+
+// Redact returns v wrapped in a Secret.
+func Redact(v interface{}) Secret {
+	return Secret{value: v}
+}
+
+// Reveal returns the wrapped value.
+func (s Secret) Reveal() interface{} {
+	return s.value
+}
+
+// LogValue implements LogValuer.
+func (s Secret) LogValue() interface{} {
+	if revealSecrets {
+		return s.value
+	}
+	return RedactedValue
+}
+
+// String implements fmt.Stringer.
+func (s Secret) String() string {
+	return fmt.Sprint(s.LogValue())
+}
+
+// GoString implements fmt.GoStringer, so %#v doesn't reveal the value.
+func (s Secret) GoString() string {
+	return s.String()
+}
+
+// Format implements fmt.Formatter, so no verb reveals the value.
+func (s Secret) Format(f fmt.State, _ rune) {
+	_, _ = fmt.Fprint(f, s.LogValue())
+}
+
+// MarshalJSON implements json.Marshaler.
+func (s Secret) MarshalJSON() ([]byte, error) {
+	return json.Marshal(s.LogValue())
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (s Secret) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
@@ -0,0 +1,8 @@
+//go:build !plog_revealsecrets
+// +build !plog_revealsecrets
+
+package plog
+
+// revealSecrets is whether Secret values are rendered. Build with the
+// plog_revealsecrets tag to render them.
+const revealSecrets = false
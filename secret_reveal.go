@@ -0,0 +1,8 @@
+//go:build plog_revealsecrets
+// +build plog_revealsecrets
+
+package plog
+
+// revealSecrets is true with the plog_revealsecrets build tag: Secret values
+// are rendered, for debugging.
+const revealSecrets = true
@@ -0,0 +1,33 @@
+package plog
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSecret(t *testing.T) {
+	if revealSecrets {
+		t.Skip("secrets are revealed")
+	}
+	token := Redact("hunter2")
+	require.Equal(t, "hunter2", token.Reveal())
+
+	for _, f := range []Formatter{TextFormatter, JSONFormatter, LogfmtFormatter, CSVFormatter, MsgPackFormatter} {
+		var buf bytes.Buffer
+		l := NewWithOptions(&buf, Options{Formatter: f, CSV: &CSVOptions{Columns: []string{"token"}}})
+		l.With("cfg", map[string]interface{}{"token": token}).Print("login", "token", token)
+		require.NotContains(t, buf.String(), "hunter2", f)
+		require.Contains(t, buf.String(), "REDACTED", f)
+	}
+
+	for _, verb := range []string{"%v", "%+v", "%#v", "%s", "%q", "%x"} {
+		require.NotContains(t, fmt.Sprintf(verb, token), "hunter2", verb)
+	}
+	b, err := json.Marshal(struct{ T Secret }{token})
+	require.NoError(t, err)
+	require.Equal(t, `{"T":"[REDACTED]"}`, string(b))
+}
@@ -0,0 +1,256 @@
+package plog
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	mrand "math/rand"
+	"net/http"
+	"net/url"
+	"reflect"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// DefaultSentryFlushTimeout is the default time Flush waits for pending events
+// to be sent.
+const DefaultSentryFlushTimeout = 2 * time.Second
+
+// ErrInvalidDSN is returned when a Sentry DSN can't be parsed.
+var ErrInvalidDSN = errors.New("invalid sentry dsn")
+
+// pkgPath is the import path of this package, used to skip logger frames in
+// captured stack traces.
+var pkgPath = reflect.TypeOf(Logger{}).PkgPath()
+
+// SentryOptions are the options for a SentryProcessor.
+type SentryOptions struct {
+	// DSN is the Sentry project DSN.
+	DSN string
+	// SampleRate is the fraction of records forwarded, between 0 and 1. The
+	// default (0) forwards all records.
+	SampleRate float64
+	// Environment is the environment reported with events.
+	Environment string
+	// Release is the release reported with events.
+	Release string
+	// Client is the HTTP client used to send events. The default is
+	// http.DefaultClient.
+	Client *http.Client
+	// FlushTimeout is the time Flush waits for pending events to be sent.
+	// The default is DefaultSentryFlushTimeout.
+	FlushTimeout time.Duration
+}
+
+// SentryProcessor is a Processor that forwards error and fatal records as
+// Sentry events, including their keyvals and the stack trace of the logging
+// call. Events are sent in the background; records are never dropped from
+// the log.
+//
+// Fatal flushes pending events before exiting.
+type SentryProcessor struct {
+	opts     SentryOptions
+	endpoint string
+	poster   *poster
+	rand     func() float64
+}
+
+// NewSentryProcessor returns a new SentryProcessor.
+func NewSentryProcessor(o SentryOptions) (*SentryProcessor, error) {
+	u, err := url.Parse(o.DSN)
+	if err != nil || u.User == nil || u.User.Username() == "" || u.Host == "" {
+		return nil, fmt.Errorf("%w: %q", ErrInvalidDSN, o.DSN)
+	}
+	idx := strings.LastIndexByte(u.Path, '/')
+	if idx == -1 || idx == len(u.Path)-1 {
+		return nil, fmt.Errorf("%w: %q", ErrInvalidDSN, o.DSN)
+	}
+	path, project := u.Path[:idx], u.Path[idx+1:]
+
+	if o.SampleRate <= 0 || o.SampleRate > 1 {
+		o.SampleRate = 1
+	}
+	if o.FlushTimeout <= 0 {
+		o.FlushTimeout = DefaultSentryFlushTimeout
+	}
+
+	header := http.Header{}
+	header.Set("Content-Type", "application/json")
+	header.Set("X-Sentry-Auth", fmt.Sprintf(
+		"Sentry sentry_version=7, sentry_client=plog/1.0, sentry_key=%s", u.User.Username()))
+	return &SentryProcessor{
+		opts:     o,
+		endpoint: fmt.Sprintf("%s://%s%s/api/%s/store/", u.Scheme, u.Host, path, project),
+		poster:   newPoster(o.Client, o.FlushTimeout, header),
+		rand:     mrand.Float64, //nolint: gosec
+	}, nil
+}
+
+// Process forwards the entry to Sentry if it's an error or fatal record and
+// is sampled in.
+func (p *SentryProcessor) Process(e *Entry) bool {
+	if e.Level < ErrorLevel || e.Level == noLevel {
+		return true
+	}
+	if p.opts.SampleRate < 1 && p.rand() >= p.opts.SampleRate {
+		return true
+	}
+
+	body, err := json.Marshal(p.event(e))
+	if err == nil {
+		// Don't block logging when Sentry can't keep up.
+		p.poster.post(p.endpoint, body)
+	}
+	return true
+}
+
+// Flush waits for pending events to be sent, up to the flush timeout.
+func (p *SentryProcessor) Flush() {
+	p.poster.flush()
+}
+
+type sentryEvent struct {
+	EventID     string                 `json:"event_id"`
+	Timestamp   string                 `json:"timestamp"`
+	Level       string                 `json:"level"`
+	Logger      string                 `json:"logger,omitempty"`
+	Platform    string                 `json:"platform"`
+	Message     string                 `json:"message,omitempty"`
+	Environment string                 `json:"environment,omitempty"`
+	Release     string                 `json:"release,omitempty"`
+	Extra       map[string]interface{} `json:"extra,omitempty"`
+	Exception   *sentryValues          `json:"exception,omitempty"`
+	Threads     *sentryValues          `json:"threads,omitempty"`
+}
+
+type sentryValues struct {
+	Values []sentryException `json:"values"`
+}
+
+type sentryException struct {
+	Type       string            `json:"type,omitempty"`
+	Value      string            `json:"value,omitempty"`
+	Current    bool              `json:"current,omitempty"`
+	Stacktrace *sentryStacktrace `json:"stacktrace,omitempty"`
+}
+
+type sentryStacktrace struct {
+	Frames []sentryFrame `json:"frames"`
+}
+
+type sentryFrame struct {
+	Function string `json:"function"`
+	Module   string `json:"module,omitempty"`
+	AbsPath  string `json:"abs_path"`
+	Lineno   int    `json:"lineno"`
+	InApp    bool   `json:"in_app"`
+}
+
+func sentryLevel(level Level) string {
+	switch {
+	case level >= FatalLevel:
+		return "fatal"
+	case level >= ErrorLevel:
+		return "error"
+	case level >= WarnLevel:
+		return "warning"
+	case level >= InfoLevel:
+		return "info"
+	default:
+		return "debug"
+	}
+}
+
+func (p *SentryProcessor) event(e *Entry) sentryEvent {
+	var id [16]byte
+	_, _ = rand.Read(id[:])
+	ts := e.Time
+	if ts.IsZero() {
+		ts = time.Now()
+	}
+	ev := sentryEvent{
+		EventID:     hex.EncodeToString(id[:]),
+		Timestamp:   ts.UTC().Format(time.RFC3339Nano),
+		Level:       sentryLevel(e.Level),
+		Logger:      e.Prefix,
+		Platform:    "go",
+		Message:     e.Message,
+		Environment: p.opts.Environment,
+		Release:     p.opts.Release,
+	}
+
+	var errs []error
+	if len(e.Keyvals) > 0 {
+		ev.Extra = make(map[string]interface{}, len(e.Keyvals)/2)
+	}
+	for i := 0; i+1 < len(e.Keyvals); i += 2 {
+		v := e.Keyvals[i+1]
+		if err, ok := v.(error); ok {
+			errs = append(errs, err)
+			v = err.Error()
+		} else if _, err := json.Marshal(v); err != nil {
+			v = fmt.Sprintf("%+v", v)
+		}
+		ev.Extra[fmt.Sprint(e.Keyvals[i])] = v
+	}
+
+	stack := &sentryStacktrace{Frames: sentryFrames()}
+	if len(errs) > 0 {
+		ev.Exception = &sentryValues{}
+		for i, err := range errs {
+			ex := sentryException{Type: fmt.Sprintf("%T", err), Value: err.Error()}
+			if i == len(errs)-1 {
+				ex.Stacktrace = stack
+			}
+			ev.Exception.Values = append(ev.Exception.Values, ex)
+		}
+	} else {
+		ev.Threads = &sentryValues{Values: []sentryException{{Current: true, Stacktrace: stack}}}
+	}
+	return ev
+}
+
+// sentryFrames returns the frames of the current goroutine stack, excluding
+// the logger frames, ordered from the outermost call as Sentry expects.
+func sentryFrames() []sentryFrame {
+	const maxStackLen = 50
+	var pc [maxStackLen]uintptr
+	n := runtime.Callers(2, pc[:])
+	frames := runtime.CallersFrames(pc[:n])
+
+	var out []sentryFrame
+	for {
+		f, more := frames.Next()
+		if !strings.HasPrefix(f.Function, pkgPath+".") {
+			module, fn := splitFuncName(f.Function)
+			out = append(out, sentryFrame{
+				Function: fn,
+				Module:   module,
+				AbsPath:  f.File,
+				Lineno:   f.Line,
+				InApp:    !strings.HasPrefix(module, "runtime") && !strings.HasPrefix(module, "testing"),
+			})
+		}
+		if !more {
+			break
+		}
+	}
+	for i, j := 0, len(out)-1; i < j; i, j = i+1, j-1 {
+		out[i], out[j] = out[j], out[i]
+	}
+	return out
+}
+
+// splitFuncName splits a fully qualified function name into its package path
+// and function name.
+func splitFuncName(name string) (string, string) {
+	slash := strings.LastIndexByte(name, '/')
+	dot := strings.IndexByte(name[slash+1:], '.')
+	if dot == -1 {
+		return "", name
+	}
+	return name[:slash+1+dot], name[slash+1+dot+1:]
+}
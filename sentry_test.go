@@ -0,0 +1,122 @@
+package plog
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSentryProcessor(t *testing.T) {
+	var (
+		mu     sync.Mutex
+		events []map[string]interface{}
+		paths  []string
+		auths  []string
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var ev map[string]interface{}
+		_ = json.NewDecoder(r.Body).Decode(&ev)
+		mu.Lock()
+		events = append(events, ev)
+		paths = append(paths, r.URL.Path)
+		auths = append(auths, r.Header.Get("X-Sentry-Auth"))
+		mu.Unlock()
+	}))
+	defer srv.Close()
+
+	dsn := strings.Replace(srv.URL, "http://", "http://public@", 1) + "/42"
+	p, err := NewSentryProcessor(SentryOptions{DSN: dsn, Environment: "test"})
+	require.NoError(t, err)
+
+	l := New(discardWriter{})
+	l.SetPrefix("oven")
+	l.AddProcessor(p)
+	l.Info("ignored")
+	l.Error("burnt", "temp", 500, "err", errors.New("too hot"))
+	l.Flush()
+
+	mu.Lock()
+	defer mu.Unlock()
+	require.Len(t, events, 1)
+	require.Equal(t, "/api/42/store/", paths[0])
+	require.Contains(t, auths[0], "sentry_key=public")
+
+	ev := events[0]
+	require.Equal(t, "error", ev["level"])
+	require.Equal(t, "burnt", ev["message"])
+	require.Equal(t, "oven", ev["logger"])
+	require.Equal(t, "test", ev["environment"])
+	require.Equal(t, map[string]interface{}{"temp": float64(500), "err": "too hot"}, ev["extra"])
+
+	ex := ev["exception"].(map[string]interface{})["values"].([]interface{})[0].(map[string]interface{})
+	require.Equal(t, "too hot", ex["value"])
+	require.Equal(t, "*errors.errorString", ex["type"])
+	require.NotEmpty(t, ex["stacktrace"].(map[string]interface{})["frames"])
+}
+
+func TestSentryProcessorConcurrentFlush(t *testing.T) {
+	var (
+		mu sync.Mutex
+		n  int
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		n++
+		mu.Unlock()
+	}))
+	defer srv.Close()
+
+	dsn := strings.Replace(srv.URL, "http://", "http://public@", 1) + "/42"
+	p, err := NewSentryProcessor(SentryOptions{DSN: dsn})
+	require.NoError(t, err)
+
+	l := New(discardWriter{})
+	l.AddProcessor(p)
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 5; j++ {
+				l.Error("burnt")
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			l.Flush()
+		}()
+	}
+	wg.Wait()
+	l.Flush()
+
+	mu.Lock()
+	defer mu.Unlock()
+	require.Equal(t, 20, n)
+}
+
+func TestSentryProcessorSampling(t *testing.T) {
+	p, err := NewSentryProcessor(SentryOptions{DSN: "https://key@sentry.example.com/1", SampleRate: 0.5})
+	require.NoError(t, err)
+	p.rand = func() float64 { return 0.9 }
+
+	e := &Entry{Level: ErrorLevel, Message: "sampled out"}
+	require.True(t, p.Process(e))
+	require.Empty(t, p.poster.queue)
+}
+
+func TestSentryDSN(t *testing.T) {
+	for _, dsn := range []string{"", "https://sentry.example.com/1", "https://key@sentry.example.com/", "::"} {
+		_, err := NewSentryProcessor(SentryOptions{DSN: dsn})
+		require.ErrorIs(t, err, ErrInvalidDSN, dsn)
+	}
+
+	p, err := NewSentryProcessor(SentryOptions{DSN: "https://key@sentry.example.com/path/7"})
+	require.NoError(t, err)
+	require.Equal(t, "https://sentry.example.com/path/api/7/store/", p.endpoint)
+}
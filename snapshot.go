@@ -0,0 +1,121 @@
+package plog
+
+import (
+	"sync"
+	"time"
+)
+
+const (
+	snapshotWindow  = 5 * time.Minute
+	snapshotBuckets = 60
+	snapshotBucket  = snapshotWindow / snapshotBuckets
+)
+
+// Snapshot is a point-in-time summary of the records a logger has logged.
+// It's meant to back health endpoints, e.g. to report a service as degraded
+// when it has been logging errors recently.
+type Snapshot struct {
+	// Since is when the logger was created, or when the last record at
+	// FatalLevel was logged without exiting, whichever is later.
+	Since time.Time
+	// Uptime is the time elapsed since Since.
+	Uptime time.Duration
+	// Counts is the number of records logged per level.
+	Counts map[Level]uint64
+	// RecentErrors is the number of records at ErrorLevel or above logged in
+	// the last five minutes.
+	RecentErrors uint64
+	// LastError is the last record logged at ErrorLevel or above. It's nil if
+	// no such record was logged.
+	LastError *Entry
+}
+
+// stats keeps track of the records logged by a logger and the loggers derived
+// from it.
+type stats struct {
+	mu  sync.Mutex
+	now func() time.Time
+
+	start     time.Time
+	since     time.Time
+	counts    map[Level]uint64
+	lastError *Entry
+
+	// errors is a ring of error counts, one per snapshotBucket, with epochs
+	// holding the bucket number each count belongs to.
+	errors [snapshotBuckets]uint64
+	epochs [snapshotBuckets]int64
+}
+
+func newStats() *stats {
+	s := &stats{
+		now:    time.Now,
+		counts: map[Level]uint64{},
+	}
+	s.start = s.now()
+	s.since = s.start
+	return s
+}
+
+func (s *stats) record(e *Entry) {
+	if s == nil || e.Level == noLevel {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	s.counts[e.Level]++
+	if e.Level >= FatalLevel {
+		s.since = now
+	}
+	if e.Level >= ErrorLevel {
+		last := *e
+		s.lastError = &last
+
+		epoch := now.UnixNano() / int64(snapshotBucket)
+		i := epoch % snapshotBuckets
+		if s.epochs[i] != epoch {
+			s.epochs[i] = epoch
+			s.errors[i] = 0
+		}
+		s.errors[i]++
+	}
+}
+
+func (s *stats) snapshot() Snapshot {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	snap := Snapshot{
+		Since:  s.since,
+		Uptime: now.Sub(s.since),
+		Counts: make(map[Level]uint64, len(s.counts)),
+	}
+	for level, n := range s.counts {
+		snap.Counts[level] = n
+	}
+	if s.lastError != nil {
+		last := *s.lastError
+		snap.LastError = &last
+	}
+
+	epoch := now.UnixNano() / int64(snapshotBucket)
+	for i, n := range s.errors {
+		if epoch-s.epochs[i] < snapshotBuckets {
+			snap.RecentErrors += n
+		}
+	}
+	return snap
+}
+
+// Snapshot returns a summary of the records logged so far. Loggers derived
+// using With share the same counters as their parent.
+func (l *Logger) Snapshot() Snapshot {
+	if l.stats == nil {
+		return Snapshot{Counts: map[Level]uint64{}}
+	}
+	return l.stats.snapshot()
+}
@@ -0,0 +1,56 @@
+package plog
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSnapshot(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	l := New(discardWriter{})
+	l.stats.now = func() time.Time { return now }
+	l.stats.since = now
+
+	snap := l.Snapshot()
+	require.Nil(t, snap.LastError)
+	require.Zero(t, snap.RecentErrors)
+	require.Empty(t, snap.Counts)
+
+	sub := l.With("component", "oven")
+	l.Info("info")
+	l.Warn("warn")
+	sub.Error("burnt", "err", errors.New("too hot"))
+	l.Print("print")
+
+	now = now.Add(time.Minute)
+	snap = l.Snapshot()
+	require.Equal(t, time.Minute, snap.Uptime)
+	require.Equal(t, map[Level]uint64{InfoLevel: 1, WarnLevel: 1, ErrorLevel: 1}, snap.Counts)
+	require.Equal(t, uint64(1), snap.RecentErrors)
+	require.NotNil(t, snap.LastError)
+	require.Equal(t, "burnt", snap.LastError.Message)
+	require.Equal(t, ErrorLevel, snap.LastError.Level)
+	require.Equal(t, []interface{}{"component", "oven", "err", errors.New("too hot")}, snap.LastError.Keyvals)
+
+	// Errors fall out of the recent window.
+	now = now.Add(snapshotWindow)
+	snap = l.Snapshot()
+	require.Zero(t, snap.RecentErrors)
+	require.NotNil(t, snap.LastError)
+
+	// A fatal record that didn't exit resets the start time.
+	l.Log(FatalLevel, "fatal")
+	snap = l.Snapshot()
+	require.Equal(t, now, snap.Since)
+	require.Zero(t, snap.Uptime)
+	require.Equal(t, uint64(1), snap.RecentErrors)
+}
+
+// discardWriter is an io.Writer that discards everything, unlike io.Discard
+// it doesn't short-circuit the logger.
+type discardWriter struct{}
+
+func (discardWriter) Write(p []byte) (int, error) { return len(p), nil }
@@ -0,0 +1,45 @@
+package plog
+
+import (
+	"fmt"
+	"strings"
+)
+
+// WithStacktrace returns a new logger that attaches the stack trace of the
+// logging goroutine under KeyStack to records at or above minLevel. The trace
+// starts at the caller, skipping Helper() functions, and is rendered as an
+// indented multiline value by the TextFormatter.
+func (l *Logger) WithStacktrace(minLevel Level) *Logger {
+	sl := l.With()
+	sl.stackLevel = &minLevel
+	return sl
+}
+
+// wantsStack returns whether records at level get a stack trace.
+func (l *Logger) wantsStack(level Level) bool {
+	return l.stackLevel != nil && level >= *l.stackLevel && level != noLevel
+}
+
+// stacktrace returns the formatted stack trace of the caller, skipping the
+// logger methods and helpers at its top.
+func (l *Logger) stacktrace(skip int) string {
+	frames := l.frames(skip + 1)
+	var (
+		b   strings.Builder
+		top = true
+	)
+	for {
+		f, more := frames.Next()
+		if top {
+			_, helper := l.helpers.Load(f.Function)
+			top = helper || strings.HasPrefix(f.Function, pkgPath+".(*Logger).")
+		}
+		if !top {
+			fmt.Fprintf(&b, "%s\n\t%s:%d\n", f.Function, f.File, f.Line)
+		}
+		if !more {
+			break
+		}
+	}
+	return strings.TrimSuffix(b.String(), "\n")
+}
@@ -0,0 +1,44 @@
+package plog
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithStacktrace(t *testing.T) {
+	var buf bytes.Buffer
+	l := NewWithOptions(&buf, Options{Formatter: JSONFormatter}).WithStacktrace(WarnLevel)
+
+	decode := func() map[string]interface{} {
+		t.Helper()
+		var m map[string]interface{}
+		require.NoError(t, json.Unmarshal(buf.Bytes(), &m))
+		buf.Reset()
+		return m
+	}
+
+	l.Info("fine")
+	require.NotContains(t, decode(), KeyStack)
+
+	l.Print("no level")
+	require.NotContains(t, decode(), KeyStack)
+
+	logWarn := func() {
+		l.Helper()
+		l.Warn("careful", "odd")
+	}
+	logWarn()
+	m := decode()
+	require.Equal(t, "missing value", m["odd"])
+	stack, _ := m[KeyStack].(string)
+	require.True(t, strings.HasPrefix(stack, pkgPath+".TestWithStacktrace\n"), stack)
+	require.NotContains(t, stack, "(*Logger)")
+
+	l.Error("failed")
+	stack, _ = decode()[KeyStack].(string)
+	require.True(t, strings.HasPrefix(stack, pkgPath+".TestWithStacktrace\n"), stack)
+}
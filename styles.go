@@ -26,12 +26,34 @@ type Styles struct {
 	// Value is the style for values.
 	Value lipgloss.Style
 
+	// Error is the style for ErrorKey values.
+	Error lipgloss.Style
+
 	// Separator is the style for separators.
 	Separator lipgloss.Style
 
 	// Levels are the styles for each level.
 	Levels map[Level]lipgloss.Style
 
+	// LevelLabels override the labels of the level styles, e.g. "WRN" or a
+	// localized word. The labels are still bound by the style widths.
+	LevelLabels map[Level]string
+
+	// LevelIcons are the icons of each level, rendered with the foreground
+	// color of the level style according to IconMode.
+	LevelIcons map[Level]string
+
+	// IconMode is how the level icons are rendered. The default is IconsOff.
+	IconMode IconMode
+
+	// Types are the styles of the values by type, used when type colors are
+	// enabled with SetTypeColors.
+	Types TypeStyles
+
+	// KeyPalette are the colors of the keys, used when key colors are enabled
+	// with SetKeyColors.
+	KeyPalette []lipgloss.TerminalColor
+
 	// Keys overrides styles for specific keys.
 	Keys map[string]lipgloss.Style
 
@@ -39,7 +61,47 @@ type Styles struct {
 	Values map[string]lipgloss.Style
 }
 
-// DefaultStyles returns the default styles.
+// TypeStyles defines the styles of the values by type.
+type TypeStyles struct {
+	// Number is the style for integers and floats.
+	Number lipgloss.Style
+
+	// Bool is the style for booleans.
+	Bool lipgloss.Style
+
+	// Duration is the style for durations.
+	Duration lipgloss.Style
+
+	// Error is the style for errors.
+	Error lipgloss.Style
+
+	// String is the style for strings.
+	String lipgloss.Style
+
+	// Nil is the style for nil values.
+	Nil lipgloss.Style
+}
+
+// StyleFunc returns the style of a value of the TextFormatter, given its key
+// and the level of the record, e.g. to color status codes >= 500 in red. The
+// returned style inherits the unset properties from the value style, so an
+// empty style keeps it. Print records have a level above FatalLevel.
+type StyleFunc func(key string, value interface{}, level Level) lipgloss.Style
+
+// IconMode is how the level icons are rendered.
+type IconMode uint8
+
+const (
+	// IconsOff renders the level labels only.
+	IconsOff IconMode = iota
+	// IconsOnly renders the level icons instead of the labels.
+	IconsOnly
+	// IconsWithLabels renders the level icons followed by the labels.
+	IconsWithLabels
+)
+
+// DefaultStyles returns the default styles. Their colors adapt to the
+// background of the terminal, see SetHasDarkBackground.
 func DefaultStyles() *Styles {
 	return &Styles{
 		Timestamp: lipgloss.NewStyle(),
@@ -48,44 +110,68 @@ func DefaultStyles() *Styles {
 		Message:   lipgloss.NewStyle(),
 		Key:       lipgloss.NewStyle().Faint(true),
 		Value:     lipgloss.NewStyle(),
+		Error:     lipgloss.NewStyle().Foreground(lipgloss.AdaptiveColor{Light: "161", Dark: "204"}),
 		Separator: lipgloss.NewStyle().Faint(true),
 		Levels: map[Level]lipgloss.Style{
 			DebugLevel: lipgloss.NewStyle().
 				SetString(strings.ToUpper(DebugLevel.String())).
 				Bold(true).
 				MaxWidth(5).
-				Foreground(lipgloss.Color("63")).
+				Foreground(lipgloss.AdaptiveColor{Light: "56", Dark: "63"}).
 				Width(5).
 				Align(lipgloss.Right),
 			InfoLevel: lipgloss.NewStyle().
 				SetString(strings.ToUpper(InfoLevel.String())).
 				Bold(true).
 				MaxWidth(5).
-				Foreground(lipgloss.Color("86")).
+				Foreground(lipgloss.AdaptiveColor{Light: "30", Dark: "86"}).
 				Width(5).
 				Align(lipgloss.Right),
 			WarnLevel: lipgloss.NewStyle().
 				SetString(strings.ToUpper(WarnLevel.String())).
 				Bold(true).
 				MaxWidth(5).
-				Foreground(lipgloss.Color("192")).
+				Foreground(lipgloss.AdaptiveColor{Light: "136", Dark: "192"}).
 				Width(5).
 				Align(lipgloss.Right),
 			ErrorLevel: lipgloss.NewStyle().
 				SetString(strings.ToUpper(ErrorLevel.String())).
 				Bold(true).
 				MaxWidth(5).
-				Foreground(lipgloss.Color("204")).
+				Foreground(lipgloss.AdaptiveColor{Light: "161", Dark: "204"}).
 				Width(5).
 				Align(lipgloss.Right),
 			FatalLevel: lipgloss.NewStyle().
 				SetString(strings.ToUpper(FatalLevel.String())).
 				Bold(true).
 				MaxWidth(5).
-				Foreground(lipgloss.Color("134")).
+				Foreground(lipgloss.AdaptiveColor{Light: "91", Dark: "134"}).
 				Width(5).
 				Align(lipgloss.Right),
 		},
+		LevelIcons: map[Level]string{
+			DebugLevel: "•",
+			InfoLevel:  "✓",
+			WarnLevel:  "⚠",
+			ErrorLevel: "✗",
+			FatalLevel: "☠",
+		},
+		Types: TypeStyles{
+			Number:   lipgloss.NewStyle().Foreground(lipgloss.AdaptiveColor{Light: "25", Dark: "75"}),
+			Bool:     lipgloss.NewStyle().Foreground(lipgloss.AdaptiveColor{Light: "127", Dark: "177"}),
+			Duration: lipgloss.NewStyle().Foreground(lipgloss.AdaptiveColor{Light: "136", Dark: "192"}),
+			Error:    lipgloss.NewStyle().Foreground(lipgloss.AdaptiveColor{Light: "161", Dark: "204"}),
+			String:   lipgloss.NewStyle().Foreground(lipgloss.AdaptiveColor{Light: "28", Dark: "114"}),
+			Nil:      lipgloss.NewStyle().Faint(true),
+		},
+		KeyPalette: []lipgloss.TerminalColor{
+			lipgloss.AdaptiveColor{Light: "25", Dark: "75"},
+			lipgloss.AdaptiveColor{Light: "28", Dark: "114"},
+			lipgloss.AdaptiveColor{Light: "127", Dark: "177"},
+			lipgloss.AdaptiveColor{Light: "136", Dark: "180"},
+			lipgloss.AdaptiveColor{Light: "30", Dark: "80"},
+			lipgloss.AdaptiveColor{Light: "91", Dark: "141"},
+		},
 		Keys:   map[string]lipgloss.Style{},
 		Values: map[string]lipgloss.Style{},
 	}
@@ -0,0 +1,74 @@
+package plog
+
+import (
+	"os"
+	"sync/atomic"
+	"time"
+)
+
+// ShutdownMessage is the message of the shutdown summary record.
+const ShutdownMessage = "shutdown"
+
+// Shutdown summary record keys.
+const (
+	KeyUptime   = "uptime"
+	KeyErrors   = "errors"
+	KeyWarnings = "warnings"
+	KeyDropped  = "dropped"
+)
+
+// SetShutdownSummary sets whether Shutdown and Fatal log a shutdown summary
+// record first. See LogShutdownSummary.
+func (l *Logger) SetShutdownSummary(enabled bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.shutdownSummary = enabled
+}
+
+// LogShutdownSummary logs a record summarizing the process run: the uptime
+// of the logger, the number of records logged at ErrorLevel or above and at
+// WarnLevel, and the number of records dropped by the async mode. Loggers
+// derived using With share the same counters as their parent.
+//
+// The record is logged at InfoLevel regardless of the logger level, so that
+// every run ends with a summary.
+func (l *Logger) LogShutdownSummary() {
+	if atomic.LoadUint32(&l.isDiscard) != 0 {
+		return
+	}
+
+	var uptime time.Duration
+	var errors, warnings uint64
+	if l.stats != nil {
+		snap := l.stats.snapshot()
+		uptime = time.Since(l.stats.start).Round(time.Millisecond)
+		for level, n := range snap.Counts {
+			switch {
+			case level >= ErrorLevel:
+				errors += n
+			case level == WarnLevel:
+				warnings += n
+			}
+		}
+	}
+
+	l.handle(InfoLevel, l.timeFunc(time.Now()), nil, ShutdownMessage,
+		KeyUptime, uptime,
+		KeyErrors, errors,
+		KeyWarnings, warnings,
+		KeyDropped, l.AsyncDropped(),
+	)
+}
+
+// exit logs the shutdown summary if enabled, flushes the logger, and exits
+// the process with status 1. It's the end of the Fatal paths.
+func (l *Logger) exit() {
+	l.mu.RLock()
+	summary := l.shutdownSummary
+	l.mu.RUnlock()
+	if summary {
+		l.LogShutdownSummary()
+	}
+	l.Flush()
+	os.Exit(1)
+}
@@ -0,0 +1,40 @@
+package plog
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestShutdownSummary(t *testing.T) {
+	var buf bytes.Buffer
+	l := NewWithOptions(&buf, Options{
+		Formatter:       JSONFormatter,
+		Level:           WarnLevel,
+		ShutdownSummary: true,
+	})
+	sub := l.With("component", "db")
+	l.Warn("slow")
+	sub.Error("failed")
+	sub.Error("failed again")
+	l.Info("filtered")
+	buf.Reset()
+
+	require.NoError(t, l.Shutdown(context.Background()))
+	var m map[string]interface{}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &m))
+	require.Equal(t, "info", m[LevelKey])
+	require.Equal(t, ShutdownMessage, m[MessageKey])
+	require.Equal(t, float64(2), m[KeyErrors])
+	require.Equal(t, float64(1), m[KeyWarnings])
+	require.Equal(t, float64(0), m[KeyDropped])
+	require.Contains(t, m, KeyUptime)
+
+	buf.Reset()
+	l.SetShutdownSummary(false)
+	require.NoError(t, l.Shutdown(context.Background()))
+	require.Empty(t, buf.String())
+}
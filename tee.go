@@ -0,0 +1,61 @@
+package plog
+
+import (
+	"bytes"
+	"io"
+)
+
+// TeeWriter is an io.Writer duplicating its writes to several writers, like
+// io.MultiWriter. When it is the output of a logger, the TextFormatter
+// renders the records once per writer, styling them according to each
+// writer, so a terminal copy is colored while a file copy is plain.
+type TeeWriter struct {
+	writers []io.Writer
+}
+
+// NewTeeWriter returns a TeeWriter duplicating its writes to writers.
+func NewTeeWriter(writers ...io.Writer) *TeeWriter {
+	return &TeeWriter{writers: append([]io.Writer(nil), writers...)}
+}
+
+// Write writes p to each writer, stopping at the first error.
+func (t *TeeWriter) Write(p []byte) (int, error) {
+	for _, w := range t.writers {
+		n, err := w.Write(p)
+		if err != nil {
+			return n, err
+		}
+		if n != len(p) {
+			return n, io.ErrShortWrite
+		}
+	}
+	return len(p), nil
+}
+
+// Flush flushes the writers buffering records.
+func (t *TeeWriter) Flush() {
+	for _, w := range t.writers {
+		flushOutput(w)
+	}
+}
+
+// teeing reports whether the records are rendered once per writer of a
+// TeeWriter output.
+func (l *Logger) teeing() bool {
+	return len(l.teeRenderers) > 0 && l.formatter == TextFormatter
+}
+
+// teeRecords formats the records once per writer of the TeeWriter output,
+// using the renderer of each writer.
+func (l *Logger) teeRecords(b *bytes.Buffer, records [][]interface{}) []asyncRecord {
+	// Format with a copy of the logger, which is only read locked.
+	tl := *l
+	t := l.w.(*TeeWriter)
+	rs := make([]asyncRecord, len(t.writers))
+	for i, w := range t.writers {
+		tl.re = l.teeRenderers[i]
+		tl.formatRecords(b, l.formatter, true, records)
+		rs[i] = asyncRecord{w: w, p: takeBuffer(b), mu: l.wmu}
+	}
+	return rs
+}
@@ -0,0 +1,37 @@
+package plog
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTeeWriter(t *testing.T) {
+	t.Setenv("TERM", "xterm-256color")
+	t.Setenv("NO_COLOR", "")
+	var term, file bytes.Buffer
+	tty := func(w io.Writer) bool { return w == &term }
+	l := NewWithOptions(NewTeeWriter(&term, &file), Options{TerminalCheck: tty})
+	l.Print("hi", ErrorKey, "x")
+	require.Equal(t, "hi error=x\n", file.String())
+	require.Contains(t, term.String(), "\x1b[")
+
+	term.Reset()
+	file.Reset()
+	l.SetFormatter(LogfmtFormatter)
+	l.Print("hi")
+	require.Equal(t, "msg=hi\n", file.String())
+	require.Equal(t, "msg=hi\n", term.String())
+}
+
+func TestTeeWriterAsync(t *testing.T) {
+	var term, file bytes.Buffer
+	l := NewWithOptions(NewTeeWriter(&term, &file), Options{Async: &AsyncOptions{}})
+	l.Print("hi")
+	require.NoError(t, l.Shutdown(context.Background()))
+	require.Equal(t, "hi\n", file.String())
+	require.Equal(t, "hi\n", term.String())
+}
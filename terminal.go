@@ -0,0 +1,56 @@
+package plog
+
+import (
+	"io"
+	"os"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
+)
+
+// TerminalCheck reports whether w is a terminal. Styles are disabled when
+// the output isn't one.
+type TerminalCheck func(w io.Writer) bool
+
+// SetTerminalCheck sets the function deciding whether the output is a
+// terminal, replacing the automatic detection. It lets environments with
+// pseudo-TTYs, Windows named pipes, or test harnesses control whether styles
+// are disabled deterministically. A nil f restores the automatic detection.
+func (l *Logger) SetTerminalCheck(f TerminalCheck) {
+	l.mu.Lock()
+	l.terminalCheck = f
+	w := l.w
+	l.mu.Unlock()
+	l.SetOutput(w)
+}
+
+// WithTerminalCheck returns a new logger using f to decide whether the
+// output is a terminal. See SetTerminalCheck.
+func (l *Logger) WithTerminalCheck(f TerminalCheck) *Logger {
+	sl := l.With()
+	sl.SetTerminalCheck(f)
+	return sl
+}
+
+// terminalRenderer returns a renderer for w using the terminal check. It
+// isn't cached in the registry, as it depends on the check.
+func (l *Logger) terminalRenderer(w io.Writer) *lipgloss.Renderer {
+	opts := []termenv.OutputOption{termenv.WithColorCache(true)}
+	if !l.terminalCheck(w) {
+		return lipgloss.NewRenderer(w, append(opts, termenv.WithProfile(termenv.Ascii))...)
+	}
+	re := lipgloss.NewRenderer(w, append(opts, termenv.WithTTY(true))...)
+	if err := enableVirtualTerminal(w); err != nil {
+		re.SetColorProfile(termenv.Ascii)
+	}
+	return re
+}
+
+// colorForced reports whether the CLICOLOR_FORCE convention forces styles
+// on outputs that aren't terminals. NO_COLOR takes precedence, as it does in
+// the color profile detection, which also honors CLICOLOR=0. An explicit
+// color profile overrides both.
+func colorForced() bool {
+	force := os.Getenv("CLICOLOR_FORCE")
+	return force != "" && force != "0" && os.Getenv("NO_COLOR") == ""
+}
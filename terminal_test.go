@@ -0,0 +1,50 @@
+package plog
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/muesli/termenv"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTerminalCheck(t *testing.T) {
+	t.Setenv("TERM", "xterm-256color")
+	t.Setenv("NO_COLOR", "")
+	var buf bytes.Buffer
+	var checked io.Writer
+	l := NewWithOptions(&buf, Options{TerminalCheck: func(w io.Writer) bool {
+		checked = w
+		return true
+	}})
+	require.Equal(t, &buf, checked)
+	require.Equal(t, termenv.ANSI256, l.re.ColorProfile())
+
+	sl := l.WithTerminalCheck(func(io.Writer) bool { return false })
+	require.Equal(t, termenv.Ascii, sl.re.ColorProfile())
+	require.Equal(t, termenv.ANSI256, l.re.ColorProfile())
+
+	sl.Print("plain", "key", "value")
+	require.Equal(t, "plain key=value\n", buf.String())
+}
+
+func TestColorEnv(t *testing.T) {
+	t.Setenv("TERM", "xterm-256color")
+	t.Setenv("NO_COLOR", "")
+	t.Setenv("CLICOLOR", "")
+	t.Setenv("CLICOLOR_FORCE", "1")
+	require.Equal(t, termenv.ANSI256, New(&bytes.Buffer{}).re.ColorProfile())
+
+	t.Setenv("NO_COLOR", "1")
+	require.Equal(t, termenv.Ascii, New(&bytes.Buffer{}).re.ColorProfile())
+
+	t.Setenv("NO_COLOR", "")
+	t.Setenv("CLICOLOR_FORCE", "")
+	t.Setenv("CLICOLOR", "0")
+	l := NewWithOptions(&bytes.Buffer{}, Options{TerminalCheck: func(io.Writer) bool { return true }})
+	require.Equal(t, termenv.Ascii, l.re.ColorProfile())
+
+	// Explicit profiles win over the environment.
+	require.Equal(t, termenv.TrueColor, l.WithColorProfile(termenv.TrueColor).re.ColorProfile())
+}
@@ -1,13 +1,21 @@
 package plog
 
 import (
+	"bytes"
+	"encoding"
 	"fmt"
 	"io"
+	"reflect"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
 	"unicode"
 	"unicode/utf8"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/muesli/termenv"
 )
 
 const (
@@ -17,18 +25,19 @@ const (
 
 func (l *Logger) writeIndent(w io.Writer, str string, indent string, newline bool, key string) {
 	st := l.styles
+	lines := strings.Count(strings.TrimSuffix(str, "\n"), "\n") + 1
 
 	// kindly borrowed from hclog
-	for {
+	for line := 0; ; line++ {
 		nl := strings.IndexByte(str, '\n')
 		if nl == -1 {
 			if str != "" {
-				_, _ = w.Write([]byte(indent))
+				_, _ = w.Write([]byte(l.linePrefix(indent, line, lines)))
 				val := escapeStringForOutput(str, false)
 				if valueStyle, ok := st.Values[key]; ok {
-					val = valueStyle.Renderer(l.re).Render(val)
+					val = l.render(valueStyle, val)
 				} else {
-					val = st.Value.Renderer(l.re).Render(val)
+					val = l.render(st.Value, val)
 				}
 				_, _ = w.Write([]byte(val))
 				if newline {
@@ -38,15 +47,65 @@ func (l *Logger) writeIndent(w io.Writer, str string, indent string, newline boo
 			return
 		}
 
-		_, _ = w.Write([]byte(indent))
+		_, _ = w.Write([]byte(l.linePrefix(indent, line, lines)))
 		val := escapeStringForOutput(str[:nl], false)
-		val = st.Value.Renderer(l.re).Render(val)
+		val = l.render(st.Value, val)
 		_, _ = w.Write([]byte(val))
 		_, _ = w.Write([]byte{'\n'})
 		str = str[nl+1:]
 	}
 }
 
+// MultilineLayout is how the TextFormatter decorates the lines of multiline
+// values.
+type MultilineLayout uint8
+
+const (
+	// MultilineBar prefixes the lines with the indent separator, "  │ " by
+	// default. This is the default.
+	MultilineBar MultilineLayout = iota
+	// MultilinePlain prefixes the lines with spaces only, so embedded YAML
+	// or SQL can be copied as is.
+	MultilinePlain
+	// MultilineFrame draws a box-drawing frame on the left of the lines.
+	MultilineFrame
+	// MultilineNumbered prefixes the lines with their number.
+	MultilineNumbered
+)
+
+// SetMultilineLayout sets how the TextFormatter decorates the lines of
+// multiline values.
+func (l *Logger) SetMultilineLayout(layout MultilineLayout) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.multiline = layout
+}
+
+// linePrefix returns the prefix of the line of a multiline value of lines
+// lines, given the styled indent separator bar.
+func (l *Logger) linePrefix(bar string, line, lines int) string {
+	var prefix string
+	switch l.multiline {
+	case MultilinePlain:
+		return "    "
+	case MultilineNumbered:
+		width := len(strconv.Itoa(lines))
+		prefix = fmt.Sprintf("  %*d │ ", width, line+1)
+	case MultilineFrame:
+		switch line {
+		case 0:
+			prefix = "  ┌ "
+		case lines - 1:
+			prefix = "  └ "
+		default:
+			prefix = "  │ "
+		}
+	default:
+		return bar
+	}
+	return l.render(l.styles.Separator, prefix)
+}
+
 func needsEscaping(str string) bool {
 	for _, b := range str {
 		if !unicode.IsPrint(b) || b == '"' {
@@ -164,63 +223,347 @@ func writeSpace(w io.Writer, first bool) {
 	}
 }
 
-func (l *Logger) textFormatter(keyvals ...interface{}) {
+// SetSeparator sets the separator written between the keys and values by the
+// TextFormatter, e.g. ": ". An empty separator restores the default "=".
+func (l *Logger) SetSeparator(sep string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.separator = sep
+}
+
+// SetIndentSeparator sets the prefix of the lines of multiline values written
+// by the TextFormatter. An empty prefix restores the default "  │ ".
+func (l *Logger) SetIndentSeparator(prefix string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.indentSeparator = prefix
+}
+
+// SetLevelWidth sets the width the TextFormatter pads the level labels to, so
+// the messages start in the same column whatever the level styles. A width of
+// zero disables the padding.
+func (l *Logger) SetLevelWidth(width int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.levelWidth = width
+}
+
+// SetTypeColors sets whether the TextFormatter styles the values by type,
+// numbers, booleans, durations, errors and strings, with the Types styles.
+func (l *Logger) SetTypeColors(enabled bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.typeColors = enabled
+}
+
+// typeStyle returns the style of the type of v, if type colors are enabled.
+func (l *Logger) typeStyle(v interface{}) (lipgloss.Style, bool) {
+	if !l.typeColors {
+		return lipgloss.Style{}, false
+	}
+	if f, ok := v.(Field); ok {
+		v = f.Value()
+	}
+	ts := l.styles.Types
+	switch v.(type) {
+	case nil:
+		return ts.Nil, true
+	case time.Duration:
+		return ts.Duration, true
+	case error:
+		return ts.Error, true
+	case bool:
+		return ts.Bool, true
+	case string:
+		return ts.String, true
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, uintptr, float32, float64:
+		return ts.Number, true
+	}
+	return lipgloss.Style{}, false
+}
+
+// SetKeyColors sets whether the TextFormatter colors each key with a color of
+// the KeyPalette picked from its hash, so a key keeps the same color across
+// lines and sessions.
+func (l *Logger) SetKeyColors(enabled bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.keyColors = enabled
+}
+
+// keyColor returns the palette color of key, if key colors are enabled.
+func (l *Logger) keyColor(key string) (lipgloss.TerminalColor, bool) {
+	palette := l.styles.KeyPalette
+	if !l.keyColors || len(palette) == 0 {
+		return nil, false
+	}
+	// FNV-1a
+	h := uint32(2166136261)
+	for i := 0; i < len(key); i++ {
+		h ^= uint32(key[i])
+		h *= 16777619
+	}
+	return palette[h%uint32(len(palette))], true
+}
+
+// SetRawValues sets whether the TextFormatter ignores the String and
+// MarshalText methods of values, rendering them with %#v instead. It helps
+// debugging the raw content of structs.
+func (l *Logger) SetRawValues(raw bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.rawValues = raw
+}
+
+// textValue returns the text representation of v. Values implementing
+// fmt.Stringer or encoding.TextMarshaler use those representations, unless
+// raw values are enabled.
+func (l *Logger) textValue(v interface{}) string {
+	if f, ok := v.(Field); ok {
+		return f.String()
+	}
+	if _, ok := v.(error); ok {
+		return fmt.Sprintf("%+v", v)
+	}
+	if l.rawValues {
+		switch v.(type) {
+		case fmt.Stringer, encoding.TextMarshaler:
+			// %#v is the only verb ignoring String.
+			return fmt.Sprintf("%#v", v)
+		}
+		return fmt.Sprintf("%+v", v)
+	}
+	switch m := v.(type) {
+	case fmt.Stringer:
+		// Handled by fmt, which recovers String panics.
+	case encoding.TextMarshaler:
+		if rv := reflect.ValueOf(v); rv.Kind() == reflect.Pointer && rv.IsNil() {
+			return "<nil>"
+		}
+		if b, err := m.MarshalText(); err == nil {
+			return string(b)
+		}
+	}
+	return fmt.Sprintf("%+v", v)
+}
+
+// render renders s with the style st. Without colors, the styles that only
+// set colors and attributes leave single line strings as is, so lipgloss is
+// skipped to save its allocations.
+func (l *Logger) render(st lipgloss.Style, s string) string {
+	if l.re.ColorProfile() == termenv.Ascii && plainStyle(st) && !strings.ContainsAny(s, "\t\n") {
+		return s
+	}
+	return st.Renderer(l.re).Render(s)
+}
+
+// plainStyle returns whether st doesn't change the layout of the strings it
+// renders.
+func plainStyle(st lipgloss.Style) bool {
+	return st.Value() == "" && st.GetTransform() == nil && !st.GetInline() &&
+		st.GetWidth() == 0 && st.GetHeight() == 0 &&
+		st.GetMaxWidth() == 0 && st.GetMaxHeight() == 0 &&
+		st.GetHorizontalPadding() == 0 && st.GetVerticalPadding() == 0 &&
+		st.GetHorizontalMargins() == 0 && st.GetVerticalMargins() == 0 &&
+		st.GetHorizontalBorderSize() == 0 && st.GetVerticalBorderSize() == 0
+}
+
+// plainLabel returns the label of the level style rendered without colors,
+// when the style only pads it to its width, skipping lipgloss.
+func (l *Logger) plainLabel(style lipgloss.Style) (string, bool) {
+	label := style.Value()
+	w, width := ansi.StringWidth(label), style.GetWidth()
+	if l.re.ColorProfile() != termenv.Ascii || strings.ContainsAny(label, "\t\n") || w > width ||
+		!plainStyle(style.UnsetString().UnsetWidth().UnsetMaxWidth()) {
+		return "", false
+	}
+	if maxWidth := style.GetMaxWidth(); maxWidth > 0 && width > maxWidth {
+		return "", false
+	}
+	pad := width - w
+	switch style.GetAlignHorizontal() {
+	case lipgloss.Right:
+		return padLabel(label, pad, 0), true
+	case lipgloss.Center:
+		// The remainder goes on the right, as with lipgloss.
+		return padLabel(label, pad/2, pad-pad/2), true
+	default:
+		return padLabel(label, 0, pad), true
+	}
+}
+
+type paddedLabel struct {
+	label       string
+	left, right int
+}
+
+// paddedLabels caches the padded level labels, which are few, so that they
+// aren't padded for every record.
+var (
+	paddedLabelsMu sync.RWMutex
+	paddedLabels   = map[paddedLabel]string{}
+)
+
+const maxPaddedLabels = 64
+
+// padLabel returns label with left spaces before it and right spaces after.
+func padLabel(label string, left, right int) string {
+	if left == 0 && right == 0 {
+		return label
+	}
+	k := paddedLabel{label, left, right}
+	paddedLabelsMu.RLock()
+	s, ok := paddedLabels[k]
+	paddedLabelsMu.RUnlock()
+	if ok {
+		return s
+	}
+	s = strings.Repeat(" ", left) + label + strings.Repeat(" ", right)
+	paddedLabelsMu.Lock()
+	if len(paddedLabels) < maxPaddedLabels {
+		paddedLabels[k] = s
+	}
+	paddedLabelsMu.Unlock()
+	return s
+}
+
+// levelLabel returns the rendered label of the level, or its override, with its icon
+// according to the icon mode.
+func (l *Logger) levelLabel(level Level, style lipgloss.Style) string {
+	st := l.styles
+	if label, ok := st.LevelLabels[level]; ok {
+		style = style.SetString(label)
+	}
+	lvl, ok := l.plainLabel(style)
+	if !ok {
+		lvl = style.Renderer(l.re).String()
+	}
+	icon, ok := st.LevelIcons[level]
+	if !ok || icon == "" || st.IconMode == IconsOff {
+		return lvl
+	}
+	icon = lipgloss.NewStyle().
+		Foreground(style.GetForeground()).
+		Renderer(l.re).
+		Render(icon)
+	if st.IconMode == IconsOnly || lvl == "" {
+		return icon
+	}
+	return icon + " " + lvl
+}
+
+func (l *Logger) textFormatter(b *bytes.Buffer, keyvals ...interface{}) {
 	st := l.styles
 	lenKeyvals := len(keyvals)
+	if l.align != nil {
+		l.align.mu.Lock()
+		defer l.align.mu.Unlock()
+		defer l.align.done()
+	}
 
+	col := 0
+	recordLevel := noLevel
 	for i := 0; i < lenKeyvals; i += 2 {
 		firstKey := i == 0
 		moreKeys := i < lenKeyvals-2
+		start := b.Len()
 
 		switch keyvals[i] {
 		case TimestampKey:
 			if t, ok := keyvals[i+1].(time.Time); ok {
-				ts := t.Format(l.timeFormat)
-				ts = st.Timestamp.Renderer(l.re).Render(ts)
-				writeSpace(&l.b, firstKey)
-				l.b.WriteString(ts)
+				ts := l.formatTime(TextFormatter, t)
+				ts = l.render(st.Timestamp, ts)
+				writeSpace(b, firstKey)
+				b.WriteString(ts)
 			}
 		case LevelKey:
 			if level, ok := keyvals[i+1].(Level); ok {
+				recordLevel = level
 				var lvl string
 				lvlStyle, ok := st.Levels[level]
 				if !ok {
 					continue
 				}
 
-				lvl = lvlStyle.Renderer(l.re).String()
+				lvl = l.levelLabel(level, lvlStyle)
+				if w := ansi.StringWidth(lvl); lvl != "" && w < l.levelWidth {
+					lvl += strings.Repeat(" ", l.levelWidth-w)
+				}
 				if lvl != "" {
-					writeSpace(&l.b, firstKey)
-					l.b.WriteString(lvl)
+					writeSpace(b, firstKey)
+					b.WriteString(lvl)
 				}
 			}
 		case CallerKey:
-			if caller, ok := keyvals[i+1].(string); ok {
-				caller = fmt.Sprintf("<%s>", caller)
-				caller = st.Caller.Renderer(l.re).Render(caller)
-				writeSpace(&l.b, firstKey)
-				l.b.WriteString(caller)
+			caller, ok := keyvals[i+1].(string)
+			link, linked := keyvals[i+1].(callerLink)
+			if linked {
+				caller, ok = link.caller, true
+			}
+			if ok {
+				caller = fmt.Sprintf("<%s>", l.sanitize(caller))
+				caller = l.render(st.Caller, caller)
+				if linked && l.hyperlinks && l.re.ColorProfile() != termenv.Ascii {
+					caller = ansi.SetHyperlink(link.url) + caller + ansi.ResetHyperlink()
+				}
+				writeSpace(b, firstKey)
+				b.WriteString(caller)
 			}
 		case PrefixKey:
 			if prefix, ok := keyvals[i+1].(string); ok {
-				prefix = st.Prefix.Renderer(l.re).Render(prefix + ":")
-				writeSpace(&l.b, firstKey)
-				l.b.WriteString(prefix)
+				prefix = l.render(st.Prefix, l.sanitize(prefix)+":")
+				writeSpace(b, firstKey)
+				b.WriteString(prefix)
 			}
 		case MessageKey:
 			if msg := keyvals[i+1]; msg != nil {
-				m := fmt.Sprint(msg)
-				m = st.Message.Renderer(l.re).Render(m)
-				writeSpace(&l.b, firstKey)
-				l.b.WriteString(m)
+				m := l.sanitizeMessage(fmt.Sprint(msg))
+				m = l.render(st.Message, m)
+				writeSpace(b, firstKey)
+				b.WriteString(m)
 			}
 		default:
 			sep := separator
+			if l.separator != "" {
+				sep = l.separator
+			}
 			indentSep := indentSeparator
-			sep = st.Separator.Renderer(l.re).Render(sep)
-			indentSep = st.Separator.Renderer(l.re).Render(indentSep)
-			key := fmt.Sprint(keyvals[i])
-			val := fmt.Sprintf("%+v", keyvals[i+1])
+			if l.indentSeparator != "" {
+				indentSep = l.indentSeparator
+			}
+			sep = l.render(st.Separator, sep)
+			indentSep = l.render(st.Separator, indentSep)
+			key := l.sanitize(fmt.Sprint(keyvals[i]))
+			val := l.textValue(keyvals[i+1])
+			if s, ok := l.formatNumber(key, keyvals[i+1]); ok {
+				val = s
+			}
+			if s, ok := l.formatDuration(keyvals[i+1]); ok {
+				val = s
+			}
+			if s, ok := l.formatBinary(keyvals[i+1]); ok {
+				val = s
+			}
+			if s, ok := l.prettyValue(keyvals[i+1]); ok {
+				val = s
+			}
+			if err, ok := keyvals[i+1].(error); ok {
+				if errs := joinedErrors(err); len(errs) > 0 {
+					val = l.textJoinedError(errs)
+				} else if s, ok := textError(err); ok && l.errorChains {
+					val = s
+				} else if _, ok := errorStack(err); ok {
+					// The stack is rendered under KeyStack, not by %+v.
+					val = err.Error()
+				}
+			}
+			val = l.sanitizeValue(val)
+			// The unit goes after the value, outside of its quotes, so
+			// that it doesn't get the value quoted.
+			var unit string
+			if meta, ok := l.meta[key]; ok && val != "" && meta.Unit != "" {
+				unit = " " + l.sanitizeValue(meta.Unit)
+			}
 			raw := val == ""
 			if raw {
 				val = `""`
@@ -230,13 +573,23 @@ func (l *Logger) textFormatter(keyvals ...interface{}) {
 			}
 			actualKey := key
 			valueStyle := st.Value
+			if key == ErrorKey {
+				valueStyle = st.Error
+			} else if ts, ok := l.typeStyle(keyvals[i+1]); ok {
+				valueStyle = ts
+			}
 			if vs, ok := st.Values[actualKey]; ok {
 				valueStyle = vs
 			}
+			if l.styleFunc != nil {
+				valueStyle = l.styleFunc(actualKey, keyvals[i+1], recordLevel).Inherit(valueStyle)
+			}
 			if keyStyle, ok := st.Keys[key]; ok {
-				key = keyStyle.Renderer(l.re).Render(key)
+				key = l.render(keyStyle, key)
+			} else if c, ok := l.keyColor(key); ok {
+				key = l.render(st.Key.Foreground(c), key)
 			} else {
-				key = st.Key.Renderer(l.re).Render(key)
+				key = l.render(st.Key, key)
 			}
 
 			// Values may contain multiple lines, and that format
@@ -247,26 +600,31 @@ func (l *Logger) textFormatter(keyvals ...interface{}) {
 			// in the value string are "normal", like if they
 			// contain ANSI escape sequences.
 			if strings.Contains(val, "\n") {
-				l.b.WriteString("\n  ")
-				l.b.WriteString(key)
-				l.b.WriteString(sep + "\n")
-				l.writeIndent(&l.b, val, indentSep, moreKeys, actualKey)
+				b.WriteString("\n  ")
+				b.WriteString(key)
+				b.WriteString(sep + "\n")
+				l.writeIndent(b, val+unit, indentSep, moreKeys, actualKey)
 			} else if !raw && needsQuoting(val) {
-				writeSpace(&l.b, firstKey)
-				l.b.WriteString(key)
-				l.b.WriteString(sep)
-				l.b.WriteString(valueStyle.Renderer(l.re).Render(fmt.Sprintf(`"%s"`,
-					escapeStringForOutput(val, true))))
+				writeSpace(b, firstKey)
+				b.WriteString(key)
+				b.WriteString(sep)
+				b.WriteString(l.render(valueStyle, fmt.Sprintf(`"%s"`,
+					escapeStringForOutput(val, true))+unit))
 			} else {
-				val = valueStyle.Renderer(l.re).Render(val)
-				writeSpace(&l.b, firstKey)
-				l.b.WriteString(key)
-				l.b.WriteString(sep)
-				l.b.WriteString(val)
+				val = l.render(valueStyle, val+unit)
+				writeSpace(b, firstKey)
+				b.WriteString(key)
+				b.WriteString(sep)
+				b.WriteString(val)
 			}
 		}
+
+		if l.align != nil && b.Len() > start {
+			l.alignColumn(b, col, start, !moreKeys)
+			col++
+		}
 	}
 
 	// Add a newline to the end of the log message.
-	l.b.WriteByte('\n')
+	b.WriteByte('\n')
 }
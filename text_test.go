@@ -9,6 +9,7 @@ import (
 	"os/exec"
 	"path/filepath"
 	"runtime"
+	"strings"
 	"testing"
 	"time"
 
@@ -434,3 +435,195 @@ func TestCustomLevelStyle(t *testing.T) {
 	l.Log(lvl, "foobar")
 	assert.Equal(t, "FUNKY foobar\n", buf.String())
 }
+
+func TestTextErrorStyle(t *testing.T) {
+	var buf bytes.Buffer
+	logger := New(&buf)
+	logger.SetColorProfile(termenv.ANSI256)
+	st := DefaultStyles()
+	logger.SetStyles(st)
+
+	errStyle := st.Error.Renderer(logger.re)
+	logger.WithError(errors.New("boom")).Print("failed", "cause", errors.New("fire"), "other", "x")
+	require.Equal(t, fmt.Sprintf("failed %s%s%s %s%sfire %s%sx\n",
+		st.Key.Renderer(logger.re).Render(ErrorKey), st.Separator.Renderer(logger.re).Render(separator), errStyle.Render("boom"),
+		st.Key.Renderer(logger.re).Render("cause"), st.Separator.Renderer(logger.re).Render(separator),
+		st.Key.Renderer(logger.re).Render("other"), st.Separator.Renderer(logger.re).Render(separator),
+	), buf.String())
+	require.Contains(t, buf.String(), "\x1b[")
+}
+
+func TestTextSeparator(t *testing.T) {
+	var buf bytes.Buffer
+	l := NewWithOptions(&buf, Options{Separator: ": ", IndentSeparator: "  > "})
+	l.Print("hi", "a", 1, "b", "x\ny")
+	require.Equal(t, "hi a: 1\n  b: \n  > x\n  > y\n", buf.String())
+
+	buf.Reset()
+	l.SetSeparator("")
+	l.Print("hi", "a", 1)
+	require.Equal(t, "hi a=1\n", buf.String())
+}
+
+func TestTextAlignColumns(t *testing.T) {
+	var buf bytes.Buffer
+	l := NewWithOptions(&buf, Options{AlignColumns: true})
+	l.Print("started", "user", "bob", "n", 1)
+	l.Print("hi", "user", "alexandra", "n", 22)
+	l.Print("stopped", "user", "al", "n", 3)
+	require.Equal(t, ""+
+		"started user=bob n=1\n"+
+		"hi      user=alexandra n=22\n"+
+		"stopped user=al        n=3\n", buf.String())
+}
+
+func TestTextLevelWidth(t *testing.T) {
+	var buf bytes.Buffer
+	l := NewWithOptions(&buf, Options{Level: DebugLevel, LevelWidth: 6})
+	st := DefaultStyles()
+	st.Levels[InfoLevel] = lipgloss.NewStyle().SetString("INFO")
+	st.Levels[ErrorLevel] = lipgloss.NewStyle().SetString("ERROR")
+	l.SetStyles(st)
+	l.Info("a")
+	l.Error("b")
+	require.Equal(t, "INFO   a\nERROR  b\n", buf.String())
+}
+
+func TestTextLevelIcons(t *testing.T) {
+	var buf bytes.Buffer
+	l := New(&buf)
+	st := DefaultStyles()
+	st.IconMode = IconsOnly
+	l.SetStyles(st)
+	l.Warn("a")
+	st.IconMode = IconsWithLabels
+	st.Levels[InfoLevel] = lipgloss.NewStyle().SetString("INFO")
+	l.Info("b")
+	delete(st.LevelIcons, InfoLevel)
+	l.Info("c")
+	require.Equal(t, "⚠ a\n✓ INFO b\nINFO c\n", buf.String())
+}
+
+func TestTextLevelLabels(t *testing.T) {
+	var buf bytes.Buffer
+	l := New(&buf)
+	st := DefaultStyles()
+	st.LevelLabels = map[Level]string{WarnLevel: "wrn"}
+	l.SetStyles(st)
+	l.Warn("a")
+	require.Equal(t, "  wrn a\n", buf.String())
+}
+
+func TestAdaptiveColors(t *testing.T) {
+	var buf bytes.Buffer
+	l := New(&buf)
+	l.SetColorProfile(termenv.ANSI256)
+	l.SetHasDarkBackground(true)
+	require.True(t, l.HasDarkBackground())
+	l.Print("", ErrorKey, "x")
+	dark := buf.String()
+
+	buf.Reset()
+	l.SetHasDarkBackground(false)
+	require.False(t, l.HasDarkBackground())
+	l.Print("", ErrorKey, "x")
+	require.Contains(t, dark, "204")
+	require.Contains(t, buf.String(), "161")
+}
+
+func TestWithColorProfile(t *testing.T) {
+	var buf bytes.Buffer
+	l := New(&buf)
+	sl := l.WithColorProfile(termenv.ANSI256)
+	require.Equal(t, termenv.ANSI256, sl.re.ColorProfile())
+	require.Equal(t, termenv.Ascii, l.re.ColorProfile())
+
+	sl.SetOutput(&buf)
+	require.Equal(t, termenv.ANSI256, sl.re.ColorProfile())
+	sl.Print("", ErrorKey, "x")
+	require.Contains(t, buf.String(), "\x1b[")
+}
+
+func TestTextStyleFunc(t *testing.T) {
+	var buf bytes.Buffer
+	red := lipgloss.NewStyle().Foreground(lipgloss.Color("9"))
+	l := New(&buf).WithStyleFunc(func(key string, value interface{}, level Level) lipgloss.Style {
+		if code, ok := value.(int); ok && key == "status" && code >= 500 && level == ErrorLevel {
+			return red
+		}
+		return lipgloss.NewStyle()
+	})
+	l.SetColorProfile(termenv.ANSI)
+	l.Error("", "status", 503, "other", 503)
+	require.Contains(t, buf.String(), "\x1b[91m503\x1b[0m")
+	require.Equal(t, 1, strings.Count(buf.String(), "\x1b[91m"))
+
+	buf.Reset()
+	l.Print("", "status", 503)
+	require.NotContains(t, buf.String(), "\x1b[91m")
+}
+
+func TestTextTypeColors(t *testing.T) {
+	var buf bytes.Buffer
+	l := NewWithOptions(&buf, Options{TypeColors: true})
+	l.SetColorProfile(termenv.ANSI256)
+	l.SetHasDarkBackground(true)
+	l.Print("", "n", 1, Float64("f", 1.5), "b", true, "d", time.Second, "s", "x", "z", nil)
+	out := buf.String()
+	for _, want := range []string{
+		"\x1b[38;5;75m1\x1b[0m",
+		"\x1b[38;5;75m1.5\x1b[0m",
+		"\x1b[38;5;177mtrue\x1b[0m",
+		"\x1b[38;5;192m1s\x1b[0m",
+		"\x1b[38;5;114mx\x1b[0m",
+	} {
+		require.Contains(t, out, want)
+	}
+
+	buf.Reset()
+	l.SetTypeColors(false)
+	l.Print("", "n", 1)
+	require.NotContains(t, buf.String(), "38;5;75")
+}
+
+func TestTextKeyColors(t *testing.T) {
+	var buf bytes.Buffer
+	l := NewWithOptions(&buf, Options{KeyColors: true})
+	l.SetColorProfile(termenv.ANSI256)
+	l.SetHasDarkBackground(true)
+	l.Print("", "alpha", 1, "alpha", 2)
+	c, ok := l.keyColor("alpha")
+	require.True(t, ok)
+	want := "\x1b[2;38;5;" + c.(lipgloss.AdaptiveColor).Dark + "malpha"
+	require.Equal(t, 2, strings.Count(buf.String(), want))
+
+	colors := map[lipgloss.TerminalColor]bool{}
+	for _, key := range []string{"a", "b", "c", "d", "e", "f", "g", "h"} {
+		c, _ := l.keyColor(key)
+		colors[c] = true
+	}
+	require.Greater(t, len(colors), 1)
+}
+
+func TestTextMultilineLayout(t *testing.T) {
+	cases := []struct {
+		layout MultilineLayout
+		want   string
+	}{
+		{MultilineBar, "hi\n  sql=\n  │ SELECT *\n  │ FROM t\n"},
+		{MultilinePlain, "hi\n  sql=\n    SELECT *\n    FROM t\n"},
+		{MultilineFrame, "hi\n  sql=\n  ┌ SELECT *\n  └ FROM t\n"},
+		{MultilineNumbered, "hi\n  sql=\n  1 │ SELECT *\n  2 │ FROM t\n"},
+	}
+	for _, c := range cases {
+		var buf bytes.Buffer
+		l := NewWithOptions(&buf, Options{MultilineLayout: c.layout})
+		l.Print("hi", "sql", "SELECT *\nFROM t")
+		require.Equal(t, c.want, buf.String())
+	}
+
+	var buf bytes.Buffer
+	l := NewWithOptions(&buf, Options{MultilineLayout: MultilineFrame})
+	l.Print("hi", "v", "a\nb\nc\n")
+	require.Equal(t, "hi\n  v=\n  ┌ a\n  │ b\n  └ c\n\n", buf.String())
+}
@@ -0,0 +1,35 @@
+package plog
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type textID struct{ n int }
+
+func (id textID) MarshalText() ([]byte, error) {
+	return []byte("id-" + string(rune('0'+id.n))), nil
+}
+
+type ptrText struct{ s string }
+
+func (p *ptrText) MarshalText() ([]byte, error) { return []byte(p.s), nil }
+
+type stringID struct{ n int }
+
+func (id *stringID) String() string { return "sid" }
+
+func TestTextValue(t *testing.T) {
+	var buf bytes.Buffer
+	l := New(&buf)
+	var nilID *ptrText
+	l.Print("ids", "text", textID{7}, "stringer", &stringID{1}, "nil", nilID)
+	require.Equal(t, "ids text=id-7 stringer=sid nil=<nil>\n", buf.String())
+
+	buf.Reset()
+	l.SetRawValues(true)
+	l.Print("ids", "text", textID{7}, "plain", struct{ N int }{1})
+	require.Equal(t, "ids text=plog.textID{n:7} plain={N:1}\n", buf.String())
+}
@@ -0,0 +1,101 @@
+package plog
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Time format presets, selectable by name with ParseTimeFormat.
+const (
+	// TimeFormatMillis is a time of day with millisecond precision.
+	TimeFormatMillis = "15:04:05.000"
+	// TimeFormatMicros is a time of day with microsecond precision.
+	TimeFormatMicros = "15:04:05.000000"
+	// TimeFormatDateTimeMillis is the default time format with millisecond
+	// precision.
+	TimeFormatDateTimeMillis = "2006/01/02 15:04:05.000"
+)
+
+// timeFormatPresets are the time format presets by name.
+var timeFormatPresets = map[string]string{
+	"default":     DefaultTimeFormat,
+	"millis":      TimeFormatMillis,
+	"micros":      TimeFormatMicros,
+	"datemillis":  TimeFormatDateTimeMillis,
+	"time":        time.TimeOnly,
+	"datetime":    time.DateTime,
+	"kitchen":     time.Kitchen,
+	"rfc3339":     time.RFC3339,
+	"rfc3339nano": time.RFC3339Nano,
+	"stamp":       time.StampMilli,
+}
+
+// ErrInvalidTimeFormat is an error returned when parsing an unknown time
+// format preset name.
+var ErrInvalidTimeFormat = errors.New("invalid time format")
+
+// ParseTimeFormat returns the layout of the time format preset name: default,
+// millis, micros, datemillis, time, datetime, kitchen, rfc3339, rfc3339nano or
+// stamp. The names are case insensitive.
+func ParseTimeFormat(name string) (string, error) {
+	if f, ok := timeFormatPresets[strings.ToLower(name)]; ok {
+		return f, nil
+	}
+	return "", ErrInvalidTimeFormat
+}
+
+// SetRelativeTime sets whether the timestamps are rendered as the offset since
+// the creation of the root logger, like "+00:03.412", instead of in the time
+// format. It suits CLI tools and benchmarks better than wall-clock times.
+func (l *Logger) SetRelativeTime(relative bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.relativeTime = relative
+}
+
+// SetFormatterTimeFormat sets the time format of the formatter f, overriding
+// the logger time format, so a logger can write human-friendly times as text
+// and machine-friendly ones as JSON, including to its machine output. An empty
+// format removes the override.
+func (l *Logger) SetFormatterTimeFormat(f Formatter, format string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	m := make(map[Formatter]string, len(l.timeFormats)+1)
+	for k, v := range l.timeFormats {
+		m[k] = v
+	}
+	if format == "" {
+		delete(m, f)
+	} else {
+		m[f] = format
+	}
+	l.timeFormats = m
+}
+
+// formatTime returns the timestamp t in the time format of the formatter f,
+// or as a relative offset.
+func (l *Logger) formatTime(f Formatter, t time.Time) string {
+	if l.relativeTime {
+		return formatOffset(t.Sub(l.created))
+	}
+	if format, ok := l.timeFormats[f]; ok {
+		return t.Format(format)
+	}
+	return t.Format(l.timeFormat)
+}
+
+// formatOffset returns d as "+MM:SS.mmm", or "+H:MM:SS.mmm" past an hour.
+func formatOffset(d time.Duration) string {
+	sign := "+"
+	if d < 0 {
+		sign, d = "-", -d
+	}
+	ms := d.Milliseconds()
+	h, m, s, ms := ms/3_600_000, ms/60_000%60, ms/1000%60, ms%1000
+	if h > 0 {
+		return fmt.Sprintf("%s%d:%02d:%02d.%03d", sign, h, m, s, ms)
+	}
+	return fmt.Sprintf("%s%02d:%02d.%03d", sign, m, s, ms)
+}
@@ -0,0 +1,69 @@
+package plog
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseTimeFormat(t *testing.T) {
+	f, err := ParseTimeFormat("Millis")
+	require.NoError(t, err)
+	require.Equal(t, TimeFormatMillis, f)
+	_, err = ParseTimeFormat("nope")
+	require.ErrorIs(t, err, ErrInvalidTimeFormat)
+
+	var buf bytes.Buffer
+	now := time.Date(2024, 1, 2, 15, 4, 5, 678_000_000, time.UTC)
+	l := NewWithOptions(&buf, Options{
+		ReportTimestamp: true,
+		TimeFormat:      f,
+		TimeFunction:    func(time.Time) time.Time { return now },
+		Formatter:       LogfmtFormatter,
+	})
+	l.Print("hi")
+	require.Equal(t, "time=15:04:05.678 msg=hi\n", buf.String())
+
+	buf.Reset()
+	l.SetTimeFormat("")
+	l.Print("hi")
+	require.Equal(t, `time="2024/01/02 15:04:05" msg=hi`+"\n", buf.String())
+}
+
+func TestRelativeTime(t *testing.T) {
+	require.Equal(t, "+00:03.412", formatOffset(3412*time.Millisecond))
+	require.Equal(t, "+02:05.000", formatOffset(125*time.Second))
+	require.Equal(t, "+1:00:01.005", formatOffset(time.Hour+time.Second+5*time.Millisecond))
+	require.Equal(t, "-00:00.250", formatOffset(-250*time.Millisecond))
+
+	var buf bytes.Buffer
+	l := NewWithOptions(&buf, Options{ReportTimestamp: true, RelativeTime: true, Formatter: LogfmtFormatter})
+	l.SetTimeFunction(func(time.Time) time.Time { return l.created.Add(1500 * time.Millisecond) })
+	l.With("a", 1).Print("hi")
+	require.Equal(t, "time=+00:01.500 msg=hi a=1\n", buf.String())
+}
+
+func TestFormatterTimeFormat(t *testing.T) {
+	var buf, machine bytes.Buffer
+	now := time.Date(2024, 1, 2, 15, 4, 5, 0, time.UTC)
+	l := NewWithOptions(&buf, Options{
+		ReportTimestamp:      true,
+		TimeFormat:           time.Kitchen,
+		TimeFunction:         func(time.Time) time.Time { return now },
+		MachineOutput:        &machine,
+		FormatterTimeFormats: map[Formatter]string{JSONFormatter: time.RFC3339},
+	})
+	l.Print("hi")
+	require.Equal(t, "3:04PM hi\n", buf.String())
+	require.Equal(t, `{"time":"2024-01-02T15:04:05Z","msg":"hi"}`+"\n", machine.String())
+
+	buf.Reset()
+	l.SetFormatterTimeFormat(TextFormatter, time.TimeOnly)
+	l.SetFormatterTimeFormat(JSONFormatter, "")
+	machine.Reset()
+	l.Print("hi")
+	require.Equal(t, "15:04:05 hi\n", buf.String())
+	require.Equal(t, `{"time":"3:04PM","msg":"hi"}`+"\n", machine.String())
+}
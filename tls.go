@@ -0,0 +1,96 @@
+package plog
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"net/http"
+	"os"
+)
+
+// ErrNoCertificates is returned when a CA bundle holds no PEM certificates.
+var ErrNoCertificates = errors.New("no certificates found in CA bundle")
+
+// TLSOptions is a TLS configuration shared by the network sinks: pass Config
+// to NetWriterOptions.TLSConfig, or HTTPClient to the Client option of the
+// Sentry and webhook processors.
+//
+// PEM contents take precedence over files.
+type TLSOptions struct {
+	// CAFile is a PEM bundle of the CAs trusted to verify the server. The
+	// default is the system pool.
+	CAFile string
+	// CAPEM is a PEM bundle of the CAs trusted to verify the server.
+	CAPEM []byte
+	// CertFile and KeyFile are the PEM client certificate and key presented
+	// to the server for mutual TLS.
+	CertFile, KeyFile string
+	// CertPEM and KeyPEM are the PEM client certificate and key presented to
+	// the server for mutual TLS.
+	CertPEM, KeyPEM []byte
+	// ServerName is the name used to verify the server certificate and sent
+	// with SNI. The default is the host of the address dialed.
+	ServerName string
+	// MinVersion is the minimum TLS version, e.g. tls.VersionTLS13. The
+	// default is tls.VersionTLS12.
+	MinVersion uint16
+	// InsecureSkipVerify disables the verification of the server certificate.
+	// Only use it for testing.
+	InsecureSkipVerify bool
+}
+
+// Config returns the tls.Config described by o. It returns an error if the CA
+// bundle or the client certificate can't be loaded.
+func (o TLSOptions) Config() (*tls.Config, error) {
+	cfg := &tls.Config{
+		ServerName:         o.ServerName,
+		MinVersion:         o.MinVersion,
+		InsecureSkipVerify: o.InsecureSkipVerify, //nolint: gosec
+	}
+	if cfg.MinVersion == 0 {
+		cfg.MinVersion = tls.VersionTLS12
+	}
+
+	ca := o.CAPEM
+	if ca == nil && o.CAFile != "" {
+		b, err := os.ReadFile(o.CAFile)
+		if err != nil {
+			return nil, err
+		}
+		ca = b
+	}
+	if ca != nil {
+		cfg.RootCAs = x509.NewCertPool()
+		if !cfg.RootCAs.AppendCertsFromPEM(ca) {
+			return nil, ErrNoCertificates
+		}
+	}
+
+	switch {
+	case o.CertPEM != nil || o.KeyPEM != nil:
+		cert, err := tls.X509KeyPair(o.CertPEM, o.KeyPEM)
+		if err != nil {
+			return nil, err
+		}
+		cfg.Certificates = []tls.Certificate{cert}
+	case o.CertFile != "" || o.KeyFile != "":
+		cert, err := tls.LoadX509KeyPair(o.CertFile, o.KeyFile)
+		if err != nil {
+			return nil, err
+		}
+		cfg.Certificates = []tls.Certificate{cert}
+	}
+	return cfg, nil
+}
+
+// HTTPClient returns an HTTP client using the TLS configuration described by
+// o, for the HTTP sinks.
+func (o TLSOptions) HTTPClient() (*http.Client, error) {
+	cfg, err := o.Config()
+	if err != nil {
+		return nil, err
+	}
+	t := http.DefaultTransport.(*http.Transport).Clone()
+	t.TLSClientConfig = cfg
+	return &http.Client{Transport: t}, nil
+}
@@ -0,0 +1,86 @@
+package plog
+
+import (
+	"fmt"
+	"hash/fnv"
+	"math"
+)
+
+// TraceSamplerOptions are the options for a TraceSampler.
+type TraceSamplerOptions struct {
+	// Rate is the fraction of traces whose debug records are kept, between 0
+	// and 1.
+	Rate float64
+	// Keys are the keys holding the trace or request ID, in order of
+	// preference. The default is KeyTraceID then KeyRequestID.
+	Keys []string
+}
+
+// TraceSampler is a Processor sampling the records below InfoLevel by trace
+// ID. The decision is a hash of the ID, so all the debug records of a trace
+// are either kept or dropped together, across loggers and processes, instead
+// of leaving half-visible requests like per-record sampling does. Records
+// without a trace ID are kept.
+type TraceSampler struct {
+	keys      []string
+	threshold uint64
+}
+
+// NewTraceSampler returns a new TraceSampler.
+func NewTraceSampler(o TraceSamplerOptions) *TraceSampler {
+	if len(o.Keys) == 0 {
+		o.Keys = []string{KeyTraceID, KeyRequestID}
+	}
+	s := &TraceSampler{keys: o.Keys}
+	switch {
+	case o.Rate >= 1:
+		s.threshold = math.MaxUint64
+	case o.Rate > 0:
+		s.threshold = uint64(o.Rate * math.MaxUint64)
+	}
+	return s
+}
+
+// Process drops the records below InfoLevel of the traces sampled out.
+func (s *TraceSampler) Process(e *Entry) bool {
+	if e.Level >= InfoLevel {
+		return true
+	}
+	id, ok := s.traceID(e.Keyvals)
+	if !ok {
+		return true
+	}
+	return s.Sampled(id)
+}
+
+// Sampled returns whether the records of the trace with the given ID are
+// kept.
+func (s *TraceSampler) Sampled(id string) bool {
+	if s.threshold == math.MaxUint64 {
+		return true
+	}
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(id))
+	// FNV-1a alone spreads similar IDs poorly over the high bits, mix it
+	// with the splitmix64 finalizer.
+	x := h.Sum64()
+	x ^= x >> 30
+	x *= 0xbf58476d1ce4e5b9
+	x ^= x >> 27
+	x *= 0x94d049bb133111eb
+	x ^= x >> 31
+	return x < s.threshold
+}
+
+// traceID returns the value of the first key of the sampler present in
+// keyvals. Later keyvals override earlier ones, as with sub-logger fields.
+func (s *TraceSampler) traceID(keyvals []interface{}) (string, bool) {
+	for _, key := range s.keys {
+		for i := len(keyvals) - 2; i >= 0; i -= 2 {
+			if k, ok := keyvals[i].(string); ok && k == key {
+				return fmt.Sprint(keyvals[i+1]), true
+			}
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,44 @@
+package plog
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTraceSampler(t *testing.T) {
+	if !debugEnabled {
+		t.Skip("debug records are compiled out")
+	}
+	s := NewTraceSampler(TraceSamplerOptions{Rate: 0.5})
+	var kept int
+	for i := 0; i < 1000; i++ {
+		id := fmt.Sprintf("trace-%d", i)
+		if s.Sampled(id) {
+			kept++
+		}
+		require.Equal(t, s.Sampled(id), s.Sampled(id))
+	}
+	require.InDelta(t, 500, kept, 60)
+
+	require.True(t, NewTraceSampler(TraceSamplerOptions{Rate: 1}).Sampled("x"))
+	require.False(t, NewTraceSampler(TraceSamplerOptions{}).Sampled("x"))
+
+	var buf bytes.Buffer
+	l := NewWithOptions(&buf, Options{
+		Formatter:  LogfmtFormatter,
+		Level:      DebugLevel,
+		Processors: []Processor{NewTraceSampler(TraceSamplerOptions{Rate: 0})},
+	})
+	req := l.WithTraceID("abc", "")
+	req.Debug("step")
+	req.Info("done")
+	l.Debug("no trace")
+	l.Debug("request", KeyRequestID, "r1")
+	require.Equal(t, "level=info msg=done trace_id=abc\n"+
+		"level=debug msg=\"no trace\"\n", buf.String())
+	require.False(t, strings.Contains(buf.String(), "step"))
+}
@@ -0,0 +1,194 @@
+package plog
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/charmbracelet/x/ansi"
+)
+
+// TranscriptFormat is the format of a session transcript.
+type TranscriptFormat uint8
+
+const (
+	// TranscriptPlain writes the output without styles, each line prefixed
+	// with the time it was written.
+	TranscriptPlain TranscriptFormat = iota
+	// TranscriptAsciicast writes an asciicast v2 recording, with styles, that
+	// can be replayed with asciinema.
+	TranscriptAsciicast
+)
+
+// transcriptTimeFormat is the line time format of TranscriptPlain.
+const transcriptTimeFormat = "2006-01-02T15:04:05.000Z07:00"
+
+// TranscriptOptions are the options for a TranscriptWriter.
+type TranscriptOptions struct {
+	// Format is the transcript format. The default is TranscriptPlain.
+	Format TranscriptFormat
+	// Width and Height are the terminal size recorded in asciicast headers.
+	// The defaults are 80 and 24.
+	Width, Height int
+	// Title is the title recorded in asciicast headers.
+	Title string
+}
+
+// TranscriptWriter is an io.Writer mirroring everything written to a
+// terminal into a session transcript, so users can attach full CLI sessions
+// to support requests. Use it as the output of loggers, and of any other
+// terminal output of the program:
+//
+//	tw, _ := log.OpenTranscript(os.Stderr, os.TempDir(), log.TranscriptOptions{})
+//	defer tw.Close()
+//	logger := log.New(tw)
+//
+// Terminal detection sees through it, so styles are kept on the terminal.
+type TranscriptWriter struct {
+	w    io.Writer
+	t    io.Writer
+	opts TranscriptOptions
+
+	mu      sync.Mutex
+	now     func() time.Time
+	start   time.Time
+	started bool
+	midLine bool
+	err     error
+	buf     bytes.Buffer
+}
+
+// NewTranscriptWriter returns a new TranscriptWriter writing to w and
+// mirroring to transcript.
+func NewTranscriptWriter(w, transcript io.Writer, o TranscriptOptions) *TranscriptWriter {
+	if o.Width <= 0 {
+		o.Width = 80
+	}
+	if o.Height <= 0 {
+		o.Height = 24
+	}
+	return &TranscriptWriter{w: w, t: transcript, opts: o, now: time.Now}
+}
+
+// OpenTranscript returns a new TranscriptWriter writing to w and mirroring to
+// a new timestamped transcript file in dir, e.g.
+// "transcript-20240102-150405.log", or ".cast" for TranscriptAsciicast.
+// Close closes the file.
+func OpenTranscript(w io.Writer, dir string, o TranscriptOptions) (*TranscriptWriter, error) {
+	ext := ".log"
+	if o.Format == TranscriptAsciicast {
+		ext = ".cast"
+	}
+	name := filepath.Join(dir, "transcript-"+time.Now().Format("20060102-150405")+ext)
+	f, err := os.OpenFile(name, os.O_CREATE|os.O_WRONLY|os.O_EXCL, 0o600) //nolint: gosec
+	if err != nil {
+		return nil, err
+	}
+	return NewTranscriptWriter(w, f, o), nil
+}
+
+// Write writes p to the terminal and the transcript. Transcript errors don't
+// fail the write; the first one is returned by Close.
+func (tw *TranscriptWriter) Write(p []byte) (int, error) {
+	n, err := tw.w.Write(p)
+
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+	if tw.err == nil {
+		tw.err = tw.record(p[:n])
+	}
+	return n, err
+}
+
+func (tw *TranscriptWriter) record(p []byte) error {
+	now := tw.now()
+	if !tw.started {
+		tw.started = true
+		tw.start = now
+		if tw.opts.Format == TranscriptAsciicast {
+			header, err := json.Marshal(struct {
+				Version   int    `json:"version"`
+				Width     int    `json:"width"`
+				Height    int    `json:"height"`
+				Timestamp int64  `json:"timestamp"`
+				Title     string `json:"title,omitempty"`
+			}{2, tw.opts.Width, tw.opts.Height, now.Unix(), tw.opts.Title})
+			if err != nil {
+				return err
+			}
+			if _, err := tw.t.Write(append(header, '\n')); err != nil {
+				return err
+			}
+		}
+	}
+
+	b := &tw.buf
+	b.Reset()
+	switch tw.opts.Format {
+	case TranscriptAsciicast:
+		// Terminals expect CRLF line endings.
+		data := bytes.ReplaceAll(p, []byte("\r\n"), []byte("\n"))
+		data = bytes.ReplaceAll(data, []byte("\n"), []byte("\r\n"))
+		event, err := json.Marshal([]interface{}{now.Sub(tw.start).Seconds(), "o", string(data)})
+		if err != nil {
+			return err
+		}
+		b.Write(event)
+		b.WriteByte('\n')
+	default:
+		plain := ansi.Strip(string(p))
+		for len(plain) > 0 {
+			if !tw.midLine {
+				b.WriteString(now.Format(transcriptTimeFormat))
+				b.WriteByte(' ')
+			}
+			i := strings.IndexByte(plain, '\n')
+			if i < 0 {
+				b.WriteString(plain)
+				tw.midLine = true
+				break
+			}
+			b.WriteString(plain[:i+1])
+			plain = plain[i+1:]
+			tw.midLine = false
+		}
+	}
+	_, err := tw.t.Write(b.Bytes())
+	return err
+}
+
+// Read reads from the terminal. It's there for terminal detection.
+func (tw *TranscriptWriter) Read(p []byte) (int, error) {
+	if r, ok := tw.w.(io.Reader); ok {
+		return r.Read(p)
+	}
+	return 0, io.EOF
+}
+
+// Fd returns the file descriptor of the terminal, for terminal detection. It
+// returns an invalid descriptor if the terminal isn't a file.
+func (tw *TranscriptWriter) Fd() uintptr {
+	if f, ok := tw.w.(interface{ Fd() uintptr }); ok {
+		return f.Fd()
+	}
+	return ^uintptr(0)
+}
+
+// Close closes the transcript if it's an io.Closer, and returns the first
+// transcript write error, if any.
+func (tw *TranscriptWriter) Close() error {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+	err := tw.err
+	if c, ok := tw.t.(io.Closer); ok {
+		if cerr := c.Close(); err == nil {
+			err = cerr
+		}
+	}
+	return err
+}
@@ -0,0 +1,70 @@
+package plog
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/muesli/termenv"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTranscriptWriter(t *testing.T) {
+	start := time.Date(2024, 1, 2, 15, 4, 5, 0, time.UTC)
+
+	t.Run("plain", func(t *testing.T) {
+		var term, transcript bytes.Buffer
+		tw := NewTranscriptWriter(&term, &transcript, TranscriptOptions{})
+		tw.now = func() time.Time { return start }
+		l := New(tw)
+		l.SetColorProfile(termenv.TrueColor)
+		l.Print("hello", "key", "value")
+		_, _ = tw.Write([]byte("partial "))
+		_, _ = tw.Write([]byte("line\n"))
+		require.NoError(t, tw.Close())
+
+		require.Contains(t, term.String(), "\x1b[")
+		require.Equal(t, "2024-01-02T15:04:05.000Z hello key=value\n"+
+			"2024-01-02T15:04:05.000Z partial line\n", transcript.String())
+	})
+
+	t.Run("asciicast", func(t *testing.T) {
+		var term, transcript bytes.Buffer
+		tw := NewTranscriptWriter(&term, &transcript, TranscriptOptions{
+			Format: TranscriptAsciicast,
+			Title:  "demo",
+		})
+		now := start
+		tw.now = func() time.Time { return now }
+		_, _ = tw.Write([]byte("\x1b[1mone\x1b[0m\n"))
+		now = now.Add(1500 * time.Millisecond)
+		_, _ = tw.Write([]byte("two\n"))
+
+		lines := strings.Split(strings.TrimSpace(transcript.String()), "\n")
+		require.Len(t, lines, 3)
+		require.JSONEq(t, `{"version":2,"width":80,"height":24,"timestamp":1704207845,"title":"demo"}`, lines[0])
+		var event []interface{}
+		require.NoError(t, json.Unmarshal([]byte(lines[1]), &event))
+		require.Equal(t, []interface{}{float64(0), "o", "\x1b[1mone\x1b[0m\r\n"}, event)
+		require.NoError(t, json.Unmarshal([]byte(lines[2]), &event))
+		require.Equal(t, []interface{}{1.5, "o", "two\r\n"}, event)
+	})
+
+	t.Run("open", func(t *testing.T) {
+		dir := t.TempDir()
+		tw, err := OpenTranscript(&bytes.Buffer{}, dir, TranscriptOptions{Format: TranscriptAsciicast})
+		require.NoError(t, err)
+		_, _ = tw.Write([]byte("x"))
+		require.NoError(t, tw.Close())
+		files, err := filepath.Glob(filepath.Join(dir, "transcript-*.cast"))
+		require.NoError(t, err)
+		require.Len(t, files, 1)
+		b, err := os.ReadFile(files[0])
+		require.NoError(t, err)
+		require.Contains(t, string(b), `"version":2`)
+	})
+}
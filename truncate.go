@@ -0,0 +1,99 @@
+package plog
+
+import (
+	"fmt"
+	"time"
+	"unicode/utf8"
+)
+
+// KeyTruncated is the key of the marker added to records with truncated
+// values.
+const KeyTruncated = "truncated"
+
+// truncationSuffix ends truncated values.
+const truncationSuffix = "…"
+
+// WithMaxValueLength returns a new logger truncating values longer than n
+// runes, ending them with an ellipsis and adding KeyTruncated=true to the
+// record, so giant payload dumps can't blow up the log storage or wrap
+// terminals into unreadability. Values other than strings are truncated in
+// their text form. Numbers, booleans, and times are left as is, as are the
+// Fields other than Str. The values of groups are truncated too. A n of zero
+// or less disables the truncation.
+func (l *Logger) WithMaxValueLength(n int) *Logger {
+	sl := l.With()
+	sl.maxValueLen = n
+	return sl
+}
+
+// truncateValues truncates, in place, the values of keyvals longer than the
+// maximum value length.
+func (l *Logger) truncateValues(keyvals []interface{}) []interface{} {
+	if l.maxValueLen <= 0 {
+		return keyvals
+	}
+	if l.truncateGroup(keyvals) {
+		keyvals = append(keyvals, KeyTruncated, true)
+	}
+	return keyvals
+}
+
+// truncateGroup truncates, in place, the values of keyvals and of their
+// groups, and reports whether any was truncated.
+func (l *Logger) truncateGroup(keyvals []interface{}) bool {
+	truncated := false
+	for i := 1; i < len(keyvals); i += 2 {
+		var s string
+		switch v := keyvals[i].(type) {
+		case string:
+			s = v
+		case nil, bool, int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64,
+			float32, float64, time.Time, time.Duration:
+			continue
+		case Field:
+			// Only string fields are truncated, the others hold numbers,
+			// booleans, durations, and sizes.
+			if v.kind == fieldString {
+				if t, ok := l.truncateString(v.str); ok {
+					v.str = t
+					keyvals[i] = v
+					truncated = true
+				}
+			}
+			continue
+		case groupValue:
+			if l.truncateGroup(v) {
+				truncated = true
+			}
+			continue
+		case error:
+			s = v.Error()
+		case fmt.Stringer:
+			s = v.String()
+		default:
+			s = fmt.Sprintf("%+v", v)
+		}
+		if t, ok := l.truncateString(s); ok {
+			keyvals[i] = t
+			truncated = true
+		}
+	}
+	return truncated
+}
+
+// truncateString returns s truncated to the maximum value length, and
+// whether it was longer.
+func (l *Logger) truncateString(s string) (string, bool) {
+	if utf8.RuneCountInString(s) <= l.maxValueLen {
+		return s, false
+	}
+	n := 0
+	for j := range s {
+		if n == l.maxValueLen {
+			s = s[:j]
+			break
+		}
+		n++
+	}
+	return s + truncationSuffix, true
+}
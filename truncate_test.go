@@ -0,0 +1,41 @@
+package plog
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMaxValueLength(t *testing.T) {
+	var buf bytes.Buffer
+	l := NewWithOptions(&buf, Options{Formatter: LogfmtFormatter}).WithMaxValueLength(5)
+	l.Print("dump", "short", "abc", "long", "abcdefgh", "utf8", "日本語日本語",
+		"err", errors.New("long error"), "n", 123456789)
+	require.Equal(t, "msg=dump short=abc long=abcde… utf8=日本語日本… err=\"long …\" n=123456789 truncated=true\n",
+		buf.String())
+
+	buf.Reset()
+	l.Print("fine", "v", strings.Repeat("x", 5))
+	require.Equal(t, "msg=fine v=xxxxx\n", buf.String())
+}
+
+func TestMaxValueLengthFields(t *testing.T) {
+	var buf bytes.Buffer
+	l := NewWithOptions(&buf, Options{Formatter: LogfmtFormatter}).WithMaxValueLength(5)
+	l.Print("fields", Str("s", "abcdefgh"), Int64("n", 123456789), Dur("d", 1234567890), Bool("ok", true))
+	require.Equal(t, "msg=fields s=abcde… n=123456789 d=1.23456789s ok=true truncated=true\n", buf.String())
+
+	buf.Reset()
+	l.Print("fields", Int64("n", 123456789))
+	require.Equal(t, "msg=fields n=123456789\n", buf.String())
+}
+
+func TestMaxValueLengthGroup(t *testing.T) {
+	var buf bytes.Buffer
+	l := NewWithOptions(&buf, Options{Formatter: LogfmtFormatter}).WithMaxValueLength(5)
+	l.Print("group", Group("req", "path", "/abcdefgh", "n", 123456789, Group("body", "raw", "abcdefgh")))
+	require.Equal(t, "msg=group req.path=/abcd… req.n=123456789 req.body.raw=abcde… truncated=true\n", buf.String())
+}
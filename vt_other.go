@@ -0,0 +1,11 @@
+//go:build !windows
+
+package plog
+
+import "io"
+
+// enableVirtualTerminal is a no-op outside of Windows, where terminals
+// interpret ANSI escape sequences.
+func enableVirtualTerminal(io.Writer) error {
+	return nil
+}
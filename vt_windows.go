@@ -0,0 +1,30 @@
+//go:build windows
+
+package plog
+
+import (
+	"io"
+
+	"golang.org/x/sys/windows"
+)
+
+// enableVirtualTerminal enables the virtual terminal processing of w when
+// it's a Windows console, so that it interprets ANSI escape sequences. It
+// returns an error if w is a console that doesn't support it, like the
+// legacy console of older Windows versions.
+func enableVirtualTerminal(w io.Writer) error {
+	f, ok := w.(interface{ Fd() uintptr })
+	if !ok {
+		return nil
+	}
+	h := windows.Handle(f.Fd())
+	var mode uint32
+	if err := windows.GetConsoleMode(h, &mode); err != nil {
+		// Not a console, e.g. a file or a pipe.
+		return nil
+	}
+	if mode&windows.ENABLE_VIRTUAL_TERMINAL_PROCESSING != 0 {
+		return nil
+	}
+	return windows.SetConsoleMode(h, mode|windows.ENABLE_VIRTUAL_TERMINAL_PROCESSING)
+}
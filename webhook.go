@@ -0,0 +1,215 @@
+package plog
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+)
+
+// WebhookFormat is the payload format of a webhook.
+type WebhookFormat uint8
+
+const (
+	// WebhookGeneric posts a JSON object with the record level, message,
+	// time, prefix, and fields.
+	WebhookGeneric WebhookFormat = iota
+	// WebhookSlack posts a Slack incoming webhook message.
+	WebhookSlack
+	// WebhookDiscord posts a Discord webhook message.
+	WebhookDiscord
+)
+
+const (
+	// DefaultWebhookRateLimit is the default maximum number of alerts sent per
+	// rate period.
+	DefaultWebhookRateLimit = 10
+	// DefaultWebhookRatePeriod is the default rate limiting period.
+	DefaultWebhookRatePeriod = time.Minute
+	// DefaultWebhookFlushTimeout is the default time Flush waits for pending
+	// alerts to be sent.
+	DefaultWebhookFlushTimeout = 2 * time.Second
+)
+
+var webhookTemplates = map[WebhookFormat]string{
+	WebhookGeneric: `{"level":{{json .Level}},"time":{{json .Time}},"prefix":{{json .Prefix}},` +
+		`"message":{{json .Message}},"fields":{{json .Fields}},"suppressed":{{.Suppressed}}}`,
+	WebhookSlack:   `{"text":{{json .Text}}}`,
+	WebhookDiscord: `{"content":{{json .Text}}}`,
+}
+
+// WebhookOptions are the options for a WebhookProcessor.
+type WebhookOptions struct {
+	// URL is the webhook URL.
+	URL string
+	// Format is the payload format. It's ignored when Template is set. The
+	// default is WebhookGeneric.
+	Format WebhookFormat
+	// Template is a text/template producing the request body. It's executed
+	// with a WebhookData and has a "json" function encoding its argument as
+	// JSON.
+	Template string
+	// ContentType is the request content type. The default is
+	// "application/json".
+	ContentType string
+	// RateLimit is the maximum number of alerts sent per RatePeriod. Alerts
+	// over the limit are dropped and counted in the next alert. The default is
+	// DefaultWebhookRateLimit.
+	RateLimit int
+	// RatePeriod is the rate limiting period. The default is
+	// DefaultWebhookRatePeriod.
+	RatePeriod time.Duration
+	// Client is the HTTP client used to post alerts. The default is
+	// http.DefaultClient.
+	Client *http.Client
+	// FlushTimeout is the time Flush waits for pending alerts to be sent. The
+	// default is DefaultWebhookFlushTimeout.
+	FlushTimeout time.Duration
+}
+
+// WebhookData is the data a webhook template is executed with.
+type WebhookData struct {
+	// Level is the upper case record level, e.g. "ERROR".
+	Level string
+	// Time is the record time.
+	Time time.Time
+	// Prefix is the logger prefix.
+	Prefix string
+	// Message is the record message.
+	Message string
+	// Fields are the record keyvals.
+	Fields map[string]string
+	// Text is a one line summary of the record.
+	Text string
+	// Suppressed is the number of alerts dropped by rate limiting since the
+	// last alert was sent.
+	Suppressed int
+}
+
+// WebhookProcessor is a Processor that posts error and fatal records to a
+// webhook, e.g. a Slack or Discord channel. Alerts are rate limited and sent
+// in the background; records are never dropped from the log.
+type WebhookProcessor struct {
+	opts   WebhookOptions
+	tmpl   *template.Template
+	poster *poster
+
+	mu          sync.Mutex
+	now         func() time.Time
+	windowStart time.Time
+	sent        int
+	suppressed  int
+}
+
+// NewWebhookProcessor returns a new WebhookProcessor. It returns an error if
+// the template can't be parsed.
+func NewWebhookProcessor(o WebhookOptions) (*WebhookProcessor, error) {
+	text := o.Template
+	if text == "" {
+		text = webhookTemplates[o.Format]
+	}
+	tmpl, err := template.New("webhook").Funcs(template.FuncMap{
+		"json": func(v interface{}) (string, error) {
+			b, err := json.Marshal(v)
+			return string(b), err
+		},
+	}).Parse(text)
+	if err != nil {
+		return nil, err
+	}
+
+	if o.ContentType == "" {
+		o.ContentType = "application/json"
+	}
+	if o.RateLimit <= 0 {
+		o.RateLimit = DefaultWebhookRateLimit
+	}
+	if o.RatePeriod <= 0 {
+		o.RatePeriod = DefaultWebhookRatePeriod
+	}
+	if o.FlushTimeout <= 0 {
+		o.FlushTimeout = DefaultWebhookFlushTimeout
+	}
+
+	header := http.Header{}
+	header.Set("Content-Type", o.ContentType)
+	return &WebhookProcessor{
+		opts:   o,
+		tmpl:   tmpl,
+		poster: newPoster(o.Client, o.FlushTimeout, header),
+		now:    time.Now,
+	}, nil
+}
+
+// Process posts the entry to the webhook if it's an error or fatal record
+// and the rate limit allows it.
+func (p *WebhookProcessor) Process(e *Entry) bool {
+	if e.Level < ErrorLevel || e.Level == noLevel {
+		return true
+	}
+
+	suppressed, ok := p.allow()
+	if !ok {
+		return true
+	}
+
+	data := WebhookData{
+		Level:      strings.ToUpper(e.Level.String()),
+		Time:       e.Time,
+		Prefix:     e.Prefix,
+		Message:    e.Message,
+		Fields:     make(map[string]string, len(e.Keyvals)/2),
+		Suppressed: suppressed,
+	}
+	var text strings.Builder
+	fmt.Fprintf(&text, "[%s] ", data.Level)
+	if e.Prefix != "" {
+		text.WriteString(e.Prefix + ": ")
+	}
+	text.WriteString(e.Message)
+	for i := 0; i+1 < len(e.Keyvals); i += 2 {
+		key, val := fmt.Sprint(e.Keyvals[i]), fmt.Sprintf("%+v", e.Keyvals[i+1])
+		data.Fields[key] = val
+		fmt.Fprintf(&text, " %s=%s", key, val)
+	}
+	if suppressed > 0 {
+		fmt.Fprintf(&text, " (%d more alerts suppressed)", suppressed)
+	}
+	data.Text = text.String()
+
+	var body bytes.Buffer
+	if err := p.tmpl.Execute(&body, data); err == nil {
+		p.poster.post(p.opts.URL, body.Bytes())
+	}
+	return true
+}
+
+// allow reports whether an alert can be sent and how many were suppressed
+// since the last one.
+func (p *WebhookProcessor) allow() (int, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	now := p.now()
+	if now.Sub(p.windowStart) >= p.opts.RatePeriod {
+		p.windowStart = now
+		p.sent = 0
+	}
+	if p.sent >= p.opts.RateLimit {
+		p.suppressed++
+		return 0, false
+	}
+	p.sent++
+	suppressed := p.suppressed
+	p.suppressed = 0
+	return suppressed, true
+}
+
+// Flush waits for pending alerts to be sent, up to the flush timeout.
+func (p *WebhookProcessor) Flush() {
+	p.poster.flush()
+}
@@ -0,0 +1,135 @@
+package plog
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWebhookProcessor(t *testing.T) {
+	var (
+		mu     sync.Mutex
+		bodies []string
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		bodies = append(bodies, r.Header.Get("Content-Type")+" "+string(b))
+		mu.Unlock()
+	}))
+	defer srv.Close()
+
+	cases := []struct {
+		name     string
+		opts     WebhookOptions
+		expected string
+	}{
+		{
+			name:     "slack",
+			opts:     WebhookOptions{Format: WebhookSlack},
+			expected: `application/json {"text":"[ERROR] oven: burnt temp=500"}`,
+		},
+		{
+			name:     "discord",
+			opts:     WebhookOptions{Format: WebhookDiscord},
+			expected: `application/json {"content":"[ERROR] oven: burnt temp=500"}`,
+		},
+		{
+			name: "generic",
+			opts: WebhookOptions{},
+			expected: `application/json {"level":"ERROR","time":"2024-01-01T00:00:00Z","prefix":"oven",` +
+				`"message":"burnt","fields":{"temp":"500"},"suppressed":0}`,
+		},
+		{
+			name:     "template",
+			opts:     WebhookOptions{Template: `{{.Level}} {{.Message}}`, ContentType: "text/plain"},
+			expected: "text/plain ERROR burnt",
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			mu.Lock()
+			bodies = nil
+			mu.Unlock()
+
+			c.opts.URL = srv.URL
+			p, err := NewWebhookProcessor(c.opts)
+			require.NoError(t, err)
+			l := NewWithOptions(discardWriter{}, Options{
+				Prefix:          "oven",
+				ReportTimestamp: true,
+				TimeFunction:    func(time.Time) time.Time { return time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC) },
+				Processors:      []Processor{p},
+			})
+			l.Warn("ignored")
+			l.Error("burnt", "temp", 500)
+			p.Flush()
+
+			mu.Lock()
+			defer mu.Unlock()
+			require.Equal(t, []string{c.expected}, bodies)
+		})
+	}
+}
+
+func TestWebhookRateLimit(t *testing.T) {
+	now := time.Unix(0, 0)
+	p, err := NewWebhookProcessor(WebhookOptions{URL: "http://example.com", RateLimit: 2})
+	require.NoError(t, err)
+	p.now = func() time.Time { return now }
+
+	for i := 0; i < 5; i++ {
+		_, ok := p.allow()
+		require.Equal(t, i < 2, ok)
+	}
+
+	now = now.Add(DefaultWebhookRatePeriod)
+	suppressed, ok := p.allow()
+	require.True(t, ok)
+	require.Equal(t, 3, suppressed)
+}
+
+func TestWebhookBadTemplate(t *testing.T) {
+	_, err := NewWebhookProcessor(WebhookOptions{Template: "{{"})
+	require.Error(t, err)
+}
+
+func TestPosterConcurrentFlush(t *testing.T) {
+	var (
+		mu sync.Mutex
+		n  int
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		n++
+		mu.Unlock()
+	}))
+	defer srv.Close()
+
+	p := newPoster(srv.Client(), 5*time.Second, nil)
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 10; j++ {
+				require.True(t, p.post(srv.URL, []byte("{}")))
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			p.flush()
+		}()
+	}
+	wg.Wait()
+	p.flush()
+
+	mu.Lock()
+	defer mu.Unlock()
+	require.Equal(t, 40, n)
+}